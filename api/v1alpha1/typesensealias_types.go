@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseAliasSpec defines the desired state of TypesenseAlias
+type TypesenseAliasSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, the alias is created in
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Name of the alias in Typesense, defaults to the name of the resource
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Name *string `json:"name,omitempty"`
+
+	// Collection the alias points at; set it to status.previousCollection to roll back a swap
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Collection string `json:"collection"`
+}
+
+// TypesenseAliasStatus defines the observed state of TypesenseAlias
+type TypesenseAliasStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	AliasName string `json:"aliasName,omitempty"`
+
+	// Collection the alias currently points at
+	// +optional
+	Collection string `json:"collection,omitempty"`
+
+	// PreviousCollection the alias pointed at before the last swap
+	// +optional
+	PreviousCollection string `json:"previousCollection,omitempty"`
+
+	// +optional
+	SwappedAt *metav1.Time `json:"swappedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseAlias is the Schema for the typesensealiases API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Alias",type=string,JSONPath=`.status.aliasName`
+// +kubebuilder:printcolumn:name="Collection",type=string,JSONPath=`.status.collection`
+// +kubebuilder:printcolumn:name="Previous",type=string,JSONPath=`.status.previousCollection`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseAlias struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseAliasSpec   `json:"spec,omitempty"`
+	Status TypesenseAliasStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseAliasList contains a list of TypesenseAlias
+type TypesenseAliasList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseAlias `json:"items"`
+}
+
+// GetAliasName returns the name of the alias in Typesense
+func (a *TypesenseAlias) GetAliasName() string {
+	if a.Spec.Name != nil && *a.Spec.Name != "" {
+		return *a.Spec.Name
+	}
+
+	return a.Name
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseAlias{}, &TypesenseAliasList{})
+}
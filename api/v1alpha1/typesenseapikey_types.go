@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseApiKeySpec defines the desired state of TypesenseApiKey
+type TypesenseApiKeySpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, the key is minted for
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Items:Type=string
+	Actions []string `json:"actions"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Items:Type=string
+	Collections []string `json:"collections"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Description *string `json:"description,omitempty"`
+
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// +optional
+	Secret *ApiKeySecretSpec `json:"secret,omitempty"`
+}
+
+type ApiKeySecretSpec struct {
+	// Name of the Secret the key is written into, defaults to the name of the resource
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Name *string `json:"name,omitempty"`
+
+	// +optional
+	// +kubebuilder:default="typesense-api-key"
+	// +kubebuilder:validation:Type=string
+	Key string `json:"key,omitempty"`
+}
+
+// TypesenseApiKeyStatus defines the observed state of TypesenseApiKey
+type TypesenseApiKeyStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	KeyId *int64 `json:"keyId,omitempty"`
+
+	// +optional
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseApiKey is the Schema for the typesenseapikeys API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Key ID",type=integer,JSONPath=`.status.keyId`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretName`
+// +kubebuilder:printcolumn:name="Expires",type=string,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseApiKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseApiKeySpec   `json:"spec,omitempty"`
+	Status TypesenseApiKeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseApiKeyList contains a list of TypesenseApiKey
+type TypesenseApiKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseApiKey `json:"items"`
+}
+
+// GetSecretName returns the name of the Secret the key is written into
+func (k *TypesenseApiKey) GetSecretName() string {
+	if k.Spec.Secret != nil && k.Spec.Secret.Name != nil && *k.Spec.Secret.Name != "" {
+		return *k.Spec.Secret.Name
+	}
+
+	return k.Name
+}
+
+// GetSecretKey returns the data key under which the api key is stored in the Secret
+func (k *TypesenseApiKey) GetSecretKey() string {
+	if k.Spec.Secret != nil && k.Spec.Secret.Key != "" {
+		return k.Spec.Secret.Key
+	}
+
+	return "typesense-api-key"
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseApiKey{}, &TypesenseApiKeyList{})
+}
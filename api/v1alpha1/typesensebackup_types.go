@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseBackupSpec defines the desired state of TypesenseBackup
+type TypesenseBackupSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, to take the snapshot of
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	Destination BackupDestinationSpec `json:"destination"`
+
+	// DeletionPolicy defines whether the uploaded snapshot is removed from the bucket when the backup is deleted
+	// +optional
+	// +kubebuilder:default="Retain"
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:validation:Type=string
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Image of the containers that upload and delete the snapshot, it must provide the aws cli
+	// +optional
+	// +kubebuilder:default="amazon/aws-cli:2.22.35"
+	// +kubebuilder:validation:Type=string
+	Image string `json:"image,omitempty"`
+
+	// Resources of the job that deletes the snapshot from the bucket; the upload runs in an ephemeral container of
+	// the leader pod, which cannot request any
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type BackupDestinationSpec struct {
+	S3 S3DestinationSpec `json:"s3"`
+}
+
+type S3DestinationSpec struct {
+	// +kubebuilder:validation:MinLength=3
+	// +kubebuilder:validation:Type=string
+	Bucket string `json:"bucket"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Prefix string `json:"prefix,omitempty"`
+
+	// Endpoint of an S3-compatible object storage, e.g. MinIO; path-style addressing is used when set
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Endpoint *string `json:"endpoint,omitempty"`
+
+	// +optional
+	// +kubebuilder:default="us-east-1"
+	// +kubebuilder:validation:Type=string
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret holds the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys
+	CredentialsSecret corev1.LocalObjectReference `json:"credentialsSecret"`
+}
+
+type TypesenseBackupPhase string
+
+const (
+	BackupPending      TypesenseBackupPhase = "Pending"
+	BackupSnapshotting TypesenseBackupPhase = "Snapshotting"
+	BackupUploading    TypesenseBackupPhase = "Uploading"
+	BackupCompleted    TypesenseBackupPhase = "Completed"
+	BackupFailed       TypesenseBackupPhase = "Failed"
+)
+
+// TypesenseBackupStatus defines the observed state of TypesenseBackup
+type TypesenseBackupStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase TypesenseBackupPhase `json:"phase,omitempty"`
+
+	// LeaderPod is the pod the snapshot was taken on
+	// +optional
+	LeaderPod string `json:"leaderPod,omitempty"`
+
+	// CommittedIndex of the leader when the snapshot was taken
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// SnapshotPath is the directory, on the snapshots volume of the leader pod, the snapshot is written to
+	// +optional
+	SnapshotPath string `json:"snapshotPath,omitempty"`
+
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// UploadContainer is the ephemeral container of the leader pod that uploads the snapshot
+	// +optional
+	UploadContainer string `json:"uploadContainer,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseBackup is the Schema for the typesensebackups API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Committed Index",type=integer,JSONPath=`.status.committedIndex`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.sizeBytes`
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completedAt`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+type TypesenseBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseBackupSpec   `json:"spec,omitempty"`
+	Status TypesenseBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseBackupList contains a list of TypesenseBackup
+type TypesenseBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseBackup `json:"items"`
+}
+
+// GetLocation returns the s3 url the snapshot of the backup is uploaded to
+func (b *TypesenseBackup) GetLocation() string {
+	segments := []string{b.Spec.Destination.S3.Bucket}
+	if prefix := strings.Trim(b.Spec.Destination.S3.Prefix, "/"); prefix != "" {
+		segments = append(segments, prefix)
+	}
+	segments = append(segments, b.Spec.Cluster.Name, b.Name)
+
+	return fmt.Sprintf("s3://%s/", strings.Join(segments, "/"))
+}
+
+func (b *TypesenseBackup) GetImage() string {
+	if b.Spec.Image != "" {
+		return b.Spec.Image
+	}
+	return "amazon/aws-cli:2.22.35"
+}
+
+func (b *TypesenseBackup) GetRegion() string {
+	if b.Spec.Destination.S3.Region != "" {
+		return b.Spec.Destination.S3.Region
+	}
+	return "us-east-1"
+}
+
+func (b *TypesenseBackup) IsFinished() bool {
+	return b.Status.Phase == BackupCompleted || b.Status.Phase == BackupFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseBackup{}, &TypesenseBackupList{})
+}
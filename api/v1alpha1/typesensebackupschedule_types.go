@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseBackupScheduleSpec defines the desired state of TypesenseBackupSchedule
+type TypesenseBackupScheduleSpec struct {
+	// Schedule in cron format, e.g. "0 3 * * *" or "@daily"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Schedule string `json:"schedule"`
+
+	// +optional
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Type=boolean
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Template of the TypesenseBackup objects created on every run
+	Template TypesenseBackupSpec `json:"template"`
+
+	// +optional
+	Retention *BackupRetentionSpec `json:"retention,omitempty"`
+}
+
+type BackupRetentionSpec struct {
+	// KeepLast is the number of completed backups to keep
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Type=integer
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// MaxAge after which completed backups are pruned, e.g. "720h"
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+type BackupRecord struct {
+	Name string `json:"name"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// +optional
+	Location string `json:"location,omitempty"`
+}
+
+// TypesenseBackupScheduleStatus defines the observed state of TypesenseBackupSchedule
+type TypesenseBackupScheduleStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// +optional
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// Backups lists the completed backups retained by the schedule, newest first
+	// +optional
+	Backups []BackupRecord `json:"backups,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseBackupSchedule is the Schema for the typesensebackupschedules API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.template.cluster.name`
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseBackupScheduleSpec   `json:"spec,omitempty"`
+	Status TypesenseBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseBackupScheduleList contains a list of TypesenseBackupSchedule
+type TypesenseBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseBackupSchedule{}, &TypesenseBackupScheduleList{})
+}
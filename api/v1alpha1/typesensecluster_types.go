@@ -28,11 +28,32 @@ import (
 type TypesenseClusterSpec struct {
 	Image string `json:"image"`
 
+	// Paused keeps the operator probing the nodes and reporting the status of the cluster, while every action that
+	// mutates it is skipped, e.g. during an incident or a manual data surgery. The same applies as long as the
+	// ts.opentelekomcloud.com/paused annotation is set to "true".
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// PlanMode holds back the changes to the StatefulSet, the nodes ConfigMap, the Services, the Ingress, the
+	// HTTPRoutes and the data volumes of a running cluster, and reports them in status.plan until the plan is approved
+	// with the ts.opentelekomcloud.com/approve-plan annotation. The cluster is only observed while a plan waits for
+	// approval.
+	// +optional
+	PlanMode bool `json:"planMode,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
 	AdminApiKey *corev1.SecretReference `json:"adminApiKey,omitempty"`
 
+	// +optional
+	AdminApiKeyRotation *AdminApiKeyRotationSpec `json:"adminApiKeyRotation,omitempty"`
+
+	// +optional
+	Restore *RestoreSpec `json:"restore,omitempty"`
+
+	// Replicas is the size of the raft quorum. When webhooks are enabled, even sizes written through the scale
+	// subresource or the spec are rounded up to the next odd size
 	// +optional
 	// +kubebuilder:default=3
 	// +kubebuilder:validation:Minimum=1
@@ -43,6 +64,11 @@ type TypesenseClusterSpec struct {
 	// +kubebuilder:validation:Enum=1;3;5;7
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// Autoscaling lets the operator drive the replicas, which should then not be edited by hand or by an external
+	// autoscaler targeting the scale subresource
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
 	// +optional
 	// +kubebuilder:default=8108
 	// +kubebuilder:validation:Minimum=1024
@@ -91,6 +117,10 @@ type TypesenseClusterSpec struct {
 
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// VerticalScaling recommends the memory of the typesense container and, in Auto mode, raises its limit
+	// +optional
+	VerticalScaling *VerticalScalingSpec `json:"verticalScaling,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
@@ -158,6 +188,18 @@ type TypesenseClusterSpec struct {
 	// +kubebuilder:validation:Maximum=300
 	// +kubebuilder:validation:Type=integer
 	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// +optional
+	Remediation *RemediationSpec `json:"remediation,omitempty"`
+
+	// +optional
+	FinalSnapshot *FinalSnapshotSpec `json:"finalSnapshot,omitempty"`
+
+	// +optional
+	Snapshots *SnapshotsSpec `json:"snapshots,omitempty"`
 }
 
 // TypesenseClusterStatus defines the observed state of TypesenseCluster
@@ -169,10 +211,64 @@ type TypesenseClusterStatus struct {
 
 	// +optional
 	Phase string `json:"phase,omitempty"`
+
+	// Replicas is the number of pods of the StatefulSet, as reported to the scale subresource
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector of the pods, as reported to the scale subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the status was last reconciled with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Image the pods are running, once all of them are on the same revision
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Leader is the pod that was the raft leader when the nodes were last probed
+	// +optional
+	Leader string `json:"leader,omitempty"`
+
+	// Nodes as probed while reconciling the quorum
+	// +optional
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+
+	// +optional
+	AdminApiKeyRotation *AdminApiKeyRotationStatus `json:"adminApiKeyRotation,omitempty"`
+
+	// +optional
+	Restore *RestoreStatus `json:"restore,omitempty"`
+
+	// +optional
+	RollingUpdate *RollingUpdateStatus `json:"rollingUpdate,omitempty"`
+
+	// +optional
+	Storage *StorageStatus `json:"storage,omitempty"`
+
+	// +optional
+	TLS *TLSStatus `json:"tls,omitempty"`
+
+	// +optional
+	Autoscaling *AutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// +optional
+	VerticalScaling *VerticalScalingStatus `json:"verticalScaling,omitempty"`
+
+	// Remediations the operator performed on the nodes, the latest ones first
+	// +optional
+	Remediations []RemediationStatus `json:"remediations,omitempty"`
+
+	// Plan waiting for approval, or approved and being applied, in plan mode
+	// +optional
+	Plan *PlanStatus `json:"plan,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 
 // TypesenseCluster is the Schema for the typesenseclusters API
 // +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
@@ -180,6 +276,7 @@ type TypesenseClusterStatus struct {
 // +kubebuilder:printcolumn:name="API Port",type=integer,JSONPath=`.spec.apiPort`
 // +kubebuilder:printcolumn:name="Peering Port",type=integer,JSONPath=`.spec.peeringPort`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Leader",type=string,JSONPath=`.status.leader`,priority=1
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 type TypesenseCluster struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,66 @@
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type AdminApiKeyRotationSpec struct {
+	// Trigger starts a new rotation of the admin api key every time its value changes
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Trigger string `json:"trigger,omitempty"`
+
+	// GracePeriodMinutes the previous admin api key remains valid after all pods have been rolled
+	// +optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10080
+	// +kubebuilder:validation:Type=integer
+	GracePeriodMinutes *int32 `json:"gracePeriodMinutes,omitempty"`
+}
+
+type AdminApiKeyRotationPhase string
+
+const (
+	AdminApiKeyRotationRolling     AdminApiKeyRotationPhase = "Rolling"
+	AdminApiKeyRotationGracePeriod AdminApiKeyRotationPhase = "GracePeriod"
+	AdminApiKeyRotationCompleted   AdminApiKeyRotationPhase = "Completed"
+)
+
+type AdminApiKeyRotationStatus struct {
+	// +optional
+	Phase AdminApiKeyRotationPhase `json:"phase,omitempty"`
+
+	// Revision is increased on every rotation and stamped on the pod template to roll the pods
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// +optional
+	LastTrigger string `json:"lastTrigger,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// +optional
+	GracePeriodExpiresAt *metav1.Time `json:"gracePeriodExpiresAt,omitempty"`
+
+	// CurrentKeyId is the id of the temporary copy of the new admin api key, valid on pods not rolled yet
+	// +optional
+	CurrentKeyId *int64 `json:"currentKeyId,omitempty"`
+
+	// PreviousKeyId is the id of the temporary copy of the previous admin api key, valid during the grace period
+	// +optional
+	PreviousKeyId *int64 `json:"previousKeyId,omitempty"`
+}
+
+func (s *TypesenseClusterSpec) GetAdminApiKeyRotationGracePeriod() time.Duration {
+	if s.AdminApiKeyRotation != nil && s.AdminApiKeyRotation.GracePeriodMinutes != nil {
+		return time.Duration(*s.AdminApiKeyRotation.GracePeriodMinutes) * time.Minute
+	}
+	return 60 * time.Minute
+}
@@ -0,0 +1,88 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// AutoscalingSpec lets the operator scale the replicas between the allowed odd sizes, one size at a time. The targets
+// are averaged over the nodes: the cluster grows as soon as one of them is exceeded and shrinks only when, even with
+// one size less, all of them would still be met. Scaling is suspended while the quorum is not ready.
+// +kubebuilder:validation:XValidation:rule="self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type AutoscalingSpec struct {
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Enum=1;3;5;7
+	// +kubebuilder:validation:Type=integer
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// +optional
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Enum=1;3;5;7
+	// +kubebuilder:validation:Type=integer
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// TargetCPUUtilizationPercentage of the cpu requests of the typesense container, as reported by the metrics API
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Type=integer
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage of the memory requests of the typesense container, as reported by the metrics API
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Type=integer
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// TargetQueuedWrites holds off any scale down while a node has more queued writes. It never scales the cluster up,
+	// as every node applies every write and more nodes do not drain the queue any faster
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Type=integer
+	TargetQueuedWrites *int64 `json:"targetQueuedWrites,omitempty"`
+
+	// TargetSearchLatencyMilliseconds as reported by the /stats.json endpoint of the nodes
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Type=integer
+	TargetSearchLatencyMilliseconds *int64 `json:"targetSearchLatencyMilliseconds,omitempty"`
+
+	// +optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Type=integer
+	ScaleUpCooldownSeconds int32 `json:"scaleUpCooldownSeconds,omitempty"`
+
+	// +optional
+	// +kubebuilder:default=900
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Type=integer
+	ScaleDownCooldownSeconds int32 `json:"scaleDownCooldownSeconds,omitempty"`
+}
+
+// AutoscalingStatus reports the metrics the last recommendation was based on
+type AutoscalingStatus struct {
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// +optional
+	CurrentCPUUtilizationPercentage *int32 `json:"currentCPUUtilizationPercentage,omitempty"`
+
+	// +optional
+	CurrentMemoryUtilizationPercentage *int32 `json:"currentMemoryUtilizationPercentage,omitempty"`
+
+	// +optional
+	CurrentQueuedWrites *int64 `json:"currentQueuedWrites,omitempty"`
+
+	// +optional
+	CurrentSearchLatencyMilliseconds *int64 `json:"currentSearchLatencyMilliseconds,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+func (s *TypesenseClusterSpec) IsAutoscalingEnabled() bool {
+	return s.Autoscaling != nil
+}
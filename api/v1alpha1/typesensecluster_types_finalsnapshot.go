@@ -0,0 +1,31 @@
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FinalSnapshotSpec takes a last TypesenseBackup of the cluster when it is deleted, before its data volumes are
+// released according to spec.storage.retentionPolicy. The backup outlives the cluster and can be restored from.
+type FinalSnapshotSpec struct {
+	Destination BackupDestinationSpec `json:"destination"`
+
+	// Image of the job that uploads the snapshot, it must provide the aws cli
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Image string `json:"image,omitempty"`
+
+	// Timeout after which the cluster is deleted, even if the final snapshot did not complete
+	// +optional
+	// +kubebuilder:default="30m"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+func (s *FinalSnapshotSpec) GetTimeout() time.Duration {
+	if s.Timeout != nil && s.Timeout.Duration > 0 {
+		return s.Timeout.Duration
+	}
+
+	return 30 * time.Minute
+}
@@ -0,0 +1,33 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeStatus is the state of a single Typesense node, as reported by its /status and /health endpoints
+type NodeStatus struct {
+	Pod string `json:"pod"`
+
+	// +optional
+	IP string `json:"ip,omitempty"`
+
+	// Role in the raft quorum, e.g. LEADER, FOLLOWER, NOT_READY or UNREACHABLE
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// +optional
+	QueuedWrites int64 `json:"queuedWrites,omitempty"`
+
+	// +optional
+	Healthy bool `json:"healthy"`
+
+	// ResourceError reported by the health endpoint, e.g. OUT_OF_MEMORY or OUT_OF_DISK
+	// +optional
+	ResourceError string `json:"resourceError,omitempty"`
+
+	// +optional
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+}
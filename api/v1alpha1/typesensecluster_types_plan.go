@@ -0,0 +1,46 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+type PlanAction string
+
+const (
+	PlanActionCreate PlanAction = "Create"
+	PlanActionUpdate PlanAction = "Update"
+	PlanActionDelete PlanAction = "Delete"
+)
+
+// PlanStatus lists the changes the operator holds back in plan mode, until the plan is approved by setting the
+// ts.opentelekomcloud.com/approve-plan annotation to its id
+type PlanStatus struct {
+	Id string `json:"id"`
+
+	// Generation of the spec the plan was computed for
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// Triggers of the StatefulSet update, if any
+	// +optional
+	Triggers []string `json:"triggers,omitempty"`
+
+	// +optional
+	Changes []PlanChange `json:"changes,omitempty"`
+
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// +optional
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+}
+
+type PlanChange struct {
+	Kind string `json:"kind"`
+
+	Name string `json:"name"`
+
+	Action PlanAction `json:"action"`
+
+	// Diff of the labels, annotations and spec of the object, as a unified diff
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
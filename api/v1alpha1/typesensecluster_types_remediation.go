@@ -0,0 +1,92 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationSpec lets the operator recover nodes from failures that otherwise need manual administrative attention
+type RemediationSpec struct {
+	// +optional
+	OutOfDisk *OutOfDiskRemediationSpec `json:"outOfDisk,omitempty"`
+
+	// +optional
+	NodeReplacement *NodeReplacementRemediationSpec `json:"nodeReplacement,omitempty"`
+}
+
+// OutOfDiskRemediationSpec expands the data volume of a node reporting OUT_OF_DISK by an increment, as long as the
+// StorageClass allows volume expansion and the volume has not reached the maximum size, and restarts the node once
+// the volume is resized. Only the volume of the affected node grows, spec.storage.size is left untouched.
+type OutOfDiskRemediationSpec struct {
+	// +optional
+	// +kubebuilder:default="1Gi"
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).isGreaterThan(quantity('0'))",message="increment must be greater than zero"
+	Increment resource.Quantity `json:"increment,omitempty"`
+
+	// MaxSize a data volume is never expanded beyond
+	MaxSize resource.Quantity `json:"maxSize"`
+}
+
+// NodeReplacementRemediationSpec replaces a node that cannot recover on its own, e.g. because of corrupt data, with a
+// fresh replica: the node is removed from the nodes list, its data volume and pod are deleted, and the new replica is
+// added back to resync from the leader, while the rest of the quorum keeps serving. A node is always replaced when its
+// pod is annotated with ts.opentelekomcloud.com/replace-node set to "true".
+type NodeReplacementRemediationSpec struct {
+	// Automatic replaces the nodes reporting the ERROR role as well, as long as another node leads the quorum
+	// +optional
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Type=boolean
+	Automatic bool `json:"automatic,omitempty"`
+}
+
+type RemediationType string
+
+const (
+	RemediationOutOfDisk       RemediationType = "OutOfDisk"
+	RemediationQuorumRecovery  RemediationType = "QuorumRecovery"
+	RemediationNodeReplacement RemediationType = "NodeReplacement"
+)
+
+type RemediationPhase string
+
+const (
+	RemediationInProgress RemediationPhase = "InProgress"
+	RemediationCompleted  RemediationPhase = "Completed"
+	RemediationFailed     RemediationPhase = "Failed"
+)
+
+// RemediationStatus records a remediation the operator performed on a node, the latest finished ones are kept next
+// to the ones in progress
+type RemediationStatus struct {
+	Type RemediationType `json:"type"`
+
+	Pod string `json:"pod"`
+
+	// +optional
+	Phase RemediationPhase `json:"phase,omitempty"`
+
+	// Object the remediation acted upon, e.g. the PersistentVolumeClaim that was expanded
+	// +optional
+	Object string `json:"object,omitempty"`
+
+	// CommittedIndex of the node when the remediation started, e.g. of the node a quorum is recovered from
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+func (s *TypesenseClusterSpec) IsOutOfDiskRemediationEnabled() bool {
+	return s.Remediation != nil && s.Remediation.OutOfDisk != nil
+}
+
+func (s *TypesenseClusterSpec) IsAutomaticNodeReplacementEnabled() bool {
+	return s.Remediation != nil && s.Remediation.NodeReplacement != nil && s.Remediation.NodeReplacement.Automatic
+}
@@ -0,0 +1,98 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoreSpec seeds the data volumes of a new cluster from a snapshot before Typesense starts. It is ignored when
+// added to a cluster that has already been bootstrapped.
+// +kubebuilder:validation:XValidation:rule="has(self.backup) != has(self.source)",message="exactly one of backup or source must be set"
+type RestoreSpec struct {
+	// Backup is a completed TypesenseBackup, in the same namespace, to restore from
+	// +optional
+	Backup *corev1.LocalObjectReference `json:"backup,omitempty"`
+
+	// Source locates the snapshot in object storage directly, e.g. when the TypesenseBackup lives in another namespace
+	// +optional
+	Source *RestoreSourceSpec `json:"source,omitempty"`
+
+	// Image of the init container that downloads the snapshot, it must provide the aws cli
+	// +optional
+	// +kubebuilder:default="amazon/aws-cli:2.22.35"
+	// +kubebuilder:validation:Type=string
+	Image string `json:"image,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type RestoreSourceSpec struct {
+	// Location is the s3 url of the snapshot, as reported in the status of a TypesenseBackup
+	// +kubebuilder:validation:Pattern=`^s3://.+`
+	// +kubebuilder:validation:Type=string
+	Location string `json:"location"`
+
+	// Endpoint of an S3-compatible object storage, e.g. MinIO; path-style addressing is used when set
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Endpoint *string `json:"endpoint,omitempty"`
+
+	// +optional
+	// +kubebuilder:default="us-east-1"
+	// +kubebuilder:validation:Type=string
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret holds the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys
+	CredentialsSecret corev1.LocalObjectReference `json:"credentialsSecret"`
+}
+
+type RestorePhase string
+
+const (
+	RestoreRestoring RestorePhase = "Restoring"
+	RestoreCompleted RestorePhase = "Completed"
+	RestoreSkipped   RestorePhase = "Skipped"
+)
+
+type RestoreStatus struct {
+	// +optional
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// Backup is the TypesenseBackup the snapshot was resolved from, if any
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// Source is the snapshot resolved when the restore started, the init container keeps using it afterward
+	// +optional
+	Source *RestoreSourceSpec `json:"source,omitempty"`
+
+	// CommittedIndex of the snapshot, when known
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+func (s *RestoreSpec) GetImage() string {
+	if s.Image != "" {
+		return s.Image
+	}
+	return "amazon/aws-cli:2.22.35"
+}
+
+func (s *RestoreSourceSpec) GetRegion() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	return "us-east-1"
+}
+
+// IsRestoring reports whether the data volumes are still being seeded from a snapshot
+func (s *TypesenseClusterStatus) IsRestoring() bool {
+	return s.Restore != nil && s.Restore.Phase == RestoreRestoring
+}
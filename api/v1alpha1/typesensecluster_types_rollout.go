@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type RollingUpdatePhase string
+
+const (
+	RollingUpdateInProgress RollingUpdatePhase = "InProgress"
+	RollingUpdatePaused     RollingUpdatePhase = "Paused"
+	RollingUpdateCompleted  RollingUpdatePhase = "Completed"
+)
+
+// RollingUpdateStatus tracks the rollout of a new pod template, which restarts the followers one at a time and the
+// leader last
+type RollingUpdateStatus struct {
+	// +optional
+	Phase RollingUpdatePhase `json:"phase,omitempty"`
+
+	// Revision of the StatefulSet the pods are rolled to
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Image of the Typesense container the pods are rolled to
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Pod restarted last, it has to catch up with the leader before the next one is restarted
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// CommittedIndex of the leader when Pod was restarted
+	// +optional
+	CommittedIndex int64 `json:"committedIndex,omitempty"`
+
+	// LeaderStepDownAt is when the leader was asked to hand over to a follower, before it is restarted
+	// +optional
+	LeaderStepDownAt *metav1.Time `json:"leaderStepDownAt,omitempty"`
+
+	// UpdatedReplicas is the number of pods already running the new revision
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// +optional
+	Nodes []RollingUpdateNodeStatus `json:"nodes,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+type RollingUpdateNodeStatus struct {
+	Pod string `json:"pod"`
+
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// +optional
+	Updated bool `json:"updated,omitempty"`
+}
+
+// IsRollingUpdate reports whether pods are still being rolled to a new revision
+func (s *TypesenseClusterStatus) IsRollingUpdate() bool {
+	return s.RollingUpdate != nil && s.RollingUpdate.Phase != RollingUpdateCompleted
+}
@@ -0,0 +1,27 @@
+package v1alpha1
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// SnapshotsSpec adds an emptyDir volume to the nodes, next to their data volume, the snapshots of a TypesenseBackup
+// are written to before they are uploaded. It is left out of the pods of clusters that are never backed up, as it
+// takes node ephemeral storage and adding it restarts every node.
+type SnapshotsSpec struct {
+	// SizeLimit of the snapshots volume, a snapshot takes about as much as the data set; a node whose snapshot
+	// exceeds it is evicted. The size of the data volume is used when omitted
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+// IsSnapshotsVolumeEnabled reports whether the nodes get a snapshots volume, which a final snapshot needs as well
+func (s *TypesenseClusterSpec) IsSnapshotsVolumeEnabled() bool {
+	return s.Snapshots != nil || s.FinalSnapshot != nil
+}
+
+// GetSnapshotsSizeLimit returns the size limit of the snapshots volume, the size of the data volume by default
+func (s *TypesenseClusterSpec) GetSnapshotsSizeLimit() resource.Quantity {
+	if s.Snapshots != nil && s.Snapshots.SizeLimit != nil {
+		return *s.Snapshots.SizeLimit
+	}
+
+	return s.GetStorage().Size
+}
@@ -1,6 +1,9 @@
 package v1alpha1
 
-import "k8s.io/apimachinery/pkg/api/resource"
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 type StorageSpec struct {
 
@@ -8,6 +11,7 @@ type StorageSpec struct {
 	// +kubebuilder:default="100Mi"
 	Size resource.Quantity `json:"size,omitempty"`
 
+	// StorageClassName of the data volumes, the default storage class of the cluster is used if it is empty
 	StorageClassName string `json:"storageClassName"`
 
 	// +kubebuilder:validation:Optional
@@ -16,6 +20,14 @@ type StorageSpec struct {
 	AccessMode string `json:"accessMode,omitempty"`
 
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// RetentionPolicy defines whether the data volumes are kept or removed when the cluster is deleted, the volumes of
+	// nodes removed by a scale down are always kept
+	// +optional
+	// +kubebuilder:default="Retain"
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:validation:Type=string
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
 }
 
 func (s *TypesenseClusterSpec) GetStorage() StorageSpec {
@@ -27,5 +39,47 @@ func (s *TypesenseClusterSpec) GetStorage() StorageSpec {
 		Size:             resource.MustParse("100Mi"),
 		StorageClassName: "standard",
 		AccessMode:       "ReadWriteOnce",
+		RetentionPolicy:  "Retain",
+	}
+}
+
+func (s *StorageSpec) GetRetentionPolicy() appsv1.PersistentVolumeClaimRetentionPolicyType {
+	if s.RetentionPolicy == string(appsv1.DeletePersistentVolumeClaimRetentionPolicyType) {
+		return appsv1.DeletePersistentVolumeClaimRetentionPolicyType
 	}
+
+	return appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+}
+
+type VolumeClaimPhase string
+
+const (
+	VolumeClaimResizing                VolumeClaimPhase = "Resizing"
+	VolumeClaimFileSystemResizePending VolumeClaimPhase = "FileSystemResizePending"
+	VolumeClaimResized                 VolumeClaimPhase = "Resized"
+)
+
+// StorageStatus reports the expansion of the data volumes, once the size of the storage has grown
+type StorageStatus struct {
+	// Size the data volumes are expanded to
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+
+	// +optional
+	VolumeClaims []VolumeClaimStatus `json:"volumeClaims,omitempty"`
+}
+
+type VolumeClaimStatus struct {
+	Name string `json:"name"`
+
+	// Requested storage in the spec of the PersistentVolumeClaim
+	// +optional
+	Requested resource.Quantity `json:"requested,omitempty"`
+
+	// Capacity reported in the status of the PersistentVolumeClaim
+	// +optional
+	Capacity resource.Quantity `json:"capacity,omitempty"`
+
+	// +optional
+	Phase VolumeClaimPhase `json:"phase,omitempty"`
 }
@@ -0,0 +1,77 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// TLSSpec serves the Typesense API over https, with a certificate read from a kubernetes.io/tls Secret that is either
+// provided or issued by cert-manager. The peering traffic between the nodes stays in plain text, as Typesense does not
+// support TLS for raft, so it should be confined to a trusted network, e.g. with a NetworkPolicy. Enabling or disabling
+// TLS on a running cluster changes the scheme the nodes forward writes to the leader with, so the nodes become
+// unreachable until they are all restarted and the quorum is expected to go down for a moment.
+// +kubebuilder:validation:XValidation:rule="has(self.secretName) || has(self.issuerRef)",message="either secretName or issuerRef must be set"
+type TLSSpec struct {
+	// SecretName of the kubernetes.io/tls Secret holding tls.crt, tls.key and, optionally, ca.crt. When the certificate
+	// is issued by cert-manager it is written into this Secret, which defaults to <cluster>-tls
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	SecretName *string `json:"secretName,omitempty"`
+
+	// IssuerRef requests the certificate from a cert-manager Issuer or ClusterIssuer
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// DNSNames added to the issued certificate, on top of the names of the services and the pods of the cluster
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Items:Type=string
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+type IssuerReference struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Name string `json:"name"`
+
+	// +optional
+	// +kubebuilder:default=Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +kubebuilder:validation:Type=string
+	Kind string `json:"kind,omitempty"`
+
+	// +optional
+	// +kubebuilder:default="cert-manager.io"
+	// +kubebuilder:validation:Type=string
+	Group string `json:"group,omitempty"`
+}
+
+// TLSStatus reports the certificate the pods are serving the Typesense API with
+type TLSStatus struct {
+	SecretName string `json:"secretName"`
+
+	// Certificate is the cert-manager Certificate that issued the certificate, if any
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+
+	// Checksum of the certificate, the pods are rolled when it changes
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// HasCA is true when the Secret carries the ca.crt that is trusted by the sidecars and the scrapers
+	// +optional
+	HasCA bool `json:"hasCA,omitempty"`
+
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+}
+
+func (s *TypesenseClusterSpec) IsTLSEnabled() bool {
+	return s.TLS != nil
+}
+
+// GetApiScheme returns the scheme the Typesense API is served with
+func (s *TypesenseClusterSpec) GetApiScheme() string {
+	if s.IsTLSEnabled() {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VerticalScalingMode string
+
+const (
+	VerticalScalingRecommend VerticalScalingMode = "Recommend"
+	VerticalScalingAuto      VerticalScalingMode = "Auto"
+)
+
+// VerticalScalingSpec sizes the memory of the typesense container after the resident memory the nodes report, as
+// Typesense keeps its indexes in RAM. The recommendation is always recorded in the status; in Auto mode the memory
+// limit is raised to it within the bounds, and the pods are rolled followers first. The limit is never lowered.
+type VerticalScalingSpec struct {
+	// +optional
+	// +kubebuilder:default=Recommend
+	// +kubebuilder:validation:Enum=Recommend;Auto
+	Mode VerticalScalingMode `json:"mode,omitempty"`
+
+	// MinMemory the recommendation never goes below
+	// +optional
+	MinMemory *resource.Quantity `json:"minMemory,omitempty"`
+
+	// MaxMemory the recommendation never goes above
+	MaxMemory resource.Quantity `json:"maxMemory"`
+
+	// TargetMemoryUtilizationPercentage of the memory limit the busiest node should use
+	// +optional
+	// +kubebuilder:default=70
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=95
+	// +kubebuilder:validation:Type=integer
+	TargetMemoryUtilizationPercentage int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// OutOfMemoryIncrementPercentage the memory limit grows by at least, when a node reports OUT_OF_MEMORY
+	// +optional
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=200
+	// +kubebuilder:validation:Type=integer
+	OutOfMemoryIncrementPercentage int32 `json:"outOfMemoryIncrementPercentage,omitempty"`
+
+	// CooldownSeconds between two resizes, so that a resize is rolled out before the next one is considered
+	// +optional
+	// +kubebuilder:default=1800
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Type=integer
+	CooldownSeconds int32 `json:"cooldownSeconds,omitempty"`
+}
+
+// VerticalScalingStatus reports the memory recommendation and the usage it was based on
+type VerticalScalingStatus struct {
+	// MemoryUsage is the highest resident memory reported by a node
+	// +optional
+	MemoryUsage *resource.Quantity `json:"memoryUsage,omitempty"`
+
+	// +optional
+	MemoryLimit *resource.Quantity `json:"memoryLimit,omitempty"`
+
+	// +optional
+	RecommendedMemory *resource.Quantity `json:"recommendedMemory,omitempty"`
+
+	// +optional
+	OutOfMemory []string `json:"outOfMemory,omitempty"`
+
+	// +optional
+	LastResizeTime *metav1.Time `json:"lastResizeTime,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+func (s *TypesenseClusterSpec) IsVerticalScalingEnabled() bool {
+	return s.VerticalScaling != nil
+}
+
+func (s *TypesenseClusterSpec) IsVerticalScalingAuto() bool {
+	return s.VerticalScaling != nil && s.VerticalScaling.Mode == VerticalScalingAuto
+}
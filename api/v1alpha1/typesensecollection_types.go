@@ -0,0 +1,178 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseCollectionSpec defines the desired state of TypesenseCollection
+type TypesenseCollectionSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, that hosts the collection
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Name of the collection in Typesense, defaults to the name of the resource
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Name *string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:MinItems=1
+	Fields []CollectionFieldSpec `json:"fields"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	DefaultSortingField *string `json:"defaultSortingField,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Items:Type=string
+	TokenSeparators []string `json:"tokenSeparators,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Items:Type=string
+	SymbolsToIndex []string `json:"symbolsToIndex,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	EnableNestedFields *bool `json:"enableNestedFields,omitempty"`
+
+	// AllowFieldDrops lets the operator drop the applied fields that are no longer declared, and drop and re-add the
+	// fields whose definition changed, which reindexes them. Otherwise these differences are only reported as drift
+	// +optional
+	// +kubebuilder:default=false
+	// +kubebuilder:validation:Type=boolean
+	AllowFieldDrops bool `json:"allowFieldDrops,omitempty"`
+}
+
+type CollectionFieldSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum=string;"string[]";int32;"int32[]";int64;"int64[]";float;"float[]";bool;"bool[]";geopoint;"geopoint[]";geopolygon;object;"object[]";"string*";image;auto
+	// +kubebuilder:validation:Type=string
+	Type string `json:"type"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Facet *bool `json:"facet,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Optional *bool `json:"optional,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Index *bool `json:"index,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Sort *bool `json:"sort,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Infix *bool `json:"infix,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	Store *bool `json:"store,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Locale *string `json:"locale,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Type=integer
+	NumDim *int `json:"numDim,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Reference *string `json:"reference,omitempty"`
+}
+
+// TypesenseCollectionStatus defines the observed state of TypesenseCollection
+type TypesenseCollectionStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	CollectionName string `json:"collectionName,omitempty"`
+
+	// AppliedSchema is the field schema as last reported by Typesense
+	// +optional
+	AppliedSchema []CollectionFieldSpec `json:"appliedSchema,omitempty"`
+
+	// +optional
+	NumDocuments int64 `json:"numDocuments,omitempty"`
+
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// SchemaDrift lists the differences between the desired and the applied schema that are not patched in place,
+	// because Typesense does not allow it or because they require dropping fields
+	// +optional
+	SchemaDrift []string `json:"schemaDrift,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseCollection is the Schema for the typesensecollections API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Collection",type=string,JSONPath=`.status.collectionName`
+// +kubebuilder:printcolumn:name="Documents",type=integer,JSONPath=`.status.numDocuments`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseCollection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseCollectionSpec   `json:"spec,omitempty"`
+	Status TypesenseCollectionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseCollectionList contains a list of TypesenseCollection
+type TypesenseCollectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseCollection `json:"items"`
+}
+
+// GetCollectionName returns the name of the collection in Typesense
+func (c *TypesenseCollection) GetCollectionName() string {
+	if c.Spec.Name != nil && *c.Spec.Name != "" {
+		return *c.Spec.Name
+	}
+
+	return c.Name
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseCollection{}, &TypesenseCollectionList{})
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseOperationSpec defines the desired state of TypesenseOperation
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="an operation is executed once, its spec is immutable"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Snapshot' || has(self.snapshotPath)",message="snapshotPath must be set for a Snapshot"
+type TypesenseOperationSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, to run the operation on
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Type of the operation: Vote makes the leader step down, Snapshot writes a snapshot to SnapshotPath, Compact
+	// compacts the database, ClearCache clears the search cache and ResetPeers makes the node reload the nodes list
+	// +kubebuilder:validation:Enum=Vote;Snapshot;Compact;ClearCache;ResetPeers
+	// +kubebuilder:validation:Type=string
+	Type TypesenseOperationType `json:"type"`
+
+	// Node is the pod to run the operation on, or "leader" or "all"
+	// +optional
+	// +kubebuilder:default="leader"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Node string `json:"node,omitempty"`
+
+	// SnapshotPath is the directory, on the data volume of the node, a Snapshot is written to
+	// +optional
+	// +kubebuilder:validation:Type=string
+	SnapshotPath string `json:"snapshotPath,omitempty"`
+
+	// Timeout of the request to every node
+	// +optional
+	// +kubebuilder:default="5m"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type TypesenseOperationType string
+
+const (
+	OperationVote       TypesenseOperationType = "Vote"
+	OperationSnapshot   TypesenseOperationType = "Snapshot"
+	OperationCompact    TypesenseOperationType = "Compact"
+	OperationClearCache TypesenseOperationType = "ClearCache"
+	OperationResetPeers TypesenseOperationType = "ResetPeers"
+)
+
+const (
+	OperationNodeLeader = "leader"
+	OperationNodeAll    = "all"
+)
+
+type TypesenseOperationPhase string
+
+const (
+	OperationPending   TypesenseOperationPhase = "Pending"
+	OperationRunning   TypesenseOperationPhase = "Running"
+	OperationCompleted TypesenseOperationPhase = "Completed"
+	OperationFailed    TypesenseOperationPhase = "Failed"
+)
+
+// OperationNodeResult is the outcome of the operation on a single node
+type OperationNodeResult struct {
+	Pod string `json:"pod"`
+
+	// Role of the node in the raft quorum when the operation was run
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	Succeeded bool `json:"succeeded"`
+
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// TypesenseOperationStatus defines the observed state of TypesenseOperation
+type TypesenseOperationStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase TypesenseOperationPhase `json:"phase,omitempty"`
+
+	// +optional
+	Nodes []OperationNodeResult `json:"nodes,omitempty"`
+
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseOperation is the Schema for the typesenseoperations API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.node`
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completedAt`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+type TypesenseOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseOperationSpec   `json:"spec,omitempty"`
+	Status TypesenseOperationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseOperationList contains a list of TypesenseOperation
+type TypesenseOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseOperation `json:"items"`
+}
+
+func (o *TypesenseOperation) GetNode() string {
+	if o.Spec.Node != "" {
+		return o.Spec.Node
+	}
+	return OperationNodeLeader
+}
+
+func (o *TypesenseOperation) GetTimeout() time.Duration {
+	if o.Spec.Timeout != nil {
+		return o.Spec.Timeout.Duration
+	}
+	return 5 * time.Minute
+}
+
+func (o *TypesenseOperation) IsFinished() bool {
+	return o.Status.Phase == OperationCompleted || o.Status.Phase == OperationFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseOperation{}, &TypesenseOperationList{})
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseOverrideSpec defines the desired state of TypesenseOverride
+type TypesenseOverrideSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, that hosts the collection
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Collection the curation overrides are defined for
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Collection string `json:"collection"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=id
+	Overrides []OverrideSpec `json:"overrides"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.includes) || has(self.excludes) || has(self.filterBy) || has(self.sortBy) || has(self.replaceQuery) || has(self.removeMatchedTokens)",message="an override must curate the results in some way"
+type OverrideSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Id string `json:"id"`
+
+	Rule OverrideRuleSpec `json:"rule"`
+
+	// +kubebuilder:validation:Optional
+	Includes []OverrideIncludeSpec `json:"includes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Excludes []OverrideExcludeSpec `json:"excludes,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	FilterBy *string `json:"filterBy,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	SortBy *string `json:"sortBy,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	ReplaceQuery *string `json:"replaceQuery,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	RemoveMatchedTokens *bool `json:"removeMatchedTokens,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	FilterCuratedHits *bool `json:"filterCuratedHits,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	StopProcessing *bool `json:"stopProcessing,omitempty"`
+
+	// +optional
+	EffectiveFrom *metav1.Time `json:"effectiveFrom,omitempty"`
+
+	// +optional
+	EffectiveTo *metav1.Time `json:"effectiveTo,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.query) || has(self.filterBy) || has(self.tags)",message="a rule must match a query, a filter or tags"
+// +kubebuilder:validation:XValidation:rule="has(self.query) == has(self.match)",message="query and match must be set together"
+type OverrideRuleSpec struct {
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Query *string `json:"query,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=exact;contains
+	// +kubebuilder:validation:Type=string
+	Match *string `json:"match,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	FilterBy *string `json:"filterBy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Items:Type=string
+	Tags []string `json:"tags,omitempty"`
+}
+
+type OverrideIncludeSpec struct {
+	// Id of the document to pin
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Id string `json:"id"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Type=integer
+	Position int `json:"position"`
+}
+
+type OverrideExcludeSpec struct {
+	// Id of the document to hide
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Id string `json:"id"`
+}
+
+// TypesenseOverrideStatus defines the observed state of TypesenseOverride
+type TypesenseOverrideStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Collection the entries were last applied to
+	// +optional
+	Collection string `json:"collection,omitempty"`
+
+	// Applied are the ids of the overrides managed in the collection, the ones removed from the spec are pruned
+	// +optional
+	Applied []string `json:"applied,omitempty"`
+
+	// Drift lists the overrides last found modified or deleted outside the operator, they have been restored since
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+
+	// +optional
+	DriftDetectedAt *metav1.Time `json:"driftDetectedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseOverride is the Schema for the typesenseoverrides API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Collection",type=string,JSONPath=`.spec.collection`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseOverrideSpec   `json:"spec,omitempty"`
+	Status TypesenseOverrideStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseOverrideList contains a list of TypesenseOverride
+type TypesenseOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseOverride `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseOverride{}, &TypesenseOverrideList{})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseStopwordSetSpec defines the desired state of TypesenseStopwordSet. Stopword sets are global to a cluster,
+// searches on any collection opt in by passing the name of the set in the stopwords parameter.
+type TypesenseStopwordSetSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, the stopword set is created in
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Name of the stopword set in Typesense, defaults to the name of the resource
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Name *string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Items:Type=string
+	Stopwords []string `json:"stopwords"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Locale *string `json:"locale,omitempty"`
+}
+
+// TypesenseStopwordSetStatus defines the observed state of TypesenseStopwordSet
+type TypesenseStopwordSetStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	StopwordSetName string `json:"stopwordSetName,omitempty"`
+
+	// Drift reports the stopword set was last found modified or deleted outside the operator, it has been restored since
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+
+	// +optional
+	DriftDetectedAt *metav1.Time `json:"driftDetectedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseStopwordSet is the Schema for the typesensestopwordsets API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Stopword Set",type=string,JSONPath=`.status.stopwordSetName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseStopwordSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseStopwordSetSpec   `json:"spec,omitempty"`
+	Status TypesenseStopwordSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseStopwordSetList contains a list of TypesenseStopwordSet
+type TypesenseStopwordSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseStopwordSet `json:"items"`
+}
+
+// GetStopwordSetName returns the name of the stopword set in Typesense
+func (s *TypesenseStopwordSet) GetStopwordSetName() string {
+	if s.Spec.Name != nil && *s.Spec.Name != "" {
+		return *s.Spec.Name
+	}
+
+	return s.Name
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseStopwordSet{}, &TypesenseStopwordSetList{})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypesenseSynonymSetSpec defines the desired state of TypesenseSynonymSet
+type TypesenseSynonymSetSpec struct {
+	// Cluster is the TypesenseCluster, in the same namespace, that hosts the collection
+	Cluster corev1.LocalObjectReference `json:"cluster"`
+
+	// Collection the synonyms are defined for
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Collection string `json:"collection"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=id
+	Synonyms []SynonymSpec `json:"synonyms"`
+}
+
+type SynonymSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Type=string
+	Id string `json:"id"`
+
+	// Root makes the synonym one-way, the synonyms are expanded to the root but not the other way around
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Root *string `json:"root,omitempty"`
+
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Items:Type=string
+	Synonyms []string `json:"synonyms"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Locale *string `json:"locale,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Items:Type=string
+	SymbolsToIndex []string `json:"symbolsToIndex,omitempty"`
+}
+
+// TypesenseSynonymSetStatus defines the observed state of TypesenseSynonymSet
+type TypesenseSynonymSetStatus struct {
+
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Collection the entries were last applied to
+	// +optional
+	Collection string `json:"collection,omitempty"`
+
+	// Applied are the ids of the synonyms managed in the collection, the ones removed from the spec are pruned
+	// +optional
+	Applied []string `json:"applied,omitempty"`
+
+	// Drift lists the synonyms last found modified or deleted outside the operator, they have been restored since
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+
+	// +optional
+	DriftDetectedAt *metav1.Time `json:"driftDetectedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// TypesenseSynonymSet is the Schema for the typesensesynonymsets API
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`
+// +kubebuilder:printcolumn:name="Collection",type=string,JSONPath=`.spec.collection`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+type TypesenseSynonymSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TypesenseSynonymSetSpec   `json:"spec,omitempty"`
+	Status TypesenseSynonymSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TypesenseSynonymSetList contains a list of TypesenseSynonymSet
+type TypesenseSynonymSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TypesenseSynonymSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TypesenseSynonymSet{}, &TypesenseSynonymSetList{})
+}
@@ -38,6 +38,7 @@ import (
 
 	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
 	"github.com/akyriako/typesense-operator/internal/controller"
+	webhookv1alpha1 "github.com/akyriako/typesense-operator/internal/webhook/v1alpha1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -247,6 +248,113 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "TypesenseCluster")
 		os.Exit(1)
 	}
+	if err = (&controller.TypesenseCollectionReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensecollection-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseCollection")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseApiKeyReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesenseapikey-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseApiKey")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseBackupReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensebackup-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseBackup")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseBackupScheduleReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensebackupschedule-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseBackupSchedule")
+		os.Exit(1)
+	}
+	// webhooks are opt-in, they need the [WEBHOOK] and [CERTMANAGER] sections of config/default to be enabled
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookv1alpha1.SetupTypesenseClusterWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TypesenseCluster")
+			os.Exit(1)
+		}
+	}
+	if err = (&controller.TypesenseAliasReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensealias-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseAlias")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseSynonymSetReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensesynonymset-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseSynonymSet")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseOverrideReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesenseoverride-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseOverride")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseStopwordSetReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesensestopwordset-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseStopwordSet")
+		os.Exit(1)
+	}
+	if err = (&controller.TypesenseOperationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("typesenseoperation-controller"),
+		ClientSet:     clientSet,
+		Configuration: mgr.GetConfig(),
+		InCluster:     isInCluster(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TypesenseOperation")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
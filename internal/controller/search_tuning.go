@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Definitions shared by the synonym set, override and stopword set status conditions
+const (
+	ConditionReasonCollectionNotFound = "CollectionNotFound"
+	ConditionReasonDriftCorrected     = "DriftCorrected"
+)
+
+var errCollectionNotFound = errors.New("collection not found")
+
+// searchTuningEntry is an entry (synonym, override, stopword set) the operator keeps in sync with its spec
+type searchTuningEntry struct {
+	Id   string
+	Body any
+}
+
+// searchTuningEntries converges a group of entries living under the same path of the Typesense API, e.g. the
+// synonyms of a collection. Entries are compared field by field with what the cluster serves, so only the ones that
+// are missing or were modified outside the operator get upserted, and the ones dropped from the spec are deleted.
+type searchTuningEntries struct {
+	api    *typesenseApiClient
+	logger logr.Logger
+	// path the entries live under, e.g. /collections/{collection}/synonyms
+	path string
+	// key the single entry responses are wrapped in, if any
+	envelope string
+}
+
+// reconcile upserts the desired entries and prunes the previously applied ones that are no longer desired. Every
+// entry is upserted when force is set, as fields dropped from the spec cannot be told apart from server defaults.
+// It returns the ids of the applied entries and a report of those found modified or deleted outside the operator.
+func (e *searchTuningEntries) reconcile(ctx context.Context, desired []searchTuningEntry, previous []string, force bool) ([]string, []string, error) {
+	applied := make([]string, 0, len(desired))
+	drift := make([]string, 0)
+
+	for _, entry := range desired {
+		managed := slices.Contains(previous, entry.Id)
+
+		current, err := e.get(ctx, entry.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if current != nil && !force {
+			inSync, err := jsonContains(current, entry.Body)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if inSync {
+				applied = append(applied, entry.Id)
+				continue
+			}
+		}
+
+		if managed && !force {
+			if current == nil {
+				drift = append(drift, fmt.Sprintf("%s was deleted", entry.Id))
+			} else {
+				drift = append(drift, fmt.Sprintf("%s was modified", entry.Id))
+			}
+		}
+
+		e.logger.V(debugLevel).Info("upserting entry", "path", e.path, "id", entry.Id)
+		if err := e.api.do(ctx, http.MethodPut, e.entryPath(entry.Id), nil, entry.Body, nil); err != nil {
+			e.logger.Error(err, "upserting entry failed", "path", e.path, "id", entry.Id)
+			return nil, nil, err
+		}
+
+		applied = append(applied, entry.Id)
+	}
+
+	for _, id := range previous {
+		if slices.Contains(applied, id) {
+			continue
+		}
+
+		if err := e.delete(ctx, id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return applied, drift, nil
+}
+
+// deleteAll deletes the given entries; entries that are already gone are not an error
+func (e *searchTuningEntries) deleteAll(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if err := e.delete(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *searchTuningEntries) get(ctx context.Context, id string) (any, error) {
+	var current map[string]any
+	if err := e.api.do(ctx, http.MethodGet, e.entryPath(id), nil, nil, &current); err != nil {
+		if isTypesenseApiNotFound(err) {
+			return nil, nil
+		}
+
+		e.logger.Error(err, "unable to fetch entry", "path", e.path, "id", id)
+		return nil, err
+	}
+
+	if e.envelope != "" {
+		return current[e.envelope], nil
+	}
+
+	return current, nil
+}
+
+func (e *searchTuningEntries) delete(ctx context.Context, id string) error {
+	err := e.api.do(ctx, http.MethodDelete, e.entryPath(id), nil, nil, nil)
+	if err != nil && !isTypesenseApiNotFound(err) {
+		e.logger.Error(err, "deleting entry failed", "path", e.path, "id", id)
+		return err
+	}
+
+	e.logger.V(debugLevel).Info("deleted entry", "path", e.path, "id", id)
+	return nil
+}
+
+func (e *searchTuningEntries) entryPath(id string) string {
+	return e.path + "/" + url.PathEscape(id)
+}
+
+// collectionExists checks whether the collection the entries are bound to exists
+func collectionExists(ctx context.Context, api *typesenseApiClient, collection string) error {
+	err := api.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(collection), nil, nil, nil)
+	if err != nil && isTypesenseApiNotFound(err) {
+		return errCollectionNotFound
+	}
+
+	return err
+}
+
+// jsonContains reports whether every field set in desired has the same value in current. Lists are compared
+// regardless of their order, and fields the server fills in with defaults are ignored.
+func jsonContains(current any, desired any) (bool, error) {
+	raw, err := json.Marshal(desired)
+	if err != nil {
+		return false, err
+	}
+
+	var want any
+	if err := json.Unmarshal(raw, &want); err != nil {
+		return false, err
+	}
+
+	return jsonValueContains(current, want), nil
+}
+
+func jsonValueContains(current any, desired any) bool {
+	switch d := desired.(type) {
+	case map[string]any:
+		c, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		for k, v := range d {
+			if !jsonValueContains(c[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		c, ok := current.([]any)
+		if !ok || len(c) != len(d) {
+			return false
+		}
+
+		matched := make([]bool, len(c))
+		for _, v := range d {
+			found := false
+			for i := range c {
+				if !matched[i] && jsonValueContains(c[i], v) {
+					matched[i], found = true, true
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(current, desired)
+	}
+}
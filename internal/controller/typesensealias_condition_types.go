@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonAliasReady              = "AliasReady"
+	ConditionReasonAliasNotReady           = "AliasNotReady"
+	ConditionReasonAliasCollectionNotFound = "AliasCollectionNotFound"
+
+	UpdateAliasStatusMessageFailed = "failed to update typesense alias status"
+)
+
+func (r *TypesenseAliasReconciler) initConditions(ctx context.Context, ta *tsv1alpha1.TypesenseAlias) error {
+	if len(ta.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, ta, func(status *tsv1alpha1.TypesenseAliasStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateAliasStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseAliasReconciler) setConditionNotReady(ctx context.Context, ta *tsv1alpha1.TypesenseAlias, reason string, err error) error {
+	if err := r.patchStatus(ctx, ta, func(status *tsv1alpha1.TypesenseAliasStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseAliasReconciler) setConditionReady(ctx context.Context, ta *tsv1alpha1.TypesenseAlias, reason string) error {
+	if err := r.patchStatus(ctx, ta, func(status *tsv1alpha1.TypesenseAliasStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Alias is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseAliasReconciler) patchStatus(
+	ctx context.Context,
+	ta *tsv1alpha1.TypesenseAlias,
+	patcher func(status *tsv1alpha1.TypesenseAliasStatus),
+) error {
+	patch := client.MergeFrom(ta.DeepCopy())
+	patcher(&ta.Status)
+
+	err := r.Status().Patch(ctx, ta, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense alias status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	aliasFinalizer = "ts.opentelekomcloud.com/delete-alias"
+)
+
+var errAliasCollectionNotFound = errors.New("target collection not found")
+
+// TypesenseAliasReconciler reconciles a TypesenseAlias object
+type TypesenseAliasReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// alias is the wire representation of an alias in the Typesense API
+type alias struct {
+	Name           string `json:"name,omitempty"`
+	CollectionName string `json:"collection_name"`
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensealiases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensealiases/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensealiases/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile points the alias in the referenced TypesenseCluster at the desired collection, swapping it atomically
+// when the collection changes and remembering the previous target for a rollback. Deleting a TypesenseAlias deletes
+// the alias, the collections behind it are left in place.
+func (r *TypesenseAliasReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "alias", req.Name)
+
+	var ta tsv1alpha1.TypesenseAlias
+	if err := r.Get(ctx, req.NamespacedName, &ta); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: ta.Namespace, Name: ta.Spec.Cluster.Name}
+
+	if !ta.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &ta, clusterObjectKey)
+	}
+
+	if !controllerutil.ContainsFinalizer(&ta, aliasFinalizer) {
+		controllerutil.AddFinalizer(&ta, aliasFinalizer)
+		if err := r.Update(ctx, &ta); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.logger.Info("reconciling alias")
+
+	err := r.initConditions(ctx, &ta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &ta, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	err = r.ReconcileAlias(ctx, &ta, api)
+	if err != nil {
+		if errors.Is(err, errAliasCollectionNotFound) {
+			err = fmt.Errorf("collection %s not found", ta.Spec.Collection)
+			report := ta.Status.Phase != ConditionReasonAliasCollectionNotFound
+
+			cerr := r.setConditionNotReady(ctx, &ta, ConditionReasonAliasCollectionNotFound, err)
+			if cerr != nil {
+				return ctrl.Result{}, cerr
+			}
+
+			if report {
+				r.Recorder.Eventf(&ta, "Warning", ConditionReasonAliasCollectionNotFound, toTitle(err.Error()))
+			}
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		cerr := r.setConditionNotReady(ctx, &ta, ConditionReasonAliasNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	cerr := r.setConditionReady(ctx, &ta, ConditionReasonAliasReady)
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.logger.Info("reconciling alias completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileAlias upserts the alias when it is missing or points at another collection, either because the spec
+// changed or because it was swapped outside the operator, and records the swap in the status.
+func (r *TypesenseAliasReconciler) ReconcileAlias(ctx context.Context, ta *tsv1alpha1.TypesenseAlias, api *typesenseApiClient) error {
+	r.logger.V(debugLevel).Info("reconciling alias target")
+
+	aliasName := ta.GetAliasName()
+	desired := ta.Spec.Collection
+
+	err := api.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(desired), nil, nil, nil)
+	if err != nil {
+		if isTypesenseApiNotFound(err) {
+			return errAliasCollectionNotFound
+		}
+
+		r.logger.Error(err, "unable to fetch collection", "collection", desired)
+		return err
+	}
+
+	aliasPath := "/aliases/" + url.PathEscape(aliasName)
+	aliasExists := true
+
+	var current = &alias{}
+	if err := api.do(ctx, http.MethodGet, aliasPath, nil, nil, current); err != nil {
+		if isTypesenseApiNotFound(err) {
+			aliasExists = false
+		} else {
+			r.logger.Error(err, "unable to fetch alias", "alias", aliasName)
+			return err
+		}
+	}
+
+	previous := ta.Status.PreviousCollection
+	swappedAt := ta.Status.SwappedAt
+
+	if !aliasExists || current.CollectionName != desired {
+		r.logger.V(debugLevel).Info("upserting alias", "alias", aliasName, "collection", desired)
+
+		if err := api.do(ctx, http.MethodPut, aliasPath, nil, alias{CollectionName: desired}, nil); err != nil {
+			r.logger.Error(err, "upserting alias failed", "alias", aliasName)
+			return err
+		}
+
+		if aliasExists {
+			now := metav1.Now()
+			previous = current.CollectionName
+			swappedAt = &now
+
+			r.Recorder.Eventf(ta, "Normal", "AliasSwapped", "Swapped alias %s from %s to %s", aliasName, previous, desired)
+		} else {
+			r.Recorder.Eventf(ta, "Normal", "AliasCreated", "Created alias %s pointing at %s", aliasName, desired)
+		}
+	}
+
+	if ta.Status.AliasName != "" && ta.Status.AliasName != aliasName {
+		r.deleteAlias(ctx, api, ta.Status.AliasName)
+	}
+
+	return r.patchStatus(ctx, ta, func(status *tsv1alpha1.TypesenseAliasStatus) {
+		status.ObservedGeneration = ta.Generation
+		status.AliasName = aliasName
+		status.Collection = desired
+		status.PreviousCollection = previous
+		status.SwappedAt = swappedAt
+	})
+}
+
+// deleteAlias deletes the alias from the cluster; an alias that is already gone is not an error
+func (r *TypesenseAliasReconciler) deleteAlias(ctx context.Context, api *typesenseApiClient, name string) bool {
+	err := api.do(ctx, http.MethodDelete, "/aliases/"+url.PathEscape(name), nil, nil, nil)
+	if err != nil && !isTypesenseApiNotFound(err) {
+		r.logger.Error(err, "deleting alias failed", "alias", name)
+		return false
+	}
+
+	r.logger.V(debugLevel).Info("deleted alias", "alias", name)
+	return true
+}
+
+func (r *TypesenseAliasReconciler) finalize(ctx context.Context, ta *tsv1alpha1.TypesenseAlias, clusterObjectKey client.ObjectKey) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(ta, aliasFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("deleting alias")
+
+	if ta.Status.AliasName != "" {
+		_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for typesense cluster to delete alias", "cluster", clusterObjectKey.Name, "reason", err.Error())
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		// a cluster that is gone took its aliases along, nothing left to delete
+		if api != nil && !r.deleteAlias(ctx, api, ta.Status.AliasName) {
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ta, aliasFinalizer)
+	if err := r.Update(ctx, ta); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseAliasReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseAlias{}, eventFilters).
+		Named("typesensealias").
+		Complete(r)
+}
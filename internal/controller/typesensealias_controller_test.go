@@ -0,0 +1,157 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseAlias Controller", func() {
+	Context("When reconciling the target of a TypesenseAlias", func() {
+		const resourceName = "test-alias"
+
+		ctx := context.Background()
+
+		var ta *tsv1alpha1.TypesenseAlias
+		var aliases map[string]string
+		var server *httptest.Server
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseAliasReconciler
+
+		collections := []string{"companies-v1", "companies-v2"}
+
+		BeforeEach(func() {
+			aliases = map[string]string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+
+				if name, ok := strings.CutPrefix(r.URL.Path, "/collections/"); ok {
+					if !slices.Contains(collections, name) {
+						writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+						return
+					}
+
+					writeJson(w, http.StatusOK, collectionSchema{Name: name})
+					return
+				}
+
+				name := strings.TrimPrefix(r.URL.Path, "/aliases/")
+				switch r.Method {
+				case http.MethodGet:
+					collection, ok := aliases[name]
+					if !ok {
+						writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+						return
+					}
+
+					writeJson(w, http.StatusOK, alias{Name: name, CollectionName: collection})
+				case http.MethodPut:
+					var upsert alias
+					Expect(json.NewDecoder(r.Body).Decode(&upsert)).To(Succeed())
+					aliases[name] = upsert.CollectionName
+
+					writeJson(w, http.StatusOK, alias{Name: name, CollectionName: upsert.CollectionName})
+				}
+			}))
+
+			ta = &tsv1alpha1.TypesenseAlias{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseAliasSpec{
+					Cluster:    corev1.LocalObjectReference{Name: "fake"},
+					Collection: "companies-v1",
+				},
+			}
+			Expect(k8sClient.Create(ctx, ta)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseAliasReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(k8sClient.Delete(ctx, ta)).To(Succeed())
+		})
+
+		reconcileAlias := func(collection string) {
+			ta.Spec.Collection = collection
+			Expect(controllerReconciler.ReconcileAlias(ctx, ta, newFakeTypesenseApiClient(server))).To(Succeed())
+			Expect(aliases).To(HaveKeyWithValue(resourceName, collection))
+			Expect(ta.Status.Collection).To(Equal(collection))
+		}
+
+		It("should create the alias pointing at the collection", func() {
+			reconcileAlias("companies-v1")
+
+			Expect(ta.Status.AliasName).To(Equal(resourceName))
+			Expect(ta.Status.PreviousCollection).To(BeEmpty())
+			Expect(ta.Status.SwappedAt).To(BeNil())
+			Expect(<-recorder.Events).To(ContainSubstring("AliasCreated"))
+		})
+
+		It("should remember the previous target when swapping and roll back to it", func() {
+			reconcileAlias("companies-v1")
+			<-recorder.Events
+
+			By("swapping the alias to the new collection")
+			reconcileAlias("companies-v2")
+			Expect(ta.Status.PreviousCollection).To(Equal("companies-v1"))
+			Expect(ta.Status.SwappedAt).NotTo(BeNil())
+			Expect(<-recorder.Events).To(ContainSubstring("Swapped alias test-alias from companies-v1 to companies-v2"))
+
+			By("rolling back to the previous collection")
+			reconcileAlias(ta.Status.PreviousCollection)
+			Expect(ta.Status.PreviousCollection).To(Equal("companies-v2"))
+			Expect(<-recorder.Events).To(ContainSubstring("Swapped alias test-alias from companies-v2 to companies-v1"))
+		})
+
+		It("should restore an alias swapped outside the operator", func() {
+			reconcileAlias("companies-v1")
+			aliases[resourceName] = "companies-v2"
+
+			reconcileAlias("companies-v1")
+			Expect(ta.Status.PreviousCollection).To(Equal("companies-v2"))
+		})
+
+		It("should leave the alias untouched when the collection does not exist", func() {
+			reconcileAlias("companies-v1")
+
+			ta.Spec.Collection = "companies-v3"
+			err := controllerReconciler.ReconcileAlias(ctx, ta, newFakeTypesenseApiClient(server))
+			Expect(err).To(MatchError(errAliasCollectionNotFound))
+			Expect(aliases).To(HaveKeyWithValue(resourceName, "companies-v1"))
+			Expect(ta.Status.Collection).To(Equal("companies-v1"))
+		})
+	})
+})
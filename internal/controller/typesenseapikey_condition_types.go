@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonApiKeyReady    = "ApiKeyReady"
+	ConditionReasonApiKeyNotReady = "ApiKeyNotReady"
+	ConditionReasonApiKeyExpired  = "ApiKeyExpired"
+	ConditionReasonApiKeyOrphaned = "ApiKeyOrphaned"
+
+	UpdateApiKeyStatusMessageFailed = "failed to update typesense api key status"
+)
+
+func (r *TypesenseApiKeyReconciler) initConditions(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey) error {
+	if len(tk.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, tk, func(status *tsv1alpha1.TypesenseApiKeyStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateApiKeyStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseApiKeyReconciler) setConditionNotReady(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, reason string, err error) error {
+	if err := r.patchStatus(ctx, tk, func(status *tsv1alpha1.TypesenseApiKeyStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseApiKeyReconciler) setConditionReady(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, reason string) error {
+	if err := r.patchStatus(ctx, tk, func(status *tsv1alpha1.TypesenseApiKeyStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Api key is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseApiKeyReconciler) patchStatus(
+	ctx context.Context,
+	tk *tsv1alpha1.TypesenseApiKey,
+	patcher func(status *tsv1alpha1.TypesenseApiKeyStatus),
+) error {
+	patch := client.MergeFrom(tk.DeepCopy())
+	patcher(&tk.Status)
+
+	err := r.Status().Patch(ctx, tk, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense api key status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	apiKeyFinalizer       = "ts.opentelekomcloud.com/revoke-api-key"
+	apiKeyIdAnnotationKey = "ts.opentelekomcloud.com/api-key-id"
+
+	// apiKeyRevocationTimeout is how long the deletion of a TypesenseApiKey waits for its key to be revoked
+	apiKeyRevocationTimeout = 10 * time.Minute
+)
+
+// TypesenseApiKeyReconciler reconciles a TypesenseApiKey object
+type TypesenseApiKeyReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// apiKey is the wire representation of a key in the Typesense API
+type apiKey struct {
+	Id          *int64   `json:"id,omitempty"`
+	Value       string   `json:"value,omitempty"`
+	ValuePrefix string   `json:"value_prefix,omitempty"`
+	Description string   `json:"description"`
+	Actions     []string `json:"actions"`
+	Collections []string `json:"collections"`
+	ExpiresAt   int64    `json:"expires_at,omitempty"`
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseapikeys,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseapikeys/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseapikeys/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile mints a scoped key in the referenced TypesenseCluster and writes its value into the target Secret.
+// Typesense keys are immutable, so any change of the spec mints a new key, swaps it into the Secret and revokes the
+// previous one. Deleting a TypesenseApiKey revokes its key.
+func (r *TypesenseApiKeyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "apikey", req.Name)
+
+	var tk tsv1alpha1.TypesenseApiKey
+	if err := r.Get(ctx, req.NamespacedName, &tk); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: tk.Namespace, Name: tk.Spec.Cluster.Name}
+
+	if !tk.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &tk, clusterObjectKey)
+	}
+
+	if !controllerutil.ContainsFinalizer(&tk, apiKeyFinalizer) {
+		controllerutil.AddFinalizer(&tk, apiKeyFinalizer)
+		if err := r.Update(ctx, &tk); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.logger.Info("reconciling api key")
+
+	err := r.initConditions(ctx, &tk)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &tk, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	key, err := r.ReconcileApiKey(ctx, &tk, api)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &tk, ConditionReasonApiKeyNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	err = r.patchStatus(ctx, &tk, func(status *tsv1alpha1.TypesenseApiKeyStatus) {
+		status.ObservedGeneration = tk.Generation
+		status.KeyId = key.Id
+		status.KeyPrefix = key.ValuePrefix
+		status.SecretName = tk.GetSecretName()
+		status.ExpiresAt = nil
+		if key.ExpiresAt > 0 && tk.Spec.ExpiresAt != nil {
+			expiresAt := metav1.NewTime(time.Unix(key.ExpiresAt, 0))
+			status.ExpiresAt = &expiresAt
+		}
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if tk.Status.ExpiresAt != nil && tk.Status.ExpiresAt.Time.Before(time.Now()) {
+		err := fmt.Errorf("api key expired at %s", tk.Status.ExpiresAt.Format(time.RFC3339))
+		report := tk.Status.Phase != ConditionReasonApiKeyExpired
+
+		cerr := r.setConditionNotReady(ctx, &tk, ConditionReasonApiKeyExpired, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+
+		if report {
+			r.Recorder.Eventf(&tk, "Warning", ConditionReasonApiKeyExpired, toTitle(err.Error()))
+		}
+	} else {
+		cerr := r.setConditionReady(ctx, &tk, ConditionReasonApiKeyReady)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+	}
+
+	r.logger.Info("reconciling api key completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileApiKey makes sure a key matching the current spec exists and that its value is stored in the target
+// Secret. A new key is minted if none was minted yet, if the spec changed, if the Secret was lost, or if the key
+// is not known to the cluster anymore.
+func (r *TypesenseApiKeyReconciler) ReconcileApiKey(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, api *typesenseApiClient) (*apiKey, error) {
+	r.logger.V(debugLevel).Info("reconciling api key secret")
+
+	secretName := tk.GetSecretName()
+	secretExists := true
+	secretObjectKey := client.ObjectKey{Namespace: tk.Namespace, Name: secretName}
+
+	var secret = &corev1.Secret{}
+	if err := r.Get(ctx, secretObjectKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			secretExists = false
+		} else {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch secret: %s", secretName))
+			return nil, err
+		}
+	}
+
+	if secretExists && !metav1.IsControlledBy(secret, tk) {
+		return nil, fmt.Errorf("secret %s already exists and is not managed by this api key", secretName)
+	}
+
+	// the secret records the key it holds, the status may still point to the previous one if it could not be patched
+	// after the last rotation, and that key is revoked already
+	keyId := tk.Status.KeyId
+	if secretExists {
+		if id, err := strconv.ParseInt(secret.Annotations[apiKeyIdAnnotationKey], 10, 64); err == nil {
+			keyId = &id
+		}
+	}
+
+	mint := keyId == nil || !secretExists || len(secret.Data[tk.GetSecretKey()]) == 0 ||
+		tk.Status.ObservedGeneration != tk.Generation
+
+	var current = &apiKey{}
+	if !mint {
+		err := api.do(ctx, http.MethodGet, fmt.Sprintf("/keys/%d", *keyId), nil, nil, current)
+		if err != nil {
+			if !isTypesenseApiNotFound(err) {
+				r.logger.Error(err, "unable to fetch api key", "id", *keyId)
+				return nil, err
+			}
+
+			r.logger.Info("api key not found in cluster", "id", *keyId)
+			mint = true
+		}
+	}
+
+	if !mint {
+		return current, nil
+	}
+
+	r.logger.V(debugLevel).Info("creating api key")
+
+	key, err := r.createApiKey(ctx, tk, api)
+	if err != nil {
+		r.logger.Error(err, "creating api key failed")
+		return nil, err
+	}
+
+	if secretExists {
+		patch := client.MergeFrom(secret.DeepCopy())
+		secret.Data = map[string][]byte{tk.GetSecretKey(): []byte(key.Value)}
+		secret.Annotations = mergeLabels(secret.Annotations, map[string]string{apiKeyIdAnnotationKey: strconv.FormatInt(*key.Id, 10)})
+		err = r.Patch(ctx, secret, patch)
+	} else {
+		err = r.createApiKeySecret(ctx, tk, secretObjectKey, key)
+	}
+	if err != nil {
+		r.logger.Error(err, "writing api key secret failed", "secret", secretName)
+		r.revokeApiKey(ctx, api, *key.Id)
+		return nil, err
+	}
+
+	if keyId != nil && *keyId != *key.Id {
+		r.revokeApiKey(ctx, api, *keyId)
+		r.Recorder.Eventf(tk, "Normal", "ApiKeyRotated", "Replaced api key %d with %d", *keyId, *key.Id)
+	} else {
+		r.Recorder.Eventf(tk, "Normal", "ApiKeyCreated", "Created api key %d", *key.Id)
+	}
+
+	if tk.Status.SecretName != "" && tk.Status.SecretName != secretName {
+		r.deleteOrphanedApiKeySecret(ctx, tk, tk.Status.SecretName)
+	}
+
+	key.Value = ""
+	return key, nil
+}
+
+func (r *TypesenseApiKeyReconciler) createApiKey(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, api *typesenseApiClient) (*apiKey, error) {
+	desired := apiKey{
+		Description: fmt.Sprintf("%s/%s", tk.Namespace, tk.Name),
+		Actions:     tk.Spec.Actions,
+		Collections: tk.Spec.Collections,
+	}
+
+	if tk.Spec.Description != nil {
+		desired.Description = *tk.Spec.Description
+	}
+
+	if tk.Spec.ExpiresAt != nil {
+		desired.ExpiresAt = tk.Spec.ExpiresAt.Unix()
+	}
+
+	var key = &apiKey{}
+	if err := api.do(ctx, http.MethodPost, "/keys", nil, desired, key); err != nil {
+		return nil, err
+	}
+
+	if key.Id == nil || key.Value == "" {
+		return nil, errors.New("typesense api returned a key without id or value")
+	}
+
+	return key, nil
+}
+
+func (r *TypesenseApiKeyReconciler) createApiKeySecret(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, key client.ObjectKey, ak *apiKey) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "typesense-operator",
+				"app.kubernetes.io/name":       "typesense",
+				"app.kubernetes.io/instance":   tk.Spec.Cluster.Name,
+			},
+			Annotations: map[string]string{
+				apiKeyIdAnnotationKey: strconv.FormatInt(*ak.Id, 10),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			tk.GetSecretKey(): []byte(ak.Value),
+		},
+	}
+
+	err := ctrl.SetControllerReference(tk, secret, r.Scheme)
+	if err != nil {
+		return err
+	}
+
+	return r.Create(ctx, secret)
+}
+
+func (r *TypesenseApiKeyReconciler) deleteOrphanedApiKeySecret(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, name string) {
+	var secret = &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tk.Namespace, Name: name}, secret); err != nil {
+		return
+	}
+
+	if !metav1.IsControlledBy(secret, tk) {
+		return
+	}
+
+	if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		r.logger.Error(err, "deleting orphaned api key secret failed", "secret", name)
+	}
+}
+
+// revokeApiKey deletes the key from the cluster; a key that is already gone is not an error
+func (r *TypesenseApiKeyReconciler) revokeApiKey(ctx context.Context, api *typesenseApiClient, id int64) bool {
+	err := api.do(ctx, http.MethodDelete, fmt.Sprintf("/keys/%d", id), nil, nil, nil)
+	if err != nil && !isTypesenseApiNotFound(err) {
+		r.logger.Error(err, "revoking api key failed", "id", id)
+		return false
+	}
+
+	r.logger.V(debugLevel).Info("revoked api key", "id", id)
+	return true
+}
+
+// finalize revokes the key before the TypesenseApiKey is deleted. A cluster that is gone or being deleted takes its
+// keys along, while a cluster that cannot revoke the key within apiKeyRevocationTimeout leaves it orphaned, so the
+// deletion is never blocked for good; the orphaned key is reported in a warning event on both objects.
+func (r *TypesenseApiKeyReconciler) finalize(ctx context.Context, tk *tsv1alpha1.TypesenseApiKey, clusterObjectKey client.ObjectKey) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(tk, apiKeyFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("revoking api key")
+
+	if tk.Status.KeyId != nil {
+		ts, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+
+		revoked := false
+		switch {
+		case apierrors.IsNotFound(err):
+			// a cluster that is gone took its keys along, nothing left to revoke
+			revoked = true
+		case ts != nil && !ts.DeletionTimestamp.IsZero():
+			r.logger.V(debugLevel).Info("skipping revoking api key of deleted typesense cluster", "cluster", clusterObjectKey.Name)
+			revoked = true
+		case err != nil:
+			r.logger.V(debugLevel).Info("waiting for typesense cluster to revoke api key", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		default:
+			revoked = r.revokeApiKey(ctx, api, *tk.Status.KeyId)
+		}
+
+		if !revoked {
+			if time.Since(tk.DeletionTimestamp.Time) < apiKeyRevocationTimeout {
+				return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+			}
+
+			r.reportOrphanedApiKey(tk, ts)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(tk, apiKeyFinalizer)
+	if err := r.Update(ctx, tk); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reportOrphanedApiKey records the id of a key that is left in the cluster, so it can be revoked by hand
+func (r *TypesenseApiKeyReconciler) reportOrphanedApiKey(tk *tsv1alpha1.TypesenseApiKey, ts *tsv1alpha1.TypesenseCluster) {
+	r.logger.Info("giving up revoking api key", "id", *tk.Status.KeyId, "prefix", tk.Status.KeyPrefix, "cluster", tk.Spec.Cluster.Name)
+
+	format := "Api key %d (%s) of %s/%s could not be revoked within %s and is left in cluster %s"
+	args := []any{*tk.Status.KeyId, tk.Status.KeyPrefix, tk.Namespace, tk.Name, apiKeyRevocationTimeout, tk.Spec.Cluster.Name}
+	r.Recorder.Eventf(tk, "Warning", ConditionReasonApiKeyOrphaned, format, args...)
+	if ts != nil {
+		r.Recorder.Eventf(ts, "Warning", ConditionReasonApiKeyOrphaned, format, args...)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseApiKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseApiKey{}, eventFilters).
+		Owns(&corev1.Secret{}).
+		Named("typesenseapikey").
+		Complete(r)
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+// deletedSinceClient keeps the deletion timestamp the test moved back in time out of the updates, it is immutable
+type deletedSinceClient struct {
+	client.Client
+}
+
+func (c *deletedSinceClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	current := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return err
+	}
+
+	obj.SetDeletionTimestamp(current.GetDeletionTimestamp())
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+var _ = Describe("TypesenseApiKey Controller", func() {
+	Context("When reconciling the key of a TypesenseApiKey", func() {
+		const resourceName = "test-apikey"
+
+		ctx := context.Background()
+
+		var tk *tsv1alpha1.TypesenseApiKey
+		var keys map[int64]apiKey
+		var minted, revoked []int64
+		var server *httptest.Server
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseApiKeyReconciler
+
+		BeforeEach(func() {
+			keys = map[int64]apiKey{}
+			minted, revoked = nil, nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+
+				if r.Method == http.MethodPost && r.URL.Path == "/keys" {
+					var key apiKey
+					Expect(json.NewDecoder(r.Body).Decode(&key)).To(Succeed())
+					key.Id = ptr.To(int64(len(minted) + 1))
+					key.ValuePrefix = fmt.Sprintf("v%d", *key.Id)
+					keys[*key.Id] = key
+					minted = append(minted, *key.Id)
+
+					key.Value = fmt.Sprintf("value-%d", *key.Id)
+					writeJson(w, http.StatusCreated, key)
+					return
+				}
+
+				id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/keys/"), 10, 64)
+				Expect(err).NotTo(HaveOccurred())
+				key, ok := keys[id]
+
+				switch {
+				case id == http.StatusInternalServerError:
+					writeJson(w, http.StatusInternalServerError, map[string]string{"message": "internal error"})
+				case !ok:
+					writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+				case r.Method == http.MethodGet:
+					writeJson(w, http.StatusOK, key)
+				case r.Method == http.MethodDelete:
+					delete(keys, id)
+					revoked = append(revoked, id)
+					writeJson(w, http.StatusOK, map[string]int64{"id": id})
+				}
+			}))
+
+			tk = &tsv1alpha1.TypesenseApiKey{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseApiKeySpec{
+					Cluster:     corev1.LocalObjectReference{Name: "fake"},
+					Actions:     []string{"documents:search"},
+					Collections: []string{"*"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tk)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseApiKeyReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tk)).To(Succeed())
+		})
+
+		getSecret := func() *corev1.Secret {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: tk.GetSecretName(), Namespace: "default"}, secret)).To(Succeed())
+			return secret
+		}
+
+		// mint reconciles the key once and records it in the status, as Reconcile does
+		mint := func() *apiKey {
+			key, err := controllerReconciler.ReconcileApiKey(ctx, tk, newFakeTypesenseApiClient(server))
+			Expect(err).NotTo(HaveOccurred())
+
+			tk.Status.KeyId = key.Id
+			tk.Status.KeyPrefix = key.ValuePrefix
+			tk.Status.ObservedGeneration = tk.Generation
+			return key
+		}
+
+		It("should mint a key and store its value in the secret", func() {
+			key := mint()
+			Expect(*key.Id).To(Equal(int64(1)))
+			Expect(key.Value).To(BeEmpty())
+			Expect(keys[1].Actions).To(Equal(tk.Spec.Actions))
+			Expect(keys[1].Description).To(Equal("default/test-apikey"))
+
+			secret := getSecret()
+			Expect(metav1.IsControlledBy(secret, tk)).To(BeTrue())
+			Expect(secret.Annotations).To(HaveKeyWithValue(apiKeyIdAnnotationKey, "1"))
+			Expect(secret.Data).To(HaveKeyWithValue(tk.GetSecretKey(), []byte("value-1")))
+			Expect(<-recorder.Events).To(ContainSubstring("ApiKeyCreated"))
+		})
+
+		It("should keep the key as long as the spec is unchanged", func() {
+			mint()
+			<-recorder.Events
+
+			key := mint()
+			Expect(*key.Id).To(Equal(int64(1)))
+			Expect(minted).To(Equal([]int64{1}))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should rotate the key when the spec changes", func() {
+			mint()
+			<-recorder.Events
+
+			// as the api server does on a spec change
+			tk.Spec.Actions = []string{"documents:search", "documents:get"}
+			tk.Generation++
+
+			key := mint()
+			Expect(*key.Id).To(Equal(int64(2)))
+			Expect(keys[2].Actions).To(Equal(tk.Spec.Actions))
+			Expect(revoked).To(Equal([]int64{1}))
+
+			secret := getSecret()
+			Expect(secret.Annotations).To(HaveKeyWithValue(apiKeyIdAnnotationKey, "2"))
+			Expect(secret.Data).To(HaveKeyWithValue(tk.GetSecretKey(), []byte("value-2")))
+			Expect(<-recorder.Events).To(ContainSubstring("Replaced api key 1 with 2"))
+		})
+
+		It("should revoke the key held by the secret when the status was not updated after a rotation", func() {
+			mint()
+			tk.Spec.Actions = []string{"documents:search", "documents:get"}
+			tk.Generation++
+			_, err := controllerReconciler.ReconcileApiKey(ctx, tk, newFakeTypesenseApiClient(server))
+			Expect(err).NotTo(HaveOccurred())
+
+			// the status still points to the first key
+			tk.Spec.Actions = []string{"documents:get"}
+			tk.Generation++
+
+			key := mint()
+			Expect(*key.Id).To(Equal(int64(3)))
+			Expect(revoked).To(Equal([]int64{1, 2}))
+			Expect(getSecret().Annotations).To(HaveKeyWithValue(apiKeyIdAnnotationKey, "3"))
+		})
+
+		It("should mint a new key when the key is gone from the cluster", func() {
+			mint()
+			delete(keys, 1)
+
+			key := mint()
+			Expect(*key.Id).To(Equal(int64(2)))
+			Expect(getSecret().Data).To(HaveKeyWithValue(tk.GetSecretKey(), []byte("value-2")))
+		})
+
+		It("should revoke a key and treat a key that is gone as revoked", func() {
+			api := newFakeTypesenseApiClient(server)
+			mint()
+
+			Expect(controllerReconciler.revokeApiKey(ctx, api, 1)).To(BeTrue())
+			Expect(revoked).To(Equal([]int64{1}))
+			Expect(controllerReconciler.revokeApiKey(ctx, api, 1)).To(BeTrue())
+			Expect(controllerReconciler.revokeApiKey(ctx, api, http.StatusInternalServerError)).To(BeFalse())
+		})
+	})
+
+	Context("When a TypesenseApiKey is deleted", func() {
+		const resourceName = "test-apikey-finalize"
+		const clusterName = "test-apikey-finalize-cluster"
+
+		ctx := context.Background()
+
+		var tk *tsv1alpha1.TypesenseApiKey
+		var ts *tsv1alpha1.TypesenseCluster
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseApiKeyReconciler
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: "default", Finalizers: []string{clusterFinalizer}},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			tk = &tsv1alpha1.TypesenseApiKey{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default", Finalizers: []string{apiKeyFinalizer}},
+				Spec: tsv1alpha1.TypesenseApiKeySpec{
+					Cluster:     corev1.LocalObjectReference{Name: clusterName},
+					Actions:     []string{"documents:search"},
+					Collections: []string{"*"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tk)).To(Succeed())
+			tk.Status.KeyId = ptr.To(int64(42))
+			tk.Status.KeyPrefix = "abcd"
+			Expect(k8sClient.Status().Update(ctx, tk)).To(Succeed())
+
+			Expect(k8sClient.Delete(ctx, tk)).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tk), tk)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseApiKeyReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			current := &tsv1alpha1.TypesenseApiKey{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tk), current); err == nil {
+				current.Finalizers = nil
+				Expect(k8sClient.Update(ctx, current)).To(Succeed())
+			}
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), ts)).To(Succeed())
+			ts.Finalizers = nil
+			Expect(k8sClient.Update(ctx, ts)).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, ts))).To(Succeed())
+		})
+
+		isDeleted := func() bool {
+			return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(tk), &tsv1alpha1.TypesenseApiKey{}))
+		}
+
+		It("should wait for a cluster that is not ready to revoke the key", func() {
+			result, err := controllerReconciler.finalize(ctx, tk, client.ObjectKeyFromObject(ts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(reconcileRequeuePeriod))
+			Expect(isDeleted()).To(BeFalse())
+		})
+
+		It("should not wait for a cluster that is being deleted", func() {
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+
+			result, err := controllerReconciler.finalize(ctx, tk, client.ObjectKeyFromObject(ts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			Expect(isDeleted()).To(BeTrue())
+		})
+
+		It("should give up revoking the key after the timeout and report it as orphaned", func() {
+			controllerReconciler.Client = &deletedSinceClient{Client: k8sClient}
+			tk.DeletionTimestamp = ptr.To(metav1.NewTime(time.Now().Add(-apiKeyRevocationTimeout)))
+
+			result, err := controllerReconciler.finalize(ctx, tk, client.ObjectKeyFromObject(ts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			Expect(isDeleted()).To(BeTrue())
+
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(And(ContainSubstring(ConditionReasonApiKeyOrphaned), ContainSubstring("42 (abcd)")))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonBackupInProgress  = "BackupInProgress"
+	ConditionReasonBackupCompleted   = "BackupCompleted"
+	ConditionReasonBackupFailed      = "BackupFailed"
+	ConditionReasonScheduleReady     = "ScheduleReady"
+	ConditionReasonScheduleSuspended = "ScheduleSuspended"
+	ConditionReasonScheduleInvalid   = "ScheduleInvalid"
+)
+
+func (r *TypesenseBackupReconciler) setBackupPhase(ctx context.Context, tb *tsv1alpha1.TypesenseBackup, phase tsv1alpha1.TypesenseBackupPhase, status metav1.ConditionStatus, reason string, message string) error {
+	return r.patchStatus(ctx, tb, func(s *tsv1alpha1.TypesenseBackupStatus) {
+		meta.SetStatusCondition(&s.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: status, Reason: reason, Message: message})
+		s.Phase = phase
+	})
+}
+
+func (r *TypesenseBackupReconciler) patchStatus(
+	ctx context.Context,
+	tb *tsv1alpha1.TypesenseBackup,
+	patcher func(status *tsv1alpha1.TypesenseBackupStatus),
+) error {
+	patch := client.MergeFrom(tb.DeepCopy())
+	patcher(&tb.Status)
+
+	err := r.Status().Patch(ctx, tb, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense backup status")
+		return err
+	}
+
+	return nil
+}
+
+func (r *TypesenseBackupScheduleReconciler) setCondition(ctx context.Context, tbs *tsv1alpha1.TypesenseBackupSchedule, status metav1.ConditionStatus, reason string, message string) error {
+	return r.patchStatus(ctx, tbs, func(s *tsv1alpha1.TypesenseBackupScheduleStatus) {
+		meta.SetStatusCondition(&s.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: status, Reason: reason, Message: message})
+	})
+}
+
+func (r *TypesenseBackupScheduleReconciler) patchStatus(
+	ctx context.Context,
+	tbs *tsv1alpha1.TypesenseBackupSchedule,
+	patcher func(status *tsv1alpha1.TypesenseBackupScheduleStatus),
+) error {
+	patch := client.MergeFrom(tbs.DeepCopy())
+	patcher(&tbs.Status)
+
+	err := r.Status().Patch(ctx, tbs, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense backup schedule status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,624 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	backupFinalizer       = "ts.opentelekomcloud.com/delete-backup"
+	backupLabelKey        = "ts.opentelekomcloud.com/backup"
+	typesenseDataDir      = "/usr/share/typesense/data"
+	typesenseSnapshotsDir = "/usr/share/typesense/snapshots"
+	backupSnapshotTimeout = 10 * time.Minute
+	backupRequeuePeriod   = 15 * time.Second
+	// ephemeral containers cannot be removed from a pod, they are only dropped when it is recreated
+	maxUploadContainers = 10
+)
+
+const backupUploadScript = `set -e
+trap 'rm -rf "$SNAPSHOT_DIR"' EXIT
+if [ -n "$S3_ENDPOINT" ]; then
+  aws configure set default.s3.addressing_style path
+  set -- --endpoint-url "$S3_ENDPOINT"
+fi
+aws "$@" s3 cp --recursive --only-show-errors "$SNAPSHOT_DIR" "$DESTINATION"
+du -sb "$SNAPSHOT_DIR" | cut -f1 > /dev/termination-log
+`
+
+const backupCleanupScript = `set -e
+if [ -n "$S3_ENDPOINT" ]; then
+  aws configure set default.s3.addressing_style path
+  set -- --endpoint-url "$S3_ENDPOINT"
+fi
+aws "$@" s3 rm --recursive --only-show-errors "$DESTINATION"
+`
+
+// TypesenseBackupReconciler reconciles a TypesenseBackup object
+type TypesenseBackupReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+
+	// snapshots in flight, keyed by the uid of their backup
+	snapshots sync.Map
+}
+
+// pendingSnapshot is a snapshot taken in the background, as the leader answers only once it is written
+type pendingSnapshot struct {
+	done chan struct{}
+	err  error
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods/ephemeralcontainers,verbs=update
+
+// Reconcile takes a snapshot on the current leader of the referenced TypesenseCluster, on the snapshots volume next
+// to its data volume, and uploads it to object storage from an ephemeral container added to the leader pod, the only
+// one that can mount that volume. The data volume is never mounted outside the typesense pods.
+func (r *TypesenseBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "backup", req.Name)
+
+	var tb tsv1alpha1.TypesenseBackup
+	if err := r.Get(ctx, req.NamespacedName, &tb); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !tb.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &tb)
+	}
+
+	if tb.Spec.DeletionPolicy == "Delete" && !controllerutil.ContainsFinalizer(&tb, backupFinalizer) {
+		controllerutil.AddFinalizer(&tb, backupFinalizer)
+		if err := r.Update(ctx, &tb); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch tb.Status.Phase {
+	case "", tsv1alpha1.BackupPending:
+		r.logger.Info("reconciling backup")
+		return r.takeSnapshot(ctx, &tb)
+	case tsv1alpha1.BackupSnapshotting:
+		return r.trackSnapshot(ctx, &tb)
+	case tsv1alpha1.BackupUploading:
+		return r.trackUpload(ctx, &tb)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *TypesenseBackupReconciler) takeSnapshot(ctx context.Context, tb *tsv1alpha1.TypesenseBackup) (ctrl.Result, error) {
+	clusterObjectKey := client.ObjectKey{Namespace: tb.Namespace, Name: tb.Spec.Cluster.Name}
+	ts, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setBackupPhase(ctx, tb, tsv1alpha1.BackupPending, metav1.ConditionFalse, ConditionReasonClusterNotReady, err.Error())
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	leader, leaderStatus, err := getLeaderNode(ctx, r.Client, api)
+	if err != nil {
+		r.logger.Info("waiting for a leader", "cluster", ts.Name, "reason", err.Error())
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+	}
+
+	var leaderPod = &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: leader.PodName}, leaderPod); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !hasSnapshotsVolume(leaderPod) {
+		message := fmt.Sprintf("leader %s has no snapshots volume, set spec.snapshots of cluster %s and wait for it to be rolled out", leader.PodName, ts.Name)
+		r.logger.Info("waiting for the snapshots volume", "leader", leader.PodName)
+		cerr := r.setBackupPhase(ctx, tb, tsv1alpha1.BackupPending, metav1.ConditionFalse, ConditionReasonClusterNotReady, message)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+	}
+
+	if count := countUploadContainers(leaderPod); count >= maxUploadContainers && !hasEphemeralContainer(leaderPod, getUploadContainerName(tb)) {
+		return r.fail(ctx, tb, fmt.Errorf("leader %s already has %d backup upload containers, which are only removed when the pod is recreated", leader.PodName, count))
+	}
+
+	// the leader takes one snapshot at a time, the others wait for it instead of failing
+	snapshotting, err := r.getSnapshottingBackup(ctx, tb)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if snapshotting != "" {
+		message := fmt.Sprintf("waiting for backup %s to finish its snapshot", snapshotting)
+		cerr := r.setBackupPhase(ctx, tb, tsv1alpha1.BackupPending, metav1.ConditionFalse, ConditionReasonClusterNotReady, message)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+	}
+
+	snapshotPath := path.Join(typesenseSnapshotsDir, tb.Name)
+	now := metav1.Now()
+
+	err = r.patchStatus(ctx, tb, func(status *tsv1alpha1.TypesenseBackupStatus) {
+		status.Phase = tsv1alpha1.BackupSnapshotting
+		status.LeaderPod = leader.PodName
+		status.CommittedIndex = int64(leaderStatus.CommittedIndex)
+		status.SnapshotPath = snapshotPath
+		status.StartedAt = &now
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.logger.V(debugLevel).Info("taking snapshot", "leader", leader.PodName, "path", snapshotPath, "committedIndex", leaderStatus.CommittedIndex)
+	r.startSnapshot(ctx, tb, api.withNode(leader), snapshotPath)
+
+	return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+}
+
+// startSnapshot asks the leader for a snapshot in the background, a large data set may take minutes to be written
+// and would otherwise hold the worker of every other backup
+func (r *TypesenseBackupReconciler) startSnapshot(ctx context.Context, tb *tsv1alpha1.TypesenseBackup, api *typesenseApiClient, snapshotPath string) {
+	snapshot := &pendingSnapshot{done: make(chan struct{})}
+	r.snapshots.Store(tb.UID, snapshot)
+
+	query := url.Values{"snapshot_path": []string{snapshotPath}}
+	go func() {
+		defer close(snapshot.done)
+		snapshot.err = api.withTimeout(backupSnapshotTimeout).do(ctx, http.MethodPost, "/operations/snapshot", query, nil, nil)
+	}()
+}
+
+// trackSnapshot waits for the snapshot started by takeSnapshot and adds the upload container to the leader pod once
+// it is written. A snapshot that is not tracked anymore, because the operator was restarted, is taken again.
+func (r *TypesenseBackupReconciler) trackSnapshot(ctx context.Context, tb *tsv1alpha1.TypesenseBackup) (ctrl.Result, error) {
+	value, ok := r.snapshots.Load(tb.UID)
+	if !ok {
+		r.logger.Info("snapshot is not tracked anymore, taking it again", "leader", tb.Status.LeaderPod)
+		return r.takeSnapshot(ctx, tb)
+	}
+
+	snapshot := value.(*pendingSnapshot)
+	select {
+	case <-snapshot.done:
+	default:
+		r.logger.V(debugLevel).Info("waiting for snapshot", "leader", tb.Status.LeaderPod, "path", tb.Status.SnapshotPath)
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+	}
+
+	if snapshot.err != nil {
+		r.snapshots.Delete(tb.UID)
+		r.logger.Error(snapshot.err, "taking snapshot failed", "leader", tb.Status.LeaderPod)
+		return r.fail(ctx, tb, fmt.Errorf("taking snapshot on %s failed: %w", tb.Status.LeaderPod, snapshot.err))
+	}
+
+	var leaderPod = &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tb.Namespace, Name: tb.Status.LeaderPod}, leaderPod); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.snapshots.Delete(tb.UID)
+			return r.fail(ctx, tb, fmt.Errorf("pod %s was deleted before the snapshot was uploaded", tb.Status.LeaderPod))
+		}
+		return ctrl.Result{}, err
+	}
+
+	container := r.buildUploadContainer(tb, tb.Status.SnapshotPath)
+	if err := r.addEphemeralContainer(ctx, leaderPod, container); err != nil {
+		r.logger.Error(err, "adding backup upload container failed", "leader", leaderPod.Name, "container", container.Name)
+		return ctrl.Result{}, err
+	}
+
+	err := r.patchStatus(ctx, tb, func(status *tsv1alpha1.TypesenseBackupStatus) {
+		status.Phase = tsv1alpha1.BackupUploading
+		status.UploadContainer = container.Name
+		status.Location = tb.GetLocation()
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.snapshots.Delete(tb.UID)
+	r.Recorder.Eventf(tb, "Normal", "SnapshotTaken", "Snapshot taken on %s at committed index %d", leaderPod.Name, tb.Status.CommittedIndex)
+	return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+}
+
+// getSnapshottingBackup returns the name of another backup of the same cluster whose snapshot is being taken
+func (r *TypesenseBackupReconciler) getSnapshottingBackup(ctx context.Context, tb *tsv1alpha1.TypesenseBackup) (string, error) {
+	var backups tsv1alpha1.TypesenseBackupList
+	if err := r.List(ctx, &backups, client.InNamespace(tb.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, backup := range backups.Items {
+		if backup.UID != tb.UID && backup.Spec.Cluster.Name == tb.Spec.Cluster.Name && backup.Status.Phase == tsv1alpha1.BackupSnapshotting {
+			return backup.Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (r *TypesenseBackupReconciler) trackUpload(ctx context.Context, tb *tsv1alpha1.TypesenseBackup) (ctrl.Result, error) {
+	var pod = &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tb.Namespace, Name: tb.Status.LeaderPod}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.fail(ctx, tb, fmt.Errorf("pod %s was deleted before the snapshot was uploaded", tb.Status.LeaderPod))
+		}
+		return ctrl.Result{}, err
+	}
+
+	// a pod recreated under the same name has neither the upload container nor the snapshot anymore
+	state, ok := getEphemeralContainerState(pod, tb.Status.UploadContainer)
+	if !ok {
+		return r.fail(ctx, tb, fmt.Errorf("pod %s was recreated before the snapshot was uploaded", tb.Status.LeaderPod))
+	}
+
+	if state.Terminated == nil {
+		r.logger.V(debugLevel).Info("waiting for backup upload", "pod", pod.Name, "container", tb.Status.UploadContainer)
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+	}
+
+	if state.Terminated.ExitCode != 0 {
+		return r.fail(ctx, tb, fmt.Errorf("backup upload container %s on %s exited with code %d", tb.Status.UploadContainer, tb.Status.LeaderPod, state.Terminated.ExitCode))
+	}
+
+	// the upload container reports the size of the snapshot in its termination message
+	size, _ := strconv.ParseInt(strings.TrimSpace(state.Terminated.Message), 10, 64)
+	now := metav1.Now()
+
+	err := r.patchStatus(ctx, tb, func(status *tsv1alpha1.TypesenseBackupStatus) {
+		status.Phase = tsv1alpha1.BackupCompleted
+		status.SizeBytes = size
+		status.CompletedAt = &now
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.setBackupPhase(ctx, tb, tsv1alpha1.BackupCompleted, metav1.ConditionTrue, ConditionReasonBackupCompleted, fmt.Sprintf("Backup uploaded to %s", tb.Status.Location))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.Recorder.Eventf(tb, "Normal", ConditionReasonBackupCompleted, "Backup uploaded to %s", tb.Status.Location)
+	r.logger.Info("reconciling backup completed", "location", tb.Status.Location, "size", size)
+	return ctrl.Result{}, nil
+}
+
+func (r *TypesenseBackupReconciler) fail(ctx context.Context, tb *tsv1alpha1.TypesenseBackup, err error) (ctrl.Result, error) {
+	cerr := r.setBackupPhase(ctx, tb, tsv1alpha1.BackupFailed, metav1.ConditionFalse, ConditionReasonBackupFailed, err.Error())
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.Recorder.Eventf(tb, "Warning", ConditionReasonBackupFailed, toTitle(err.Error()))
+	return ctrl.Result{}, nil
+}
+
+// buildUploadContainer returns the ephemeral container that uploads the snapshot from the snapshots volume of the
+// leader pod. Ephemeral containers are never restarted, so the snapshot is removed even if the upload failed.
+func (r *TypesenseBackupReconciler) buildUploadContainer(tb *tsv1alpha1.TypesenseBackup, snapshotPath string) corev1.EphemeralContainer {
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:    getUploadContainerName(tb),
+			Image:   tb.GetImage(),
+			Command: []string{"/bin/sh", "-c", backupUploadScript},
+			Env:     append(getBackupEnv(tb), corev1.EnvVar{Name: "SNAPSHOT_DIR", Value: snapshotPath}),
+			EnvFrom: getBackupEnvFrom(tb),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "snapshots",
+					MountPath: typesenseSnapshotsDir,
+				},
+			},
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+	}
+}
+
+// addEphemeralContainer adds the container to the pod, unless it has been added already
+func (r *TypesenseBackupReconciler) addEphemeralContainer(ctx context.Context, pod *corev1.Pod, container corev1.EphemeralContainer) error {
+	if hasEphemeralContainer(pod, container.Name) {
+		return nil
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+	return r.SubResource("ephemeralcontainers").Update(ctx, pod)
+}
+
+func (r *TypesenseBackupReconciler) buildBackupJob(tb *tsv1alpha1.TypesenseBackup, jobName string, script string) *batchv1.Job {
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "typesense-operator",
+		"app.kubernetes.io/name":       "typesense",
+		"app.kubernetes.io/instance":   tb.Spec.Cluster.Name,
+		backupLabelKey:                 tb.Name,
+	}
+
+	var resources corev1.ResourceRequirements
+	if tb.Spec.Resources != nil {
+		resources = *tb.Spec.Resources
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: tb.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:                     "backup",
+							Image:                    tb.GetImage(),
+							Command:                  []string{"/bin/sh", "-c", script},
+							Env:                      getBackupEnv(tb),
+							EnvFrom:                  getBackupEnvFrom(tb),
+							Resources:                resources,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getBackupEnv(tb *tsv1alpha1.TypesenseBackup) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  "HOME",
+			Value: "/tmp",
+		},
+		{
+			Name:  "AWS_DEFAULT_REGION",
+			Value: tb.GetRegion(),
+		},
+		{
+			Name:  "DESTINATION",
+			Value: tb.GetLocation(),
+		},
+	}
+
+	if tb.Spec.Destination.S3.Endpoint != nil {
+		env = append(env, corev1.EnvVar{Name: "S3_ENDPOINT", Value: *tb.Spec.Destination.S3.Endpoint})
+	}
+
+	return env
+}
+
+func getBackupEnvFrom(tb *tsv1alpha1.TypesenseBackup) []corev1.EnvFromSource {
+	return []corev1.EnvFromSource{
+		{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: tb.Spec.Destination.S3.CredentialsSecret,
+			},
+		},
+	}
+}
+
+func (r *TypesenseBackupReconciler) finalize(ctx context.Context, tb *tsv1alpha1.TypesenseBackup) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(tb, backupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if tb.Status.Location != "" {
+		jobName := fmt.Sprintf(BackupCleanupJob, tb.Name)
+		jobExists := true
+
+		var job = &batchv1.Job{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: tb.Namespace, Name: jobName}, job); err != nil {
+			if apierrors.IsNotFound(err) {
+				jobExists = false
+			} else {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if !jobExists {
+			r.logger.Info("deleting backup from object storage", "location", tb.Status.Location)
+
+			job = r.buildBackupJob(tb, jobName, backupCleanupScript)
+			if err := ctrl.SetControllerReference(tb, job, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if err := r.Create(ctx, job); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+		}
+
+		finished, succeeded := isJobFinished(job)
+		if !finished {
+			return ctrl.Result{RequeueAfter: backupRequeuePeriod}, nil
+		}
+
+		if !succeeded {
+			r.Recorder.Eventf(tb, "Warning", "BackupCleanupFailed", "Deleting %s failed, objects must be removed manually", tb.Status.Location)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(tb, backupFinalizer)
+	if err := r.Update(ctx, tb); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func isJobFinished(job *batchv1.Job) (finished bool, succeeded bool) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, true
+		case batchv1.JobFailed:
+			return true, false
+		}
+	}
+
+	return false, false
+}
+
+// getUploadContainerName derives the name of the upload container from the uid of the backup, its name may be too
+// long for a container, and a backup recreated under the same name must not find the container of its predecessor
+func getUploadContainerName(tb *tsv1alpha1.TypesenseBackup) string {
+	uid := strings.ReplaceAll(string(tb.UID), "-", "")
+	return fmt.Sprintf(BackupUploadContainer, uid[:min(len(uid), 12)])
+}
+
+// configureStatefulSetSnapshots adds the volume backup snapshots are written to, next to the data volume so they do
+// not fill it up, only to the pods of clusters that opted in as adding it restarts every node
+func configureStatefulSetSnapshots(sts *appsv1.StatefulSet, ts *tsv1alpha1.TypesenseCluster) {
+	if !ts.Spec.IsSnapshotsVolumeEnabled() {
+		return
+	}
+
+	sizeLimit := ts.Spec.GetSnapshotsSizeLimit()
+	podSpec := &sts.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "snapshots",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+		},
+	})
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name == "typesense" {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{MountPath: typesenseSnapshotsDir, Name: "snapshots"})
+		}
+	}
+}
+
+func hasEphemeralContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// countUploadContainers counts the upload containers left on the pod by every backup taken since it was created
+func countUploadContainers(pod *corev1.Pod) int {
+	prefix := fmt.Sprintf(BackupUploadContainer, "")
+
+	count := 0
+	for _, c := range pod.Spec.EphemeralContainers {
+		if strings.HasPrefix(c.Name, prefix) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func hasSnapshotsVolume(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == "snapshots" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getEphemeralContainerState returns the state of the ephemeral container, it is empty until the container is
+// started, and false if the pod does not have it
+func getEphemeralContainerState(pod *corev1.Pod, name string) (corev1.ContainerState, bool) {
+	found := false
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return corev1.ContainerState{}, false
+	}
+
+	for _, cs := range pod.Status.EphemeralContainerStatuses {
+		if cs.Name == name {
+			return cs.State, true
+		}
+	}
+
+	return corev1.ContainerState{}, true
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseBackup{}, eventFilters).
+		Owns(&batchv1.Job{}).
+		Named("typesensebackup").
+		Complete(r)
+}
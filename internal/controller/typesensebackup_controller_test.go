@@ -0,0 +1,333 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseBackup Controller", func() {
+	Context("When the snapshot is being uploaded", func() {
+		const resourceName = "test-backup"
+		const leaderPodName = "test-backup-sts-0"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		var tb *tsv1alpha1.TypesenseBackup
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			By("creating a backup whose snapshot was taken on the leader")
+			tb = &tsv1alpha1.TypesenseBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: tsv1alpha1.TypesenseBackupSpec{
+					Cluster: corev1.LocalObjectReference{Name: "test-backup"},
+					Destination: tsv1alpha1.BackupDestinationSpec{
+						S3: tsv1alpha1.S3DestinationSpec{
+							Bucket:            "typesense-backups",
+							CredentialsSecret: corev1.LocalObjectReference{Name: "credentials"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tb)).To(Succeed())
+
+			tb.Status = tsv1alpha1.TypesenseBackupStatus{
+				Phase:           tsv1alpha1.BackupUploading,
+				LeaderPod:       leaderPodName,
+				SnapshotPath:    path.Join(typesenseSnapshotsDir, resourceName),
+				UploadContainer: getUploadContainerName(tb),
+				Location:        tb.GetLocation(),
+			}
+			Expect(k8sClient.Status().Update(ctx, tb)).To(Succeed())
+
+			By("creating the leader pod with its snapshots volume")
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: leaderPodName, Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}},
+					Volumes: []corev1.Volume{{
+						Name:         "snapshots",
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tb)).To(Succeed())
+		})
+
+		It("should upload the snapshot from the snapshots volume of the leader pod", func() {
+			controllerReconciler := &TypesenseBackupReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			By("adding the upload container to the leader pod")
+			container := controllerReconciler.buildUploadContainer(tb, tb.Status.SnapshotPath)
+			Expect(container.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "snapshots", MountPath: typesenseSnapshotsDir}))
+			Expect(controllerReconciler.addEphemeralContainer(ctx, pod, container)).To(Succeed())
+			Expect(controllerReconciler.addEphemeralContainer(ctx, pod, container)).To(Succeed())
+			Expect(pod.Spec.EphemeralContainers).To(HaveLen(1))
+
+			By("waiting for the upload")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(backupRequeuePeriod))
+
+			By("completing the backup once the upload container succeeded")
+			pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+				Name:  container.Name,
+				Image: container.Image,
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Message: "2048\n"},
+				},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tb)).To(Succeed())
+			Expect(tb.Status.Phase).To(Equal(tsv1alpha1.BackupCompleted))
+			Expect(tb.Status.SizeBytes).To(Equal(int64(2048)))
+			Expect(tb.Status.Location).To(Equal("s3://typesense-backups/test-backup/test-backup/"))
+		})
+
+		It("should fail the backup when the leader pod was recreated", func() {
+			controllerReconciler := &TypesenseBackupReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tb)).To(Succeed())
+			Expect(tb.Status.Phase).To(Equal(tsv1alpha1.BackupFailed))
+		})
+	})
+
+	Context("When the snapshot is being taken", func() {
+		const resourceName = "test-backup-snapshot"
+		const leaderPodName = "test-backup-snapshot-sts-0"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		var tb *tsv1alpha1.TypesenseBackup
+		var pod *corev1.Pod
+		var controllerReconciler *TypesenseBackupReconciler
+		var snapshot *pendingSnapshot
+
+		BeforeEach(func() {
+			By("creating a backup whose snapshot is being taken on the leader")
+			tb = &tsv1alpha1.TypesenseBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: tsv1alpha1.TypesenseBackupSpec{
+					Cluster: corev1.LocalObjectReference{Name: "test-backup-snapshot"},
+					Destination: tsv1alpha1.BackupDestinationSpec{
+						S3: tsv1alpha1.S3DestinationSpec{
+							Bucket:            "typesense-backups",
+							CredentialsSecret: corev1.LocalObjectReference{Name: "credentials"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tb)).To(Succeed())
+
+			tb.Status = tsv1alpha1.TypesenseBackupStatus{
+				Phase:        tsv1alpha1.BackupSnapshotting,
+				LeaderPod:    leaderPodName,
+				SnapshotPath: path.Join(typesenseSnapshotsDir, resourceName),
+			}
+			Expect(k8sClient.Status().Update(ctx, tb)).To(Succeed())
+
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: leaderPodName, Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}},
+					Volumes: []corev1.Volume{{
+						Name:         "snapshots",
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+
+			controllerReconciler = &TypesenseBackupReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			snapshot = &pendingSnapshot{done: make(chan struct{})}
+			controllerReconciler.snapshots.Store(tb.UID, snapshot)
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tb)).To(Succeed())
+		})
+
+		It("should wait for the snapshot and upload it once it is written", func() {
+			By("requeuing while the leader is writing the snapshot")
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(backupRequeuePeriod))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tb)).To(Succeed())
+			Expect(tb.Status.Phase).To(Equal(tsv1alpha1.BackupSnapshotting))
+
+			By("adding the upload container once the snapshot is written")
+			close(snapshot.done)
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tb)).To(Succeed())
+			Expect(tb.Status.Phase).To(Equal(tsv1alpha1.BackupUploading))
+			Expect(tb.Status.UploadContainer).To(Equal(getUploadContainerName(tb)))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: leaderPodName, Namespace: "default"}, pod)).To(Succeed())
+			Expect(hasEphemeralContainer(pod, tb.Status.UploadContainer)).To(BeTrue())
+
+			_, tracked := controllerReconciler.snapshots.Load(tb.UID)
+			Expect(tracked).To(BeFalse())
+		})
+
+		It("should fail the backup when the snapshot failed", func() {
+			snapshot.err = errors.New("snapshot failed")
+			close(snapshot.done)
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tb)).To(Succeed())
+			Expect(tb.Status.Phase).To(Equal(tsv1alpha1.BackupFailed))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: leaderPodName, Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Spec.EphemeralContainers).To(BeEmpty())
+		})
+	})
+
+	Context("When upload containers are left on the leader pod", func() {
+		It("should count the upload containers only", func() {
+			pod := &corev1.Pod{}
+			for i := range maxUploadContainers {
+				pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: fmt.Sprintf(BackupUploadContainer, fmt.Sprint(i))},
+				})
+			}
+			pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"},
+			})
+
+			Expect(countUploadContainers(pod)).To(Equal(maxUploadContainers))
+			Expect(hasEphemeralContainer(pod, "debugger")).To(BeTrue())
+		})
+	})
+
+	Context("When the statefulset of a cluster is built", func() {
+		newStatefulSet := func() *appsv1.StatefulSet {
+			return &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "typesense"}, {Name: "metrics-exporter"}},
+						},
+					},
+				},
+			}
+		}
+
+		It("should leave the snapshots volume out of clusters that are not backed up", func() {
+			sts := newStatefulSet()
+			configureStatefulSetSnapshots(sts, &tsv1alpha1.TypesenseCluster{})
+
+			Expect(sts.Spec.Template.Spec.Volumes).To(BeEmpty())
+			Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+		})
+
+		It("should limit the snapshots volume to the size of the data volume by default", func() {
+			sts := newStatefulSet()
+			ts := &tsv1alpha1.TypesenseCluster{
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Storage:   &tsv1alpha1.StorageSpec{Size: resource.MustParse("2Gi")},
+					Snapshots: &tsv1alpha1.SnapshotsSpec{},
+				},
+			}
+			configureStatefulSetSnapshots(sts, ts)
+
+			Expect(sts.Spec.Template.Spec.Volumes).To(HaveLen(1))
+			Expect(sts.Spec.Template.Spec.Volumes[0].EmptyDir.SizeLimit.String()).To(Equal("2Gi"))
+			Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "snapshots", MountPath: typesenseSnapshotsDir}))
+			Expect(sts.Spec.Template.Spec.Containers[1].VolumeMounts).To(BeEmpty())
+		})
+
+		It("should add the snapshots volume for a final snapshot with the given size limit", func() {
+			sts := newStatefulSet()
+			ts := &tsv1alpha1.TypesenseCluster{
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Snapshots:     &tsv1alpha1.SnapshotsSpec{SizeLimit: ptr.To(resource.MustParse("5Gi"))},
+					FinalSnapshot: &tsv1alpha1.FinalSnapshotSpec{},
+				},
+			}
+			configureStatefulSetSnapshots(sts, ts)
+
+			Expect(sts.Spec.Template.Spec.Volumes).To(HaveLen(1))
+			Expect(sts.Spec.Template.Spec.Volumes[0].EmptyDir.SizeLimit.String()).To(Equal("5Gi"))
+		})
+	})
+})
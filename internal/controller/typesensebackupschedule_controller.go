@@ -0,0 +1,259 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const backupScheduleLabelKey = "ts.opentelekomcloud.com/backup-schedule"
+
+// TypesenseBackupScheduleReconciler reconciles a TypesenseBackupSchedule object
+type TypesenseBackupScheduleReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackupschedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackupschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackupschedules/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates a TypesenseBackup from the template on every activation of the cron schedule and prunes the
+// backups it owns according to the retention policy.
+func (r *TypesenseBackupScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "backupschedule", req.Name)
+
+	var tbs tsv1alpha1.TypesenseBackupSchedule
+	if err := r.Get(ctx, req.NamespacedName, &tbs); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	schedule, err := cron.ParseStandard(tbs.Spec.Schedule)
+	if err != nil {
+		r.logger.Error(err, "parsing schedule failed", "schedule", tbs.Spec.Schedule)
+		cerr := r.setCondition(ctx, &tbs, metav1.ConditionFalse, ConditionReasonScheduleInvalid, err.Error())
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	var backups tsv1alpha1.TypesenseBackupList
+	if err := r.List(ctx, &backups, client.InNamespace(tbs.Namespace), client.MatchingLabels{backupScheduleLabelKey: tbs.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Retention strategy: prune completed backups beyond keepLast or older than maxAge
+	retained, inProgress, err := r.ReconcileRetention(ctx, &tbs, backups.Items)
+	if err != nil {
+		r.logger.Error(err, "pruning backups failed")
+		return ctrl.Result{}, err
+	}
+
+	records := make([]tsv1alpha1.BackupRecord, 0, len(retained))
+	for _, tb := range retained {
+		records = append(records, tsv1alpha1.BackupRecord{
+			Name:           tb.Name,
+			CompletedAt:    tb.Status.CompletedAt,
+			SizeBytes:      tb.Status.SizeBytes,
+			CommittedIndex: tb.Status.CommittedIndex,
+			Location:       tb.Status.Location,
+		})
+	}
+
+	if tbs.Spec.Suspend {
+		err := r.patchStatus(ctx, &tbs, func(status *tsv1alpha1.TypesenseBackupScheduleStatus) {
+			status.Backups = records
+			status.NextScheduleTime = nil
+		})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, r.setCondition(ctx, &tbs, metav1.ConditionFalse, ConditionReasonScheduleSuspended, "Schedule is suspended")
+	}
+
+	now := time.Now()
+	lastScheduleTime := tbs.CreationTimestamp.Time
+	if tbs.Status.LastScheduleTime != nil {
+		lastScheduleTime = tbs.Status.LastScheduleTime.Time
+	}
+
+	var lastBackup = tbs.Status.LastBackup
+	nextScheduleTime := schedule.Next(lastScheduleTime)
+	if !nextScheduleTime.IsZero() && !now.Before(nextScheduleTime) {
+		lastScheduleTime = now
+		nextScheduleTime = schedule.Next(now)
+
+		if inProgress != nil {
+			r.logger.Info("skipping scheduled backup, previous backup still in progress", "backup", inProgress.Name)
+			r.Recorder.Eventf(&tbs, "Warning", "BackupSkipped", "Skipped scheduled backup, %s is still in progress", inProgress.Name)
+		} else {
+			tb, err := r.createBackup(ctx, &tbs, now)
+			if err != nil {
+				r.logger.Error(err, "creating scheduled backup failed")
+				return ctrl.Result{}, err
+			}
+
+			lastBackup = tb.Name
+			r.Recorder.Eventf(&tbs, "Normal", "BackupCreated", "Created scheduled backup %s", tb.Name)
+		}
+	}
+
+	err = r.patchStatus(ctx, &tbs, func(status *tsv1alpha1.TypesenseBackupScheduleStatus) {
+		status.Backups = records
+		status.LastBackup = lastBackup
+		if !lastScheduleTime.Equal(tbs.CreationTimestamp.Time) {
+			status.LastScheduleTime = &metav1.Time{Time: lastScheduleTime}
+		}
+		status.NextScheduleTime = nil
+		if !nextScheduleTime.IsZero() {
+			status.NextScheduleTime = &metav1.Time{Time: nextScheduleTime}
+		}
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.setCondition(ctx, &tbs, metav1.ConditionTrue, ConditionReasonScheduleReady, fmt.Sprintf("Next backup at %s", nextScheduleTime.Format(time.RFC3339)))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if nextScheduleTime.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: nextScheduleTime.Sub(now)}, nil
+}
+
+// ReconcileRetention deletes the backups that fall out of the retention policy, failed backups are pruned as soon as
+// a newer backup completes. It returns the retained completed backups newest first, and the backup in progress if any.
+func (r *TypesenseBackupScheduleReconciler) ReconcileRetention(
+	ctx context.Context,
+	tbs *tsv1alpha1.TypesenseBackupSchedule,
+	backups []tsv1alpha1.TypesenseBackup,
+) ([]tsv1alpha1.TypesenseBackup, *tsv1alpha1.TypesenseBackup, error) {
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[j].CreationTimestamp.Before(&backups[i].CreationTimestamp)
+	})
+
+	var keepLast = -1
+	var maxAge time.Duration
+	if tbs.Spec.Retention != nil {
+		if tbs.Spec.Retention.KeepLast != nil {
+			keepLast = int(*tbs.Spec.Retention.KeepLast)
+		}
+		if tbs.Spec.Retention.MaxAge != nil {
+			maxAge = tbs.Spec.Retention.MaxAge.Duration
+		}
+	}
+
+	var retained []tsv1alpha1.TypesenseBackup
+	var inProgress *tsv1alpha1.TypesenseBackup
+	var completed bool
+
+	for i := range backups {
+		tb := &backups[i]
+		if !tb.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		var prune bool
+		switch tb.Status.Phase {
+		case tsv1alpha1.BackupCompleted:
+			expired := maxAge > 0 && tb.Status.CompletedAt != nil && time.Since(tb.Status.CompletedAt.Time) > maxAge
+			prune = (keepLast >= 0 && len(retained) >= keepLast) || expired
+			if !prune {
+				retained = append(retained, *tb)
+			}
+			completed = true
+		case tsv1alpha1.BackupFailed:
+			prune = completed
+		default:
+			if inProgress == nil {
+				inProgress = tb
+			}
+		}
+
+		if prune {
+			r.logger.Info("pruning backup", "backup", tb.Name, "phase", tb.Status.Phase)
+			if err := r.Delete(ctx, tb); err != nil && !apierrors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			r.Recorder.Eventf(tbs, "Normal", "BackupPruned", "Pruned backup %s", tb.Name)
+		}
+	}
+
+	return retained, inProgress, nil
+}
+
+func (r *TypesenseBackupScheduleReconciler) createBackup(ctx context.Context, tbs *tsv1alpha1.TypesenseBackupSchedule, now time.Time) (*tsv1alpha1.TypesenseBackup, error) {
+	tb := &tsv1alpha1.TypesenseBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(ScheduledBackup, tbs.Name, now.Unix()),
+			Namespace: tbs.Namespace,
+			Labels: map[string]string{
+				backupScheduleLabelKey: tbs.Name,
+			},
+		},
+		Spec: *tbs.Spec.Template.DeepCopy(),
+	}
+
+	err := ctrl.SetControllerReference(tbs, tb, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Create(ctx, tb); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	return tb, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseBackupScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseBackupSchedule{}, eventFilters).
+		Owns(&tsv1alpha1.TypesenseBackup{}).
+		Named("typesensebackupschedule").
+		Complete(r)
+}
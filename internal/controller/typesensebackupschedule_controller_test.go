@@ -0,0 +1,158 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseBackupSchedule Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-backupschedule"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		typesensebackupschedule := &tsv1alpha1.TypesenseBackupSchedule{}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind TypesenseBackupSchedule")
+			err := k8sClient.Get(ctx, typeNamespacedName, typesensebackupschedule)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &tsv1alpha1.TypesenseBackupSchedule{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: tsv1alpha1.TypesenseBackupScheduleSpec{
+						Schedule: "0 3 * * *",
+						Template: tsv1alpha1.TypesenseBackupSpec{
+							Cluster: corev1.LocalObjectReference{Name: "missing-cluster"},
+							Destination: tsv1alpha1.BackupDestinationSpec{
+								S3: tsv1alpha1.S3DestinationSpec{
+									Bucket:            "typesense-backups",
+									CredentialsSecret: corev1.LocalObjectReference{Name: "credentials"},
+								},
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &tsv1alpha1.TypesenseBackupSchedule{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance TypesenseBackupSchedule")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should schedule the next backup", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &TypesenseBackupScheduleReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, typesensebackupschedule)).To(Succeed())
+			Expect(typesensebackupschedule.Status.NextScheduleTime).NotTo(BeNil())
+			Expect(typesensebackupschedule.Status.NextScheduleTime.UTC().Hour()).To(Equal(3))
+			Expect(typesensebackupschedule.Status.NextScheduleTime.Minute()).To(BeZero())
+			Expect(meta.IsStatusConditionTrue(typesensebackupschedule.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+		})
+	})
+
+	Context("When the schedule is not a valid cron expression", func() {
+		const resourceName = "test-backupschedule-invalid"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &tsv1alpha1.TypesenseBackupSchedule{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}})).To(Succeed())
+		})
+
+		It("should report the schedule as invalid", func() {
+			resource := &tsv1alpha1.TypesenseBackupSchedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: tsv1alpha1.TypesenseBackupScheduleSpec{
+					Schedule: "0 3 * *",
+					Template: tsv1alpha1.TypesenseBackupSpec{
+						Cluster: corev1.LocalObjectReference{Name: "missing-cluster"},
+						Destination: tsv1alpha1.BackupDestinationSpec{
+							S3: tsv1alpha1.S3DestinationSpec{
+								Bucket:            "typesense-backups",
+								CredentialsSecret: corev1.LocalObjectReference{Name: "credentials"},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &TypesenseBackupScheduleReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			condition := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(ConditionReasonScheduleInvalid))
+			Expect(resource.Status.NextScheduleTime).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	typesenseApiRequestTimeout = 30 * time.Second
+)
+
+var errClusterNotReady = errors.New("typesense cluster is not ready")
+
+// typesenseApiError is returned by typesenseApiClient when Typesense answers with a non 2xx status code
+type typesenseApiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *typesenseApiError) Error() string {
+	return fmt.Sprintf("typesense api returned %d: %s", e.StatusCode, e.Message)
+}
+
+func isTypesenseApiNotFound(err error) bool {
+	var apiErr *typesenseApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// typesenseApiClient talks to the Typesense HTTP API of a single node of a TypesenseCluster, authenticating with the
+// cluster admin api key. It reuses the same plumbing the quorum probes use, so it works both in-cluster and
+// out-of-cluster via the pods proxy.
+type typesenseApiClient struct {
+	httpClient *http.Client
+	clientSet  *kubernetes.Clientset
+	inCluster  bool
+	ts         *tsv1alpha1.TypesenseCluster
+	node       NodeEndpoint
+	apiKey     string
+}
+
+func newTypesenseApiClient(
+	ctx context.Context,
+	c client.Client,
+	config *rest.Config,
+	clientSet *kubernetes.Clientset,
+	inCluster bool,
+	ts *tsv1alpha1.TypesenseCluster,
+) (*typesenseApiClient, error) {
+	var secret = &corev1.Secret{}
+	if err := c.Get(ctx, getAdminApiKeyObjectKey(ts), secret); err != nil {
+		return nil, err
+	}
+
+	apiKey, ok := secret.Data[ClusterAdminApiKeySecretKeyName]
+	if !ok || len(apiKey) == 0 {
+		return nil, fmt.Errorf("secret %s is missing '%s' key", secret.Name, ClusterAdminApiKeySecretKeyName)
+	}
+
+	nodes, err := getReadyNodeEndpoints(ctx, c, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no ready nodes found for cluster %s", ts.Name)
+	}
+
+	tlsConfig, err := newTLSClientConfig(ctx, c, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHttpClient(config, inCluster, typesenseApiRequestTimeout, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &typesenseApiClient{
+		httpClient: httpClient,
+		clientSet:  clientSet,
+		inCluster:  inCluster,
+		ts:         ts,
+		node:       nodes[0],
+		apiKey:     string(apiKey),
+	}, nil
+}
+
+// newTypesenseApiClientForCluster fetches the referenced TypesenseCluster and, if its quorum is ready, returns an api
+// client for it. It returns errClusterNotReady when the cluster exists but is not (yet) serving requests.
+func newTypesenseApiClientForCluster(
+	ctx context.Context,
+	c client.Client,
+	config *rest.Config,
+	clientSet *kubernetes.Clientset,
+	inCluster bool,
+	key client.ObjectKey,
+) (*tsv1alpha1.TypesenseCluster, *typesenseApiClient, error) {
+	var ts = &tsv1alpha1.TypesenseCluster{}
+	if err := c.Get(ctx, key, ts); err != nil {
+		return nil, nil, err
+	}
+
+	if !meta.IsStatusConditionTrue(ts.Status.Conditions, ConditionTypeReady) {
+		return ts, nil, errClusterNotReady
+	}
+
+	api, err := newTypesenseApiClient(ctx, c, config, clientSet, inCluster, ts)
+	if err != nil {
+		return ts, nil, err
+	}
+
+	return ts, api, nil
+}
+
+// withNode returns a copy of the client that targets the given node
+func (c *typesenseApiClient) withNode(node NodeEndpoint) *typesenseApiClient {
+	nc := *c
+	nc.node = node
+	return &nc
+}
+
+// withApiKey returns a copy of the client that authenticates with the given api key
+func (c *typesenseApiClient) withApiKey(apiKey string) *typesenseApiClient {
+	nc := *c
+	nc.apiKey = apiKey
+	return &nc
+}
+
+// withTimeout returns a copy of the client whose requests time out after the given duration
+func (c *typesenseApiClient) withTimeout(timeout time.Duration) *typesenseApiClient {
+	nc := *c
+	hc := *c.httpClient
+	hc.Timeout = timeout
+	nc.httpClient = &hc
+	return &nc
+}
+
+func (c *typesenseApiClient) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	u, err := buildNodeUrl(c.clientSet, c.inCluster, c.node, c.ts, c.ts.Spec.ApiPort, path)
+	if err != nil {
+		return err
+	}
+
+	if len(query) > 0 {
+		pu, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		pu.RawQuery = query.Encode()
+		u = pu.String()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("x-typesense-api-key", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if jerr := json.Unmarshal(raw, &msg); jerr != nil || msg.Message == "" {
+			msg.Message = string(raw)
+		}
+		return &typesenseApiError{StatusCode: resp.StatusCode, Message: msg.Message}
+	}
+
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(raw, out)
+}
+
+// getLeaderNode asks every ready node for its raft state and returns the current leader along with its status
+func getLeaderNode(ctx context.Context, c client.Client, api *typesenseApiClient) (NodeEndpoint, NodeStatus, error) {
+	nodes, err := getReadyNodeEndpoints(ctx, c, api.ts)
+	if err != nil {
+		return NodeEndpoint{}, NodeStatus{}, err
+	}
+
+	for _, node := range nodes {
+		var nodeStatus NodeStatus
+		if err := api.withNode(node).do(ctx, http.MethodGet, "/status", nil, nil, &nodeStatus); err != nil {
+			continue
+		}
+
+		if nodeStatus.State == LeaderState {
+			return node, nodeStatus, nil
+		}
+	}
+
+	return NodeEndpoint{}, NodeStatus{}, fmt.Errorf("no leader found for cluster %s", api.ts.Name)
+}
+
+// getReadyNodeEndpoints returns the endpoints of the cluster pods that are running and ready, sorted by pod name.
+// Pods whose quorum readiness gate is already true are returned first.
+func getReadyNodeEndpoints(ctx context.Context, c client.Client, ts *tsv1alpha1.TypesenseCluster) ([]NodeEndpoint, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	var quorumReady, ready []NodeEndpoint
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		ne := NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(pod.Status.PodIP)}
+		switch {
+		case isPodConditionTrue(&pod, QuorumReadinessGateCondition):
+			quorumReady = append(quorumReady, ne)
+		case isPodConditionTrue(&pod, corev1.ContainersReady):
+			ready = append(ready, ne)
+		}
+	}
+
+	return append(quorumReady, ready...), nil
+}
+
+func isPodConditionTrue(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
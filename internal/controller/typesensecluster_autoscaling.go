@@ -0,0 +1,330 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// autoscalingTolerance keeps the replicas from flapping around the targets
+	autoscalingTolerance = 0.1
+	minAllowedReplicas   = 1
+	maxAllowedReplicas   = 7
+)
+
+var podMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// autoscalingMetric is the observed value of a target, averaged over the nodes
+type autoscalingMetric struct {
+	name    string
+	current float64
+	target  float64
+	// scaleUp is false for the metrics that can only hold off a scale down
+	scaleUp bool
+}
+
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+// ReconcileScaleStatus reports the replicas and the pod selector of the StatefulSet to the scale subresource
+func (r *TypesenseClusterReconciler) ReconcileScaleStatus(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, sts *appsv1.StatefulSet) error {
+	selector := labels.SelectorFromSet(getLabels(ts)).String()
+	if ts.Status.Replicas == sts.Status.Replicas && ts.Status.Selector == selector {
+		return nil
+	}
+
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Replicas = sts.Status.Replicas
+		status.Selector = selector
+	})
+}
+
+// ReconcileAutoscaling recommends the replicas from the observed metrics and, once the cooldown elapsed, patches
+// spec.replicas one odd size at a time, so the scaling goes through ReconcileStatefulSet as if it was edited by hand.
+// Nothing is scaled while the quorum is not ready or a rolling update is in progress.
+func (r *TypesenseClusterReconciler) ReconcileAutoscaling(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, condition ConditionQuorum) error {
+	if !ts.Spec.IsAutoscalingEnabled() {
+		if ts.Status.Autoscaling == nil {
+			return nil
+		}
+
+		return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Autoscaling = nil
+		})
+	}
+
+	r.logger.V(debugLevel).Info("reconciling autoscaling")
+
+	spec := ts.Spec.Autoscaling
+	current := ts.Spec.Replicas
+
+	autoscaling := &tsv1alpha1.AutoscalingStatus{DesiredReplicas: current}
+	if ts.Status.Autoscaling != nil {
+		autoscaling.LastScaleTime = ts.Status.Autoscaling.LastScaleTime
+	}
+
+	if condition != ConditionReasonQuorumReady || ts.Status.IsRollingUpdate() {
+		autoscaling.Message = fmt.Sprintf("scaling is suspended while the quorum is %s", condition)
+		if ts.Status.IsRollingUpdate() {
+			autoscaling.Message = "scaling is suspended while a rolling update is in progress"
+		}
+
+		return r.updateAutoscalingStatus(ctx, ts, autoscaling)
+	}
+
+	metrics, missing := r.getAutoscalingMetrics(ctx, ts, autoscaling)
+	desired, reason := getAutoscalingRecommendation(current, spec, metrics, missing)
+	autoscaling.DesiredReplicas = desired
+	autoscaling.Message = reason
+
+	if desired == current {
+		return r.updateAutoscalingStatus(ctx, ts, autoscaling)
+	}
+
+	cooldown := time.Duration(spec.ScaleUpCooldownSeconds) * time.Second
+	if desired < current {
+		cooldown = time.Duration(spec.ScaleDownCooldownSeconds) * time.Second
+	}
+
+	if autoscaling.LastScaleTime != nil {
+		if until := autoscaling.LastScaleTime.Add(cooldown); time.Now().Before(until) {
+			autoscaling.Message = fmt.Sprintf("scaling from %d to %d replicas is held off by the cooldown until %s: %s", current, desired, until.Format(time.RFC3339), reason)
+			return r.updateAutoscalingStatus(ctx, ts, autoscaling)
+		}
+	}
+
+	r.logger.Info("autoscaling cluster", "from", current, "to", desired, "reason", reason)
+
+	patch := client.MergeFrom(ts.DeepCopy())
+	ts.Spec.Replicas = desired
+	if err := r.Patch(ctx, ts, patch); err != nil {
+		r.logger.Error(err, "patching replicas failed", "replicas", desired)
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "Autoscaled", "Scaling from %d to %d replicas: %s", current, desired, reason)
+
+	now := metav1.Now()
+	autoscaling.LastScaleTime = &now
+	return r.updateAutoscalingStatus(ctx, ts, autoscaling)
+}
+
+func (r *TypesenseClusterReconciler) updateAutoscalingStatus(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, autoscaling *tsv1alpha1.AutoscalingStatus) error {
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Autoscaling = autoscaling
+	})
+}
+
+// getAutoscalingMetrics collects the metrics the targets are set for and records them in the status. It returns the
+// names of the targets that could not be observed, as long as any is missing the cluster is not scaled down.
+func (r *TypesenseClusterReconciler) getAutoscalingMetrics(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, autoscaling *tsv1alpha1.AutoscalingStatus) ([]autoscalingMetric, []string) {
+	spec := ts.Spec.Autoscaling
+	metrics := make([]autoscalingMetric, 0)
+	missing := make([]string, 0)
+
+	if spec.TargetCPUUtilizationPercentage != nil || spec.TargetMemoryUtilizationPercentage != nil {
+		cpu, memory, err := r.getTypesenseContainerUtilization(ctx, ts)
+		if err != nil {
+			r.logger.V(debugLevel).Info("unable to fetch pod metrics", "reason", err.Error())
+		}
+
+		if spec.TargetCPUUtilizationPercentage != nil {
+			if cpu != nil {
+				autoscaling.CurrentCPUUtilizationPercentage = ptr.To(int32(math.Round(*cpu)))
+				metrics = append(metrics, autoscalingMetric{name: "cpu utilization", current: *cpu, target: float64(*spec.TargetCPUUtilizationPercentage), scaleUp: true})
+			} else {
+				missing = append(missing, "cpu utilization")
+			}
+		}
+
+		if spec.TargetMemoryUtilizationPercentage != nil {
+			if memory != nil {
+				autoscaling.CurrentMemoryUtilizationPercentage = ptr.To(int32(math.Round(*memory)))
+				metrics = append(metrics, autoscalingMetric{name: "memory utilization", current: *memory, target: float64(*spec.TargetMemoryUtilizationPercentage), scaleUp: true})
+			} else {
+				missing = append(missing, "memory utilization")
+			}
+		}
+	}
+
+	if spec.TargetQueuedWrites != nil {
+		if len(ts.Status.Nodes) > 0 {
+			queuedWrites := int64(0)
+			for _, node := range ts.Status.Nodes {
+				queuedWrites = max(queuedWrites, node.QueuedWrites)
+			}
+
+			autoscaling.CurrentQueuedWrites = ptr.To(queuedWrites)
+			metrics = append(metrics, autoscalingMetric{name: "queued writes", current: float64(queuedWrites), target: float64(*spec.TargetQueuedWrites)})
+		} else {
+			missing = append(missing, "queued writes")
+		}
+	}
+
+	if spec.TargetSearchLatencyMilliseconds != nil {
+		latency, err := r.getSearchLatency(ctx, ts)
+		if err != nil {
+			r.logger.V(debugLevel).Info("unable to fetch search latency", "reason", err.Error())
+		}
+
+		if latency != nil {
+			autoscaling.CurrentSearchLatencyMilliseconds = ptr.To(int64(math.Round(*latency)))
+			metrics = append(metrics, autoscalingMetric{name: "search latency", current: *latency, target: float64(*spec.TargetSearchLatencyMilliseconds), scaleUp: true})
+		} else {
+			missing = append(missing, "search latency")
+		}
+	}
+
+	return metrics, missing
+}
+
+// getAutoscalingRecommendation grows the cluster by one size as soon as a target is exceeded and shrinks it by one
+// size only when every target would still be met, assuming the load spreads evenly over the remaining nodes
+func getAutoscalingRecommendation(current int32, spec *tsv1alpha1.AutoscalingSpec, metrics []autoscalingMetric, missing []string) (int32, string) {
+	if current < spec.MinReplicas {
+		return nextReplicas(current), fmt.Sprintf("replicas are below minReplicas %d", spec.MinReplicas)
+	}
+
+	if current > spec.MaxReplicas {
+		return previousReplicas(current), fmt.Sprintf("replicas are above maxReplicas %d", spec.MaxReplicas)
+	}
+
+	for _, metric := range metrics {
+		if metric.scaleUp && metric.current > metric.target*(1+autoscalingTolerance) {
+			reason := fmt.Sprintf("%s %.0f is above its target %.0f", metric.name, metric.current, metric.target)
+			if current >= spec.MaxReplicas {
+				return current, reason + ", but maxReplicas is reached"
+			}
+
+			return nextReplicas(current), reason
+		}
+	}
+
+	if len(missing) > 0 {
+		return current, fmt.Sprintf("waiting for metrics: %s", strings.Join(missing, ", "))
+	}
+
+	if current <= spec.MinReplicas || len(metrics) == 0 {
+		return current, "metrics are within their targets"
+	}
+
+	smaller := previousReplicas(current)
+	for _, metric := range metrics {
+		projected := metric.current
+		if metric.scaleUp {
+			projected = metric.current * float64(current) / float64(smaller)
+		}
+
+		if projected > metric.target*(1-autoscalingTolerance) {
+			return current, "metrics are within their targets"
+		}
+	}
+
+	return smaller, fmt.Sprintf("metrics would stay within their targets with %d replicas", smaller)
+}
+
+// getTypesenseContainerUtilization returns the cpu and memory usage of the typesense containers, as a percentage of
+// their requests, from the metrics API. It is read as unstructured, so the operator does not depend on metrics-server.
+func (r *TypesenseClusterReconciler) getTypesenseContainerUtilization(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*float64, *float64, error) {
+	var podMetrics = &unstructured.UnstructuredList{}
+	podMetrics.SetGroupVersionKind(podMetricsListGVK)
+	if err := r.List(ctx, podMetrics, client.InNamespace(ts.Namespace), client.MatchingLabels(getLabels(ts))); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil, fmt.Errorf("metrics api is not available: %w", err)
+		}
+		return nil, nil, err
+	}
+
+	var cpuUsage, memoryUsage int64
+	pods := int64(0)
+	for _, item := range podMetrics.Items {
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok || container["name"] != "typesense" {
+				continue
+			}
+
+			usage, _, _ := unstructured.NestedStringMap(container, "usage")
+			if cpu, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+				cpuUsage += cpu.MilliValue()
+			}
+			if memory, err := resource.ParseQuantity(usage["memory"]); err == nil {
+				memoryUsage += memory.Value()
+			}
+			pods++
+		}
+	}
+
+	if pods == 0 {
+		return nil, nil, fmt.Errorf("no pod metrics found")
+	}
+
+	requests := ts.Spec.GetResources().Requests
+
+	var cpu, memory *float64
+	if request := requests.Cpu(); request != nil && request.MilliValue() > 0 {
+		cpu = ptr.To(float64(cpuUsage) / float64(pods*request.MilliValue()) * 100)
+	}
+	if request := requests.Memory(); request != nil && request.Value() > 0 {
+		memory = ptr.To(float64(memoryUsage) / float64(pods*request.Value()) * 100)
+	}
+
+	return cpu, memory, nil
+}
+
+// getSearchLatency averages the search latency reported by the /stats.json endpoint of the ready nodes
+func (r *TypesenseClusterReconciler) getSearchLatency(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*float64, error) {
+	api, err := newTypesenseApiClient(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := getReadyNodeEndpoints(ctx, r.Client, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	total, count := 0.0, 0
+	for _, node := range nodes {
+		var stats struct {
+			SearchLatencyMs float64 `json:"search_latency_ms"`
+		}
+
+		if err := api.withNode(node).do(ctx, http.MethodGet, "/stats.json", nil, nil, &stats); err != nil {
+			r.logger.V(debugLevel).Info("unable to fetch stats", "pod", node.PodName, "reason", err.Error())
+			continue
+		}
+
+		total += stats.SearchLatencyMs
+		count++
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("no stats reported by the nodes")
+	}
+
+	return ptr.To(total / float64(count)), nil
+}
+
+func nextReplicas(current int32) int32 {
+	return min(current+1+current%2, maxAllowedReplicas)
+}
+
+func previousReplicas(current int32) int32 {
+	return max(current-1-current%2, minAllowedReplicas)
+}
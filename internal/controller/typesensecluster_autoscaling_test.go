@@ -0,0 +1,233 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Autoscaling", func() {
+	ctx := context.Background()
+
+	newSpec := func(minReplicas, maxReplicas int32) *tsv1alpha1.AutoscalingSpec {
+		return &tsv1alpha1.AutoscalingSpec{
+			MinReplicas: minReplicas,
+			MaxReplicas: maxReplicas,
+		}
+	}
+
+	cpu := func(current float64) autoscalingMetric {
+		return autoscalingMetric{name: "cpu utilization", current: current, target: 70, scaleUp: true}
+	}
+
+	queuedWrites := func(current float64) autoscalingMetric {
+		return autoscalingMetric{name: "queued writes", current: current, target: 100}
+	}
+
+	DescribeTable("recommending the replicas",
+		func(current int32, spec *tsv1alpha1.AutoscalingSpec, metrics []autoscalingMetric, missing []string, expected int32, reason string) {
+			desired, actual := getAutoscalingRecommendation(current, spec, metrics, missing)
+			Expect(desired).To(Equal(expected))
+			Expect(actual).To(Equal(reason))
+		},
+		Entry("grows the cluster up to minReplicas",
+			int32(1), newSpec(3, 7), nil, nil,
+			int32(3), "replicas are below minReplicas 3"),
+		Entry("shrinks the cluster down to maxReplicas",
+			int32(7), newSpec(1, 5), nil, nil,
+			int32(5), "replicas are above maxReplicas 5"),
+		Entry("grows the cluster by one odd size when a target is exceeded",
+			int32(3), newSpec(1, 7), []autoscalingMetric{cpu(90)}, nil,
+			int32(5), "cpu utilization 90 is above its target 70"),
+		Entry("does not grow the cluster within the tolerance",
+			int32(3), newSpec(1, 7), []autoscalingMetric{cpu(75)}, nil,
+			int32(3), "metrics are within their targets"),
+		Entry("does not grow the cluster beyond maxReplicas",
+			int32(5), newSpec(1, 5), []autoscalingMetric{cpu(90)}, nil,
+			int32(5), "cpu utilization 90 is above its target 70, but maxReplicas is reached"),
+		Entry("does not grow the cluster on queued writes",
+			int32(3), newSpec(1, 7), []autoscalingMetric{queuedWrites(500)}, nil,
+			int32(3), "metrics are within their targets"),
+		Entry("shrinks the cluster by one odd size when the targets would still be met",
+			int32(5), newSpec(1, 7), []autoscalingMetric{cpu(20), queuedWrites(10)}, nil,
+			int32(3), "metrics would stay within their targets with 3 replicas"),
+		Entry("does not shrink the cluster when the load of the smaller cluster would exceed a target",
+			int32(5), newSpec(1, 7), []autoscalingMetric{cpu(50)}, nil,
+			int32(5), "metrics are within their targets"),
+		Entry("does not shrink the cluster when queued writes are close to their target",
+			int32(5), newSpec(1, 7), []autoscalingMetric{cpu(20), queuedWrites(95)}, nil,
+			int32(5), "metrics are within their targets"),
+		Entry("does not shrink the cluster below minReplicas",
+			int32(3), newSpec(3, 7), []autoscalingMetric{cpu(10)}, nil,
+			int32(3), "metrics are within their targets"),
+		Entry("does not shrink the cluster while metrics are missing",
+			int32(5), newSpec(1, 7), []autoscalingMetric{cpu(10)}, []string{"search latency"},
+			int32(5), "waiting for metrics: search latency"),
+		Entry("grows the cluster even while metrics are missing",
+			int32(3), newSpec(1, 7), []autoscalingMetric{cpu(90)}, []string{"search latency"},
+			int32(5), "cpu utilization 90 is above its target 70"),
+		Entry("does not shrink the cluster without metrics",
+			int32(5), newSpec(1, 7), nil, nil,
+			int32(5), "metrics are within their targets"),
+	)
+
+	DescribeTable("stepping the replicas",
+		func(current int32, next int32, previous int32) {
+			Expect(nextReplicas(current)).To(Equal(next))
+			Expect(previousReplicas(current)).To(Equal(previous))
+		},
+		Entry("from a single node", int32(1), int32(3), int32(1)),
+		Entry("from 3 nodes", int32(3), int32(5), int32(1)),
+		Entry("from 5 nodes", int32(5), int32(7), int32(3)),
+		Entry("from 7 nodes", int32(7), int32(7), int32(5)),
+		Entry("from an even size", int32(4), int32(5), int32(3)),
+	)
+
+	Context("When the cluster is autoscaled", func() {
+		const resourceName = "test-autoscaling"
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseClusterReconciler
+
+		getReplicas := func() int32 {
+			current := &tsv1alpha1.TypesenseCluster{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), current)).To(Succeed())
+			return current.Spec.Replicas
+		}
+
+		// the queued writes of the nodes are far below their target, so the cluster may shrink by one size
+		observe := func(lastScaleTime *time.Time) {
+			ts.Status.Nodes = []tsv1alpha1.NodeStatus{
+				{Pod: "test-autoscaling-sts-0", QueuedWrites: 2},
+				{Pod: "test-autoscaling-sts-1", QueuedWrites: 5},
+			}
+
+			ts.Status.Autoscaling = nil
+			if lastScaleTime != nil {
+				ts.Status.Autoscaling = &tsv1alpha1.AutoscalingStatus{LastScaleTime: ptr.To(metav1.NewTime(*lastScaleTime))}
+			}
+		}
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Replicas: 5,
+					Autoscaling: &tsv1alpha1.AutoscalingSpec{
+						MinReplicas:              1,
+						MaxReplicas:              7,
+						TargetQueuedWrites:       ptr.To(int64(100)),
+						ScaleUpCooldownSeconds:   60,
+						ScaleDownCooldownSeconds: 600,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should hold off scaling down until the cooldown elapsed", func() {
+			observe(ptr.To(time.Now().Add(-5 * time.Minute)))
+
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumReady)).To(Succeed())
+			Expect(getReplicas()).To(BeEquivalentTo(5))
+			Expect(ts.Status.Autoscaling.DesiredReplicas).To(BeEquivalentTo(3))
+			Expect(ts.Status.Autoscaling.CurrentQueuedWrites).To(Equal(ptr.To(int64(5))))
+			Expect(ts.Status.Autoscaling.Message).To(HavePrefix("scaling from 5 to 3 replicas is held off by the cooldown until"))
+			Expect(recorder.Events).To(BeEmpty())
+
+			By("scaling down once the cooldown elapsed")
+			lastScaleTime := time.Now().Add(-11 * time.Minute)
+			observe(&lastScaleTime)
+
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumReady)).To(Succeed())
+			Expect(getReplicas()).To(BeEquivalentTo(3))
+			Expect(ts.Status.Autoscaling.DesiredReplicas).To(BeEquivalentTo(3))
+			Expect(ts.Status.Autoscaling.Message).To(Equal("metrics would stay within their targets with 3 replicas"))
+			Expect(ts.Status.Autoscaling.LastScaleTime.Time).To(BeTemporally(">", lastScaleTime))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Scaling from 5 to 3 replicas")))
+		})
+
+		It("should scale down right away without a previous scaling", func() {
+			observe(nil)
+
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumReady)).To(Succeed())
+			Expect(getReplicas()).To(BeEquivalentTo(3))
+			Expect(ts.Status.Autoscaling.LastScaleTime).NotTo(BeNil())
+		})
+
+		It("should suspend scaling while the quorum is not ready", func() {
+			observe(nil)
+
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumNotReady)).To(Succeed())
+			Expect(getReplicas()).To(BeEquivalentTo(5))
+			Expect(ts.Status.Autoscaling.DesiredReplicas).To(BeEquivalentTo(5))
+			Expect(ts.Status.Autoscaling.Message).To(Equal(fmt.Sprintf("scaling is suspended while the quorum is %s", ConditionReasonQuorumNotReady)))
+		})
+
+		It("should clear the status once autoscaling is disabled", func() {
+			observe(nil)
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumNotReady)).To(Succeed())
+			Expect(ts.Status.Autoscaling).NotTo(BeNil())
+
+			ts.Spec.Autoscaling = nil
+			Expect(controllerReconciler.ReconcileAutoscaling(ctx, ts, ConditionReasonQuorumReady)).To(Succeed())
+			Expect(ts.Status.Autoscaling).To(BeNil())
+		})
+
+		It("should report the replicas and the selector to the scale subresource", func() {
+			sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+
+			Expect(controllerReconciler.ReconcileScaleStatus(ctx, ts, sts)).To(Succeed())
+			Expect(ts.Status.Replicas).To(BeEquivalentTo(3))
+			Expect(ts.Status.Selector).To(Equal(labels.SelectorFromSet(getLabels(ts)).String()))
+
+			current := &tsv1alpha1.TypesenseCluster{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), current)).To(Succeed())
+			Expect(current.Status.Replicas).To(BeEquivalentTo(3))
+			Expect(current.Status.Selector).To(Equal(ts.Status.Selector))
+
+			By("following the replicas of the statefulset")
+			sts.Status.Replicas = 5
+			Expect(controllerReconciler.ReconcileScaleStatus(ctx, ts, sts)).To(Succeed())
+			Expect(ts.Status.Replicas).To(BeEquivalentTo(5))
+		})
+	})
+})
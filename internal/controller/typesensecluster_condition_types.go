@@ -12,7 +12,9 @@ type ConditionQuorum string
 
 // Definitions to manage status conditions
 const (
-	ConditionTypeReady = "Ready"
+	ConditionTypeReady           = "Ready"
+	ConditionTypeStorageExpanded = "StorageExpanded"
+	ConditionTypePaused          = "Paused"
 
 	ConditionReasonReconciliationInProgress                              = "ReconciliationInProgress"
 	ConditionReasonSecretNotReady                                        = "SecretNotReady"
@@ -32,8 +34,20 @@ const (
 	ConditionReasonQuorumNeedsAttentionMemoryOrDiskIssue ConditionQuorum = "QuorumNeedsAttentionMemoryOrDiskIssue"
 	ConditionReasonQuorumNeedsAttentionClusterIsLagging  ConditionQuorum = "QuorumNeedsAttentionClusterIsLagging"
 	ConditionReasonQuorumQueuedWrites                    ConditionQuorum = "QuorumQueuedWrites"
+	ConditionReasonQuorumRestoring                       ConditionQuorum = "QuorumRestoring"
 	ConditionReasonStatefulSetNotReady                                   = "StatefulSetNotReady"
+	ConditionReasonRestoreNotReady                                       = "RestoreNotReady"
+	ConditionReasonCertificateNotReady                                   = "CertificateNotReady"
+	ConditionReasonStorageNotReady                                       = "StorageNotReady"
+	ConditionReasonStorageExpanding                                      = "StorageExpanding"
+	ConditionReasonStorageExpanded                                       = "StorageExpanded"
+	ConditionReasonStorageShrinkNotSupported                             = "StorageShrinkNotSupported"
+	ConditionReasonStorageExpansionNotSupported                          = "StorageExpansionNotSupported"
+	ConditionReasonReconciliationPaused                                  = "ReconciliationPaused"
+	ConditionReasonReconciliationResumed                                 = "ReconciliationResumed"
+	ConditionReasonPlanNotReady                                          = "PlanNotReady"
 
+	InitReconciliationPhase   = "Bootstrapping"
 	InitReconciliationMessage = "Starting reconciliation"
 	UpdateStatusMessageFailed = "failed to update typesense cluster status"
 )
@@ -42,7 +56,7 @@ func (r *TypesenseClusterReconciler) initConditions(ctx context.Context, ts *tsv
 	if len(ts.Status.Conditions) == 0 {
 		if err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
 			meta.SetStatusCondition(&ts.Status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
-			status.Phase = "Bootstrapping"
+			status.Phase = InitReconciliationPhase
 		}); err != nil {
 			r.logger.Error(err, UpdateStatusMessageFailed)
 			return err
@@ -55,6 +69,7 @@ func (r *TypesenseClusterReconciler) setConditionNotReady(ctx context.Context, t
 	if err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
 		meta.SetStatusCondition(&ts.Status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
 		status.Phase = reason
+		status.ObservedGeneration = ts.Generation
 	}); err != nil {
 		return err
 	}
@@ -65,6 +80,7 @@ func (r *TypesenseClusterReconciler) setConditionReady(ctx context.Context, ts *
 	if err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
 		meta.SetStatusCondition(&ts.Status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Cluster is Ready"})
 		status.Phase = reason
+		status.ObservedGeneration = ts.Generation
 	}); err != nil {
 		return err
 	}
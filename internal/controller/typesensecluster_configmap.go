@@ -286,8 +286,21 @@ func (r *TypesenseClusterReconciler) getNodes(ctx context.Context, ts *tsv1alpha
 		return nil, err
 	}
 
+	// the nodes left out of the quorum by a remediation in progress are not added back until it completes
+	recovery := getQuorumRecoveryInProgress(ts)
+	replacement := getNodeReplacementInProgress(ts)
+
 	for _, s := range eps {
 		for _, e := range s.Endpoints {
+			if e.TargetRef != nil {
+				if recovery != nil && e.TargetRef.Name != recovery.Pod {
+					continue
+				}
+				if replacement != nil && e.TargetRef.Name == replacement.Pod {
+					continue
+				}
+			}
+
 			if len(e.Addresses) > 0 {
 				addr := e.Addresses[0]
 				//r.logger.V(debugLevel).Info("discovered slice endpoint", "slice", s.Name, "endpoint", e.Hostname, "address", addr)
@@ -354,7 +367,7 @@ func (r *TypesenseClusterReconciler) getEndpointSlicesForStatefulSet(ctx context
 	return readySlices, nil
 }
 
-func (r *TypesenseClusterReconciler) getNodeEndpoint(ts *tsv1alpha1.TypesenseCluster, raftNodeEndpoint string) string {
+func getNodeEndpoint(ts *tsv1alpha1.TypesenseCluster, raftNodeEndpoint string) string {
 	if hasIP4Prefix(raftNodeEndpoint) {
 		node := strings.Replace(raftNodeEndpoint, fmt.Sprintf(":%d:%d", ts.Spec.PeeringPort, ts.Spec.ApiPort), "", 1)
 		return node
@@ -366,7 +379,7 @@ func (r *TypesenseClusterReconciler) getNodeEndpoint(ts *tsv1alpha1.TypesenseClu
 	return fqdn
 }
 
-func (r *TypesenseClusterReconciler) getShortName(raftNodeEndpoint string) string {
+func getShortName(raftNodeEndpoint string) string {
 	parts := strings.SplitN(raftNodeEndpoint, ":", 2)
 	host := parts[0]
 
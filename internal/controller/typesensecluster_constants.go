@@ -4,11 +4,14 @@ const (
 	ClusterNodesConfigMap           = "%s-nodeslist"
 	ClusterAdminApiKeySecret        = "%s-admin-key"
 	ClusterAdminApiKeySecretKeyName = "typesense-api-key"
+	ClusterAdminApiKeyPendingSecret = "%s-admin-key-pending"
 
-	ClusterHeadlessService = "%s-sts-svc"
-	ClusterRestService     = "%s-svc"
-	ClusterStatefulSet     = "%s-sts"
-	ClusterAppLabel        = "%s-sts"
+	ClusterHeadlessService  = "%s-sts-svc"
+	ClusterRestService      = "%s-svc"
+	ClusterLeaderService    = "%s-leader-svc"
+	ClusterFollowersService = "%s-followers-svc"
+	ClusterStatefulSet      = "%s-sts"
+	ClusterAppLabel         = "%s-sts"
 
 	ClusterReverseProxyAppLabel  = "%s-rp"
 	ClusterReverseProxyIngress   = "%s-reverse-proxy"
@@ -31,4 +34,16 @@ const (
 
 	ClusterScraperCronJob          = "%s-scraper"
 	ClusterScraperCronJobContainer = "%s-docsearch-scraper"
+
+	ClusterTLSSecret   = "%s-tls"
+	ClusterCertificate = "%s-certificate"
+
+	ClusterDataVolumeClaim  = "data-%s"
+	ClusterRestoreConfigMap = "%s-restore"
+
+	BackupUploadContainer = "backup-%s"
+	BackupCleanupJob      = "%s-cleanup"
+	ScheduledBackup       = "%s-%d"
+
+	ClusterFinalSnapshotBackup = "%s-final-%d"
 )
@@ -24,6 +24,12 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -34,9 +40,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
 )
@@ -65,7 +74,10 @@ var (
 			// updated on spec changes. On the other hand RevisionVersion
 			// changes also on status changes. We want to omit reconciliation
 			// for status updates.
-			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+			// Annotations that trigger an action are the exception, as they don't
+			// bump the generation.
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				annotationChanged(e.ObjectOld, e.ObjectNew, triggerAnnotations...)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			// DeleteStateUnknown evaluates to false only if the object
@@ -73,6 +85,11 @@ var (
 			return !e.DeleteStateUnknown
 		},
 	})
+	triggerAnnotations = []string{
+		rotateAdminApiKeyAnnotationKey,
+		pausedAnnotationKey,
+		approvePlanAnnotationKey,
+	}
 	// kubelets sync configmaps by default every minute so let's wait for 2 minutes
 	configMapRequeuePeriod = 2 * time.Minute
 	reconcileRequeuePeriod = 60 * time.Second
@@ -119,9 +136,23 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	var ts tsv1alpha1.TypesenseCluster
 	if err := r.Get(ctx, req.NamespacedName, &ts); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteClusterMetrics(req.Namespace, req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !ts.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &ts)
+	}
+
+	if !controllerutil.ContainsFinalizer(&ts, clusterFinalizer) {
+		controllerutil.AddFinalizer(&ts, clusterFinalizer)
+		if err := r.Update(ctx, &ts); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	r.logger.Info("reconciling cluster")
 
 	err := r.initConditions(ctx, &ts)
@@ -129,7 +160,16 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	// Update strategy: Admin Secret is Immutable, will not be updated on any future change
+	// Update strategy: Skip every mutating action while paused, keep probing the nodes and reporting their status
+	paused, err := r.ReconcilePaused(ctx, &ts)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return r.observe(ctx, &ts)
+	}
+
+	// Update strategy: Admin Secret is Immutable, replaced only when a rotation is requested
 	secret, err := r.ReconcileSecret(ctx, ts)
 	if err != nil {
 		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonSecretNotReady, err)
@@ -139,6 +179,47 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// Update strategy: Replace the admin Secret and roll the pods on demand, keep the previous key for a grace period
+	rotatedSecret, err := r.ReconcileAdminApiKeyRotation(ctx, &ts, secret)
+	if err != nil {
+		r.logger.Error(err, "rotating admin api key failed")
+		r.Recorder.Eventf(&ts, "Warning", "AdminApiKeyRotationFailed", toTitle(err.Error()))
+	}
+	if rotatedSecret != nil {
+		secret = rotatedSecret
+	}
+
+	// Update strategy: Request the certificate from cert-manager, roll the pods when the certificate Secret changes
+	tlsReady, err := r.ReconcileTLS(ctx, &ts)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonCertificateNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+	if !tlsReady {
+		err = fmt.Errorf("waiting for certificate secret %s", getTLSSecretName(&ts))
+		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonCertificateNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	// Update strategy: Hold back the changes to the managed objects in plan mode, until the plan is approved
+	approved, err := r.ReconcilePlan(ctx, &ts)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonPlanNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+	if !approved {
+		return r.observe(ctx, &ts)
+	}
+
 	// Update strategy: Update the existing object, if changes are identified in the desired.Data["nodes"]
 	configMapUpdated, err := r.ReconcileConfigMap(ctx, ts)
 	if err != nil {
@@ -199,6 +280,30 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// Update strategy: Resolve the snapshot once when bootstrapping, the init containers are gated by a ConfigMap afterward
+	err = r.ReconcileRestore(ctx, &ts)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonRestoreNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Update strategy: Expand the existing claims in place and recreate the StatefulSet orphaning its pods, if the storage grows
+	recreating, err := r.ReconcileStorage(ctx, &ts)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &ts, ConditionReasonStorageNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+	if recreating {
+		r.logger.Info("waiting for statefulset to be recreated", "requeueAfter", storageExpansionRequeuePeriod)
+		return ctrl.Result{RequeueAfter: storageExpansionRequeuePeriod}, nil
+	}
+
 	// Update strategy: Update the whole specs when changes are identified
 	sts, _, err := r.ReconcileStatefulSet(ctx, &ts)
 	if err != nil {
@@ -209,6 +314,11 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	err = r.ReconcileScaleStatus(ctx, &ts, sts)
+	if err != nil {
+		r.logger.Error(err, "reporting scale status failed")
+	}
+
 	terminationGracePeriodSeconds := *sts.Spec.Template.Spec.TerminationGracePeriodSeconds
 	requeueAfter := reconcileRequeuePeriod + (time.Duration(terminationGracePeriodSeconds) * time.Second)
 
@@ -276,6 +386,9 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		if condition != ConditionReasonQuorumReady {
 			if err == nil {
 				err = errors.New("quorum is not ready")
+				if condition == ConditionReasonQuorumRestoring {
+					err = errors.New("quorum is restoring from snapshot")
+				}
 			}
 			cerr := r.setConditionNotReady(ctx, &ts, string(condition), err)
 			if cerr != nil {
@@ -284,6 +397,13 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 			r.Recorder.Eventf(&ts, "Warning", string(condition), toTitle(err.Error()))
 		} else {
+			if ts.Status.IsRestoring() {
+				err := r.completeRestore(ctx, &ts)
+				if err != nil {
+					r.logger.Error(err, "completing restore failed")
+				}
+			}
+
 			report := ts.Status.Conditions[0].Status != metav1.ConditionTrue
 
 			cerr := r.setConditionReady(ctx, &ts, string(condition))
@@ -299,14 +419,87 @@ func (r *TypesenseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	cond = condition
 
+	// Update strategy: Patch spec.replicas one odd size at a time, when the quorum is ready and the cooldown elapsed
+	err = r.ReconcileAutoscaling(ctx, &ts, condition)
+	if err != nil {
+		r.logger.Error(err, "reconciling autoscaling failed")
+	}
+
+	// Update strategy: Patch the memory limit in spec.resources, when a node runs short of memory and Auto mode is on
+	err = r.ReconcileVerticalScaling(ctx, &ts, condition)
+	if err != nil {
+		r.logger.Error(err, "reconciling vertical scaling failed")
+	}
+
+	// Update strategy: Expand the data volume of the nodes that are out of disk, restart them once it is resized
+	err = r.ReconcileOutOfDiskRemediation(ctx, &ts)
+	if err != nil {
+		r.logger.Error(err, "reconciling out of disk remediation failed")
+	}
+
+	// Update strategy: Replace a broken node with a fresh replica of the leader, on demand or when it reports ERROR
+	err = r.ReconcileNodeReplacement(ctx, &ts)
+	if err != nil {
+		r.logger.Error(err, "reconciling node replacement failed")
+	}
+
+	// Update strategy: Restart the pods that are not on the latest revision one by one, followers first and the leader last
+	rolling, err := r.ReconcileRollingUpdate(ctx, &ts, secret, condition)
+	if err != nil {
+		r.logger.Error(err, "reconciling rolling update failed")
+	}
+	if rolling && requeueAfter > rollingUpdateRequeuePeriod {
+		requeueAfter = rollingUpdateRequeuePeriod
+	}
+
 	r.logger.Info(fmt.Sprintf("%s cluster completed", string(action)), "condition", cond, "requeueAfter", requeueAfter)
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TypesenseClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	ctx := context.Background()
+	indexer := mgr.GetFieldIndexer()
+
+	err := indexer.IndexField(ctx, &tsv1alpha1.TypesenseCluster{}, additionalServerConfigurationIndexKey, indexAdditionalServerConfiguration)
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(ctx, &tsv1alpha1.TypesenseCluster{}, adminApiKeyIndexKey, indexAdminApiKey)
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(ctx, &tsv1alpha1.TypesenseCluster{}, tlsSecretIndexKey, indexTLSSecret)
+	if err != nil {
+		return err
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&tsv1alpha1.TypesenseCluster{}, eventFilters).
+		Owns(&appsv1.StatefulSet{}, ownedObjectsEventFilters).
+		Owns(&corev1.Service{}, ownedObjectsEventFilters).
+		Owns(&corev1.ConfigMap{}, ownedObjectsEventFilters).
+		Owns(&corev1.Secret{}, ownedObjectsEventFilters).
+		Owns(&networkingv1.Ingress{}, ownedObjectsEventFilters).
+		Owns(&batchv1.CronJob{}, ownedObjectsEventFilters).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(findClusterByInstanceLabel), ownedObjectsEventFilters).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(findClusterByStatefulSetPod), podEventFilters).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findClustersReferencing(additionalServerConfigurationIndexKey)), referencedObjectsEventFilters).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findClustersReferencing(adminApiKeyIndexKey)), referencedObjectsEventFilters).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findClustersReferencing(tlsSecretIndexKey)), referencedObjectsEventFilters)
+
+	// the optional api groups can be watched only if their CRDs are installed when the operator starts
+	if deployed, err := r.IsApiGroupDeployed(prometheusApiGroup); err == nil && deployed {
+		bldr = bldr.Owns(&monitoringv1.PodMonitor{}, ownedObjectsEventFilters)
+	}
+
+	if deployed, err := r.IsApiGroupDeployed(gatewayApiGroup); err == nil && deployed {
+		bldr = bldr.Owns(&gatewayv1.HTTPRoute{}, ownedObjectsEventFilters)
+	}
+
+	return bldr.
 		Named("typesense-kubernetes-operator").
 		Complete(r)
 }
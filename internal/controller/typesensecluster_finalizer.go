@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	clusterFinalizer           = "ts.opentelekomcloud.com/delete-cluster"
+	finalSnapshotLabelKey      = "ts.opentelekomcloud.com/final-snapshot"
+	finalSnapshotRequeuePeriod = 15 * time.Second
+)
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups,verbs=get;list;watch;create
+
+// finalize releases what the owner references cannot: it takes the final snapshot if requested, deletes the data
+// volumes when the retention policy says so, including those of nodes removed by a scale down, and the ReferenceGrants
+// created in the namespaces of the gateways. The finalizer is removed only then, and the owned objects are collected.
+func (r *TypesenseClusterReconciler) finalize(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(ts, clusterFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("finalizing cluster")
+
+	if ts.Spec.FinalSnapshot != nil {
+		completed, err := r.reconcileFinalSnapshot(ctx, ts)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if !completed {
+			r.logger.V(debugLevel).Info("waiting for final snapshot", "requeueAfter", finalSnapshotRequeuePeriod)
+			return ctrl.Result{RequeueAfter: finalSnapshotRequeuePeriod}, nil
+		}
+	}
+
+	storage := ts.Spec.GetStorage()
+	if storage.GetRetentionPolicy() == appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+		if err := r.deleteDataVolumeClaims(ctx, ts); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.deleteReferenceGrants(ctx, ts); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	deleteClusterMetrics(ts.Namespace, ts.Name)
+
+	controllerutil.RemoveFinalizer(ts, clusterFinalizer)
+	if err := r.Update(ctx, ts); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.logger.Info("finalizing cluster completed")
+	return ctrl.Result{}, nil
+}
+
+// reconcileFinalSnapshot creates the TypesenseBackup of the deleted cluster, and reports whether it is finished or
+// timed out. The backup is not owned by the cluster, so it outlives it, and it waits for a leader like any other.
+func (r *TypesenseClusterReconciler) reconcileFinalSnapshot(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (bool, error) {
+	spec := ts.Spec.FinalSnapshot
+	backupName := fmt.Sprintf(ClusterFinalSnapshotBackup, ts.Name, ts.DeletionTimestamp.Unix())
+	backupObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: backupName}
+
+	var tb = &tsv1alpha1.TypesenseBackup{}
+	if err := r.Get(ctx, backupObjectKey, tb); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch backup: %s", backupName))
+			return false, err
+		}
+
+		tb = &tsv1alpha1.TypesenseBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      backupName,
+				Namespace: ts.Namespace,
+				Labels: map[string]string{
+					finalSnapshotLabelKey: ts.Name,
+				},
+			},
+			Spec: tsv1alpha1.TypesenseBackupSpec{
+				Cluster:        corev1.LocalObjectReference{Name: ts.Name},
+				Destination:    *spec.Destination.DeepCopy(),
+				DeletionPolicy: "Retain",
+				Image:          spec.Image,
+			},
+		}
+
+		r.logger.Info("taking final snapshot", "backup", backupName)
+		if err := r.Create(ctx, tb); err != nil {
+			r.logger.Error(err, "creating final snapshot backup failed", "backup", backupName)
+			return false, err
+		}
+
+		r.Recorder.Eventf(ts, "Normal", "FinalSnapshotStarted", "Taking final snapshot with backup %s", backupName)
+		return false, nil
+	}
+
+	switch tb.Status.Phase {
+	case tsv1alpha1.BackupCompleted:
+		r.Recorder.Eventf(ts, "Normal", "FinalSnapshotCompleted", "Final snapshot uploaded to %s", tb.Status.Location)
+		return true, nil
+	case tsv1alpha1.BackupFailed:
+		r.Recorder.Eventf(ts, "Warning", "FinalSnapshotFailed", "Final snapshot backup %s failed", backupName)
+		return true, nil
+	}
+
+	if time.Since(tb.CreationTimestamp.Time) > spec.GetTimeout() {
+		r.Recorder.Eventf(ts, "Warning", "FinalSnapshotFailed", "Final snapshot backup %s did not complete within %s", backupName, spec.GetTimeout())
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// deleteDataVolumeClaims deletes every data volume of the cluster, the StatefulSet retention policy covers only the
+// volumes of its current replicas
+func (r *TypesenseClusterReconciler) deleteDataVolumeClaims(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &pvcs, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		r.logger.Error(err, "failed to list persistent volume claims")
+		return err
+	}
+
+	for _, pvc := range pvcs.Items {
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+
+		r.logger.V(debugLevel).Info("deleting persistent volume claim", "pvc", pvc.Name)
+		if err := r.Delete(ctx, &pvc); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "deleting persistent volume claim failed", "pvc", pvc.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteReferenceGrants deletes the ReferenceGrants of the cluster in the namespaces of the gateways, they cannot be
+// owned by the cluster across namespaces
+func (r *TypesenseClusterReconciler) deleteReferenceGrants(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	if deployed, err := r.IsApiGroupDeployed(gatewayApiGroup); err != nil || !deployed {
+		return nil
+	}
+
+	referenceGrantsLabelSelector := labels.SelectorFromSet(map[string]string{
+		"app.kubernetes.io/managed-by": "typesense-operator",
+		"app":                          fmt.Sprintf(ClusterAppLabel, ts.Name),
+	})
+
+	var referenceGrants gatewayv1beta1.ReferenceGrantList
+	if err := r.List(ctx, &referenceGrants, &client.ListOptions{
+		LabelSelector: referenceGrantsLabelSelector,
+	}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+
+		gerr := fmt.Errorf("failed to list reference grants: %w", err)
+		r.logger.Error(gerr, "finalizing cluster failed")
+		return gerr
+	}
+
+	for _, rg := range referenceGrants.Items {
+		r.logger.V(debugLevel).Info("deleting reference grant", "namespace", rg.Namespace, "reference_grant", rg.Name)
+		if err := r.deleteReferenceGrant(ctx, &rg); err != nil && !apierrors.IsNotFound(err) {
+			gerr := fmt.Errorf("deleting reference grant failed: %w", err)
+			r.logger.Error(gerr, "finalizing cluster failed")
+			return gerr
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Finalizer", func() {
+	const resourceName = "test-finalizer"
+
+	ctx := context.Background()
+
+	var ts *tsv1alpha1.TypesenseCluster
+	var pvc *corev1.PersistentVolumeClaim
+	var server *httptest.Server
+	var controllerReconciler *TypesenseClusterReconciler
+
+	// createDeletedCluster creates the cluster with its finalizer and deletes it, so it waits to be finalized
+	createDeletedCluster := func(retentionPolicy string, finalSnapshot *tsv1alpha1.FinalSnapshotSpec) {
+		ts = &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       resourceName,
+				Namespace:  "default",
+				Finalizers: []string{clusterFinalizer},
+			},
+			Spec: tsv1alpha1.TypesenseClusterSpec{
+				Replicas: 3,
+				Storage: &tsv1alpha1.StorageSpec{
+					Size:             resource.MustParse("100Mi"),
+					StorageClassName: "standard",
+					RetentionPolicy:  retentionPolicy,
+				},
+				FinalSnapshot: finalSnapshot,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), ts)).To(Succeed())
+		Expect(ts.DeletionTimestamp).NotTo(BeNil())
+	}
+
+	isFinalized := func() bool {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), &tsv1alpha1.TypesenseCluster{})
+		if apierrors.IsNotFound(err) {
+			return true
+		}
+
+		Expect(err).NotTo(HaveOccurred())
+		return false
+	}
+
+	BeforeEach(func() {
+		// the gateway api is not served, so there are no reference grants to delete
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api":
+				writeJson(w, http.StatusOK, metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}})
+			default:
+				writeJson(w, http.StatusOK, metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}})
+			}
+		}))
+
+		controllerReconciler = &TypesenseClusterReconciler{
+			Client:          k8sClient,
+			Scheme:          k8sClient.Scheme(),
+			Recorder:        record.NewFakeRecorder(10),
+			DiscoveryClient: discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}),
+		}
+
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("data-%s-sts-0", resourceName),
+				Namespace: "default",
+				Labels:    map[string]string{"app": fmt.Sprintf(ClusterAppLabel, resourceName)},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Mi")},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, pvc))).To(Succeed())
+
+		current := &tsv1alpha1.TypesenseCluster{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), current); err == nil {
+			current.Finalizers = nil
+			Expect(k8sClient.Update(ctx, current)).To(Succeed())
+		}
+	})
+
+	It("should keep the data volumes when they are retained", func() {
+		createDeletedCluster("Retain", nil)
+
+		_, err := controllerReconciler.finalize(ctx, ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isFinalized()).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), &corev1.PersistentVolumeClaim{})).To(Succeed())
+	})
+
+	It("should delete the data volumes when they are not retained", func() {
+		createDeletedCluster("Delete", nil)
+
+		_, err := controllerReconciler.finalize(ctx, ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isFinalized()).To(BeTrue())
+
+		current := &corev1.PersistentVolumeClaim{}
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), current)
+		Expect(apierrors.IsNotFound(err) || current.DeletionTimestamp != nil).To(BeTrue())
+	})
+
+	It("should wait for the final snapshot before letting the cluster go", func() {
+		createDeletedCluster("Delete", &tsv1alpha1.FinalSnapshotSpec{
+			Destination: tsv1alpha1.BackupDestinationSpec{
+				S3: tsv1alpha1.S3DestinationSpec{
+					Bucket:            "snapshots",
+					CredentialsSecret: corev1.LocalObjectReference{Name: "s3-credentials"},
+				},
+			},
+		})
+
+		result, err := controllerReconciler.finalize(ctx, ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(finalSnapshotRequeuePeriod))
+		Expect(isFinalized()).To(BeFalse())
+
+		tb := &tsv1alpha1.TypesenseBackup{}
+		backupName := fmt.Sprintf(ClusterFinalSnapshotBackup, resourceName, ts.DeletionTimestamp.Unix())
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: backupName}, tb)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, tb)).To(Succeed())
+		}()
+		Expect(tb.Labels).To(HaveKeyWithValue(finalSnapshotLabelKey, resourceName))
+		Expect(tb.Spec.Cluster.Name).To(Equal(resourceName))
+		Expect(tb.Spec.DeletionPolicy).To(Equal("Retain"))
+		Expect(tb.OwnerReferences).To(BeEmpty())
+
+		// the data volumes are kept while the snapshot is taken
+		_, err = controllerReconciler.finalize(ctx, ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isFinalized()).To(BeFalse())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), &corev1.PersistentVolumeClaim{})).To(Succeed())
+
+		tb.Status.Phase = tsv1alpha1.BackupCompleted
+		Expect(k8sClient.Status().Update(ctx, tb)).To(Succeed())
+
+		_, err = controllerReconciler.finalize(ctx, ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isFinalized()).To(BeTrue())
+	})
+})
@@ -39,7 +39,7 @@ const (
 			{{.ServerDirectives}}
 			{{- end}}
 			location / {
-			  proxy_pass http://{{.ServiceName}}-svc:{{.ServicePort}}/;
+			  proxy_pass {{.ServiceScheme}}://{{.ServiceName}}-svc:{{.ServicePort}}/;
 			  proxy_pass_request_headers on;
 
 			  {{- if .LocationDirectives}}
@@ -481,6 +481,7 @@ func (r *TypesenseClusterReconciler) getIngressNginxConf(ts *tsv1alpha1.Typesens
 		Referer            string
 		ServiceName        string
 		ServicePort        string
+		ServiceScheme      string
 	}{
 		HttpDirectives:     httpDirectives,
 		ServerDirectives:   serverDirectives,
@@ -488,6 +489,7 @@ func (r *TypesenseClusterReconciler) getIngressNginxConf(ts *tsv1alpha1.Typesens
 		Referer:            ref,
 		ServiceName:        ts.Name,
 		ServicePort:        strconv.Itoa(ts.Spec.ApiPort),
+		ServiceScheme:      ts.Spec.GetApiScheme(),
 	}
 
 	tmpl, err := template.New("nginxConf").Parse(confTemplate)
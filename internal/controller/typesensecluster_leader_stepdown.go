@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// leaderStepDownTimeout after which a leader that did not step down is restarted anyway
+	leaderStepDownTimeout = time.Minute
+)
+
+// leaderStepDownScript runs in the preStop hook of the typesense container: a leader asks one of its peers to start
+// an election, and waits for it to take over, so a voluntary termination does not leave the quorum without a leader
+// until the election timeout. It uses curl if the image provides it, and a plain http request otherwise.
+const leaderStepDownScript = `request() {
+  if command -v curl >/dev/null 2>&1; then
+    curl -sk -m 5 -X "$1" -H "x-typesense-api-key: $TYPESENSE_API_KEY" "%[1]s://$2:$3$4"
+  elif [ "%[1]s" = "http" ]; then
+    exec 3<>"/dev/tcp/$2/$3" || return 1
+    printf '%%s %%s HTTP/1.0\r\nHost: %%s\r\nx-typesense-api-key: %%s\r\nContent-Length: 0\r\n\r\n' "$1" "$4" "$2" "$TYPESENSE_API_KEY" >&3
+    cat <&3
+    exec 3<&-
+  fi
+}
+is_leader() {
+  request GET localhost "$TYPESENSE_API_PORT" /status | grep -Eq '"state": ?"LEADER"'
+}
+is_leader || exit 0
+for peer in $(tr ',' ' ' < "$TYPESENSE_NODES"); do
+  host="${peer%%%%:*}"
+  [ "$host" = "$TYPESENSE_PEERING_ADDRESS" ] && continue
+  request POST "$host" "${peer##*:}" /operations/vote | grep -Eq '"success": ?true' && break
+done
+for i in $(seq %[2]d); do
+  is_leader || exit 0
+  sleep 1
+done
+`
+
+// getLeaderStepDownLifecycle returns the preStop hook stepping the leader down, it waits for half of the termination
+// grace period at most, so Typesense keeps the rest of it to shut down
+func getLeaderStepDownLifecycle(ts *tsv1alpha1.TypesenseCluster) *corev1.Lifecycle {
+	seconds := max(ts.Spec.GetTerminationGracePeriodSeconds()/2, 1)
+
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/bash", "-c", fmt.Sprintf(leaderStepDownScript, ts.Spec.GetApiScheme(), seconds)},
+			},
+		},
+	}
+}
+
+// stepDownLeader asks the follower with the highest committed index to start an election, so it takes over from the
+// leader before the leader is restarted
+func (r *TypesenseClusterReconciler) stepDownLeader(
+	ctx context.Context,
+	httpClient *http.Client,
+	ts *tsv1alpha1.TypesenseCluster,
+	secret *corev1.Secret,
+	pods []corev1.Pod,
+	nodesStatus map[string]NodeStatus,
+) (string, error) {
+	var follower *NodeEndpoint
+	committedIndex := -1
+	for _, pod := range pods {
+		status := nodesStatus[pod.Name]
+		if status.State != FollowerState || status.CommittedIndex <= committedIndex {
+			continue
+		}
+
+		follower = &NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(pod.Status.PodIP)}
+		committedIndex = status.CommittedIndex
+	}
+
+	if follower == nil {
+		return "", fmt.Errorf("no follower found to take over")
+	}
+
+	return follower.PodName, r.vote(ctx, httpClient, *follower, ts, secret)
+}
+
+// vote makes the follower start an election, and take over leadership once it wins it
+func (r *TypesenseClusterReconciler) vote(ctx context.Context, httpClient *http.Client, node NodeEndpoint, ts *tsv1alpha1.TypesenseCluster, secret *corev1.Secret) error {
+	u, err := r.buildUrl(node, ts, ts.Spec.ApiPort, "/operations/vote")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return err
+	}
+
+	apiKey := secret.Data[ClusterAdminApiKeySecretKeyName]
+	req.Header.Set("x-typesense-api-key", string(apiKey))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vote on %s returned status code %d", getShortName(node.PodName), resp.StatusCode)
+	}
+
+	r.logger.V(debugLevel).Info("vote", "node", getShortName(node.PodName))
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Leader Step Down", func() {
+	ctx := context.Background()
+
+	Context("When the leader is stepped down before it is restarted", func() {
+		var ts *tsv1alpha1.TypesenseCluster
+		var pods []corev1.Pod
+		var server *httptest.Server
+		var votes []string
+		var voteStatus int
+		var mu sync.Mutex
+		var controllerReconciler *TypesenseClusterReconciler
+
+		BeforeEach(func() {
+			// every node listens on its own loopback address, so a vote is traced back to its node
+			votes = nil
+			voteStatus = http.StatusOK
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				host, _, err := net.SplitHostPort(r.Host)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/operations/vote"))
+				Expect(r.Header.Get("x-typesense-api-key")).To(Equal("admin"))
+
+				mu.Lock()
+				votes = append(votes, host)
+				mu.Unlock()
+
+				writeJson(w, voteStatus, map[string]bool{"success": voteStatus == http.StatusOK})
+			}))
+			listener, err := net.Listen("tcp", "0.0.0.0:0")
+			Expect(err).NotTo(HaveOccurred())
+			server.Listener = listener
+			server.Start()
+
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port, err := strconv.Atoi(u.Port())
+			Expect(err).NotTo(HaveOccurred())
+
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-stepdown", Namespace: "default"},
+				Spec:       tsv1alpha1.TypesenseClusterSpec{ApiPort: port},
+			}
+
+			pods = nil
+			for i := range 3 {
+				pods = append(pods, corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("test-stepdown-sts-%d", i), Namespace: "default"},
+					Status:     corev1.PodStatus{PodIP: fmt.Sprintf("127.0.0.%d", i+1)},
+				})
+			}
+
+			controllerReconciler = &TypesenseClusterReconciler{InCluster: true}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		stepDownLeader := func(nodesStatus map[string]NodeStatus) (string, error) {
+			secret := &corev1.Secret{Data: map[string][]byte{ClusterAdminApiKeySecretKeyName: []byte("admin")}}
+			return controllerReconciler.stepDownLeader(ctx, http.DefaultClient, ts, secret, pods, nodesStatus)
+		}
+
+		It("should ask the follower with the highest committed index to take over", func() {
+			follower, err := stepDownLeader(map[string]NodeStatus{
+				pods[0].Name: {State: FollowerState, CommittedIndex: 41},
+				pods[1].Name: {State: LeaderState, CommittedIndex: 43},
+				pods[2].Name: {State: FollowerState, CommittedIndex: 42},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(follower).To(Equal(pods[2].Name))
+			Expect(votes).To(Equal([]string{"127.0.0.3"}))
+		})
+
+		It("should fail when no follower can take over", func() {
+			_, err := stepDownLeader(map[string]NodeStatus{
+				pods[0].Name: {State: CandidateState, CommittedIndex: 41},
+				pods[1].Name: {State: LeaderState, CommittedIndex: 43},
+				pods[2].Name: {State: UnreachableState},
+			})
+			Expect(err).To(MatchError("no follower found to take over"))
+			Expect(votes).To(BeEmpty())
+		})
+
+		It("should fail when the follower refuses to vote", func() {
+			voteStatus = http.StatusInternalServerError
+
+			follower, err := stepDownLeader(map[string]NodeStatus{
+				pods[0].Name: {State: FollowerState, CommittedIndex: 42},
+				pods[1].Name: {State: LeaderState, CommittedIndex: 42},
+			})
+			Expect(follower).To(Equal(pods[0].Name))
+			Expect(err).To(MatchError(ContainSubstring("returned status code 500")))
+		})
+	})
+
+	DescribeTable("stepping the leader down on termination",
+		func(gracePeriod *int64, tls bool, scheme string, seconds int) {
+			ts := &tsv1alpha1.TypesenseCluster{Spec: tsv1alpha1.TypesenseClusterSpec{TerminationGracePeriodSeconds: gracePeriod}}
+			if tls {
+				ts.Spec.TLS = &tsv1alpha1.TLSSpec{}
+			}
+
+			lifecycle := getLeaderStepDownLifecycle(ts)
+			Expect(lifecycle.PreStop).NotTo(BeNil())
+			Expect(lifecycle.PreStop.Exec.Command).To(HaveLen(3))
+
+			script := lifecycle.PreStop.Exec.Command[2]
+			Expect(script).To(ContainSubstring(fmt.Sprintf(`"%s://$2:$3$4"`, scheme)))
+			Expect(script).To(ContainSubstring(fmt.Sprintf("$(seq %d)", seconds)))
+			Expect(script).NotTo(ContainSubstring("%!"))
+		},
+		Entry("waits for half of the grace period", ptr.To[int64](60), false, "http", 30),
+		Entry("waits for at least a second", ptr.To[int64](1), false, "http", 1),
+		Entry("waits for half of the default grace period", nil, false, "http", 2),
+		Entry("reaches the leader over tls", ptr.To[int64](30), true, "https", 15),
+	)
+})
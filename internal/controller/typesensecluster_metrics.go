@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "typesense_operator"
+)
+
+var (
+	clusterMetricsLabels = []string{"namespace", "cluster"}
+
+	quorumHealthyNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "healthy_nodes",
+			Help:      "Number of nodes whose raft readiness gate is true",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumAvailableNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "available_nodes",
+			Help:      "Number of nodes in the nodes list of the quorum",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumMinRequiredNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "min_required_nodes",
+			Help:      "Minimum number of healthy nodes the quorum requires",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumMaxQueuedWrites = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "max_queued_writes",
+			Help:      "Highest number of queued writes reported by any node",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumHealthyWriteLagThreshold = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "healthy_write_lag_threshold",
+			Help:      "Queued writes above which the cluster is reported as lagging",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumDowngradesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "downgrades_total",
+			Help:      "Number of times the quorum was downgraded to a single node",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumUpgradesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "upgrades_total",
+			Help:      "Number of times the quorum was scaled back up after a downgrade",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumPurgesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "purges_total",
+			Help:      "Number of times all the pods of the quorum were deleted",
+		},
+		clusterMetricsLabels,
+	)
+
+	quorumSplitBrainsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "split_brains_total",
+			Help:      "Number of times more than one leader was detected",
+		},
+		clusterMetricsLabels,
+	)
+
+	readinessGateTransitionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "quorum",
+			Name:      "readiness_gate_transitions_total",
+			Help:      "Number of times the raft readiness gate of a pod changed its status",
+		},
+		[]string{"namespace", "cluster", "status"},
+	)
+
+	nodeStatusProbeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "node",
+			Name:      "status_probe_duration_seconds",
+			Help:      "Latency of the requests to the /status endpoint of the nodes",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		clusterMetricsLabels,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		quorumHealthyNodes,
+		quorumAvailableNodes,
+		quorumMinRequiredNodes,
+		quorumMaxQueuedWrites,
+		quorumHealthyWriteLagThreshold,
+		quorumDowngradesTotal,
+		quorumUpgradesTotal,
+		quorumPurgesTotal,
+		quorumSplitBrainsTotal,
+		readinessGateTransitionsTotal,
+		nodeStatusProbeDuration,
+	)
+}
+
+// deleteClusterMetrics drops the series of a deleted cluster
+func deleteClusterMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "cluster": name}
+
+	quorumHealthyNodes.DeletePartialMatch(labels)
+	quorumAvailableNodes.DeletePartialMatch(labels)
+	quorumMinRequiredNodes.DeletePartialMatch(labels)
+	quorumMaxQueuedWrites.DeletePartialMatch(labels)
+	quorumHealthyWriteLagThreshold.DeletePartialMatch(labels)
+	quorumDowngradesTotal.DeletePartialMatch(labels)
+	quorumUpgradesTotal.DeletePartialMatch(labels)
+	quorumPurgesTotal.DeletePartialMatch(labels)
+	quorumSplitBrainsTotal.DeletePartialMatch(labels)
+	readinessGateTransitionsTotal.DeletePartialMatch(labels)
+	nodeStatusProbeDuration.DeletePartialMatch(labels)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Metrics", func() {
+	ctx := context.Background()
+
+	var controllerReconciler *TypesenseClusterReconciler
+
+	BeforeEach(func() {
+		controllerReconciler = &TypesenseClusterReconciler{
+			Client:    k8sClient,
+			Scheme:    k8sClient.Scheme(),
+			Recorder:  record.NewFakeRecorder(10),
+			InCluster: true,
+		}
+	})
+
+	// getSampleCount returns the number of observations of the probe latency of the cluster
+	getSampleCount := func(namespace, name string) uint64 {
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, family := range families {
+			if family.GetName() != "typesense_operator_node_status_probe_duration_seconds" {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				labels := map[string]string{}
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+
+				if labels["namespace"] == namespace && labels["cluster"] == name {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+
+		return 0
+	}
+
+	It("should register the collectors with the controller-runtime registry", func() {
+		quorumHealthyNodes.WithLabelValues("default", "test-metrics-registry").Set(3)
+		quorumDowngradesTotal.WithLabelValues("default", "test-metrics-registry").Inc()
+		defer deleteClusterMetrics("default", "test-metrics-registry")
+
+		Expect(testutil.GatherAndCount(metrics.Registry,
+			"typesense_operator_quorum_healthy_nodes",
+			"typesense_operator_quorum_downgrades_total",
+		)).To(BeNumerically(">=", 2))
+	})
+
+	It("should observe the latency of the node status probes", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJson(w, http.StatusOK, NodeStatus{State: LeaderState})
+		}))
+		defer server.Close()
+
+		ts := newFakeTypesenseApiClient(server).ts
+		ts.Name = "test-metrics-probe"
+		defer deleteClusterMetrics(ts.Namespace, ts.Name)
+
+		before := getSampleCount(ts.Namespace, ts.Name)
+		_, err := controllerReconciler.getNodeStatus(ctx, http.DefaultClient, NodeEndpoint{PodName: "test-metrics-probe-sts-0", IP: net.ParseIP("127.0.0.1")}, ts, &corev1.Secret{}, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getSampleCount(ts.Namespace, ts.Name)).To(Equal(before + 1))
+	})
+
+	It("should count the purges of the quorum", func() {
+		const resourceName = "test-metrics-purge"
+
+		ts := &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+		defer deleteClusterMetrics(ts.Namespace, ts.Name)
+
+		sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 1, "1")
+		pod := newStatefulSetPod(sts, 0, sts.Status.CurrentRevision, true)
+		Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+
+		Expect(controllerReconciler.PurgeStatefulSetPods(ctx, sts, ts)).To(Succeed())
+		Expect(testutil.ToFloat64(quorumPurgesTotal.WithLabelValues(ts.Namespace, ts.Name))).To(BeEquivalentTo(1))
+
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &corev1.Pod{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should drop the series of a deleted cluster only", func() {
+		quorumHealthyNodes.WithLabelValues("default", "test-metrics-deleted").Set(3)
+		readinessGateTransitionsTotal.WithLabelValues("default", "test-metrics-deleted", "True").Inc()
+		quorumHealthyNodes.WithLabelValues("default", "test-metrics-kept").Set(1)
+		defer deleteClusterMetrics("default", "test-metrics-kept")
+
+		deleteClusterMetrics("default", "test-metrics-deleted")
+
+		Expect(testutil.ToFloat64(quorumHealthyNodes.WithLabelValues("default", "test-metrics-kept"))).To(BeEquivalentTo(1))
+		Expect(quorumHealthyNodes.DeleteLabelValues("default", "test-metrics-deleted")).To(BeFalse())
+		Expect(readinessGateTransitionsTotal.DeleteLabelValues("default", "test-metrics-deleted", "True")).To(BeFalse())
+	})
+})
@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	replaceNodeAnnotationKey = "ts.opentelekomcloud.com/replace-node"
+	// nodeReplacementTimeout after which a replacement whose fresh replica did not come up is given up on
+	nodeReplacementTimeout = 15 * time.Minute
+)
+
+// ReconcileNodeReplacement replaces a single node that cannot recover on its own with a fresh replica, instead of
+// downgrading the whole quorum: the node is removed from the nodes list, its data volume and pod are deleted once the
+// other nodes synced the list, and the new replica is added back to resync from the leader. The rest of the quorum
+// keeps serving throughout. A node is replaced when its pod is annotated, or when it reports ERROR and automatic
+// replacement is enabled. One node is replaced at a time, and only while another node leads.
+func (r *TypesenseClusterReconciler) ReconcileNodeReplacement(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	r.logger.V(debugLevel).Info("reconciling node replacement")
+
+	if replacement := getNodeReplacementInProgress(ts); replacement != nil {
+		return r.continueNodeReplacement(ctx, ts, *replacement)
+	}
+
+	if getQuorumRecoveryInProgress(ts) != nil {
+		return nil
+	}
+
+	requested, err := r.getReplaceNodeRequests(ctx, ts)
+	if err != nil {
+		return err
+	}
+
+	if len(requested) > 0 {
+		pod := requested[0]
+		return r.startNodeReplacement(ctx, ts, pod, getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, pod))
+	}
+
+	if !ts.Spec.IsAutomaticNodeReplacementEnabled() {
+		return nil
+	}
+
+	for _, node := range ts.Status.Nodes {
+		if node.Role != string(ErrorState) || node.Healthy {
+			continue
+		}
+
+		// the fresh replica may report errors while it is resyncing
+		latest := getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, node.Pod)
+		if latest != nil && latest.Phase == tsv1alpha1.RemediationCompleted && latest.CompletedAt != nil &&
+			time.Since(latest.CompletedAt.Time) < nodeReplacementTimeout {
+			continue
+		}
+
+		return r.startNodeReplacement(ctx, ts, node.Pod, latest)
+	}
+
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) startNodeReplacement(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, pod string, latest *tsv1alpha1.RemediationStatus) error {
+	remediation := tsv1alpha1.RemediationStatus{
+		Type:      tsv1alpha1.RemediationNodeReplacement,
+		Pod:       pod,
+		Object:    fmt.Sprintf(ClusterDataVolumeClaim, pod),
+		StartedAt: ptr.To(metav1.Now()),
+	}
+
+	leader := ""
+	healthyNodes := 0
+	for _, node := range ts.Status.Nodes {
+		if node.Pod == pod {
+			if node.Role == string(LeaderState) {
+				return r.failRemediation(ctx, ts, remediation, latest, fmt.Sprintf("%s leads the quorum", pod))
+			}
+			continue
+		}
+
+		if node.Role == string(LeaderState) {
+			leader = node.Pod
+		}
+		if node.Healthy {
+			healthyNodes++
+		}
+	}
+
+	if leader == "" {
+		return r.failRemediation(ctx, ts, remediation, latest, "no other node leads the quorum")
+	}
+
+	minRequiredNodes := getMinimumRequiredNodes(int(ts.Spec.Replicas))
+	if healthyNodes < minRequiredNodes {
+		return r.failRemediation(ctx, ts, remediation, latest, fmt.Sprintf("only %d healthy nodes would remain, %d are required", healthyNodes, minRequiredNodes))
+	}
+
+	r.logger.Info("replacing node", "pod", pod, "leader", leader)
+
+	// the replacement is recorded first, so the node is left out of the nodes list from now on
+	remediation.Phase = tsv1alpha1.RemediationInProgress
+	remediation.Message = fmt.Sprintf("replacing %s with a fresh replica of leader %s", pod, leader)
+	if err := r.updateRemediation(ctx, ts, remediation); err != nil {
+		return err
+	}
+
+	if err := r.updateNodesConfigMap(ctx, ts); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Warning", "NodeReplacementStarted", toTitle(remediation.Message))
+	return nil
+}
+
+// continueNodeReplacement deletes the data volume and the pod of the node once the other nodes had the time to sync
+// the nodes list without it, and adds the fresh replica back once it runs. The steps are derived from the creation
+// time of the pod, so they are resumed after a restart of the operator.
+func (r *TypesenseClusterReconciler) continueNodeReplacement(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, replacement tsv1alpha1.RemediationStatus) error {
+	elapsed := time.Since(replacement.StartedAt.Time)
+	if elapsed > nodeReplacementTimeout {
+		err := r.failRemediation(ctx, ts, replacement, nil, fmt.Sprintf("%s was not replaced within %s", replacement.Pod, nodeReplacementTimeout))
+		if err != nil {
+			return err
+		}
+
+		return r.updateNodesConfigMap(ctx, ts)
+	}
+
+	var pod = &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: replacement.Pod}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for fresh replica to be created", "pod", replacement.Pod)
+			return nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch pod: %s", replacement.Pod))
+		return err
+	}
+
+	if pod.CreationTimestamp.Before(replacement.StartedAt) {
+		if elapsed < configMapRequeuePeriod {
+			r.logger.V(debugLevel).Info("waiting for nodes to sync the quorum configuration", "pod", replacement.Pod)
+			return nil
+		}
+
+		r.logger.Info("deleting data volume and pod of replaced node", "pod", replacement.Pod, "pvc", replacement.Object)
+
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: ts.Namespace, Name: replacement.Object}}
+		if err := r.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "deleting persistent volume claim failed", "pvc", replacement.Object)
+			return err
+		}
+
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "failed to delete pod", "pod", replacement.Pod)
+			return err
+		}
+
+		return nil
+	}
+
+	if pod.Status.Phase == corev1.PodPending {
+		// the fresh replica was created while the deleted data volume was still terminating
+		var pvc = &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: replacement.Object}, pvc)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch persistent volume claim: %s", replacement.Object))
+			return err
+		}
+
+		if apierrors.IsNotFound(err) || pvc.DeletionTimestamp != nil {
+			r.logger.Info("recreating fresh replica bound to a terminating data volume", "pod", replacement.Pod)
+			if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				r.logger.Error(err, "failed to delete pod", "pod", replacement.Pod)
+				return err
+			}
+		}
+	}
+
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+		r.logger.V(debugLevel).Info("waiting for fresh replica to run", "pod", replacement.Pod, "phase", pod.Status.Phase)
+		return nil
+	}
+
+	r.logger.Info("adding fresh replica back to the quorum", "pod", replacement.Pod)
+
+	// the replacement is completed first, the nodes list would otherwise keep leaving the node out
+	replacement.Phase = tsv1alpha1.RemediationCompleted
+	replacement.Message = fmt.Sprintf("replaced %s with a fresh replica resyncing from the leader", replacement.Pod)
+	replacement.CompletedAt = ptr.To(metav1.Now())
+	if err := r.updateRemediation(ctx, ts, replacement); err != nil {
+		return err
+	}
+
+	if err := r.updateNodesConfigMap(ctx, ts); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "NodeReplacementCompleted", toTitle(replacement.Message))
+	return nil
+}
+
+// getReplaceNodeRequests returns the pods annotated for replacement, the fresh replicas are created without the
+// annotation
+func (r *TypesenseClusterReconciler) getReplaceNodeRequests(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) ([]string, error) {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		r.logger.Error(err, "failed to list pods")
+		return nil, err
+	}
+
+	requested := make([]string, 0)
+	for _, pod := range pods.Items {
+		if pod.Annotations[replaceNodeAnnotationKey] == "true" && pod.DeletionTimestamp == nil {
+			requested = append(requested, pod.Name)
+		}
+	}
+
+	return requested, nil
+}
+
+// updateNodesConfigMap rewrites the nodes list, and makes the kubelet sync it to the pods right away
+func (r *TypesenseClusterReconciler) updateNodesConfigMap(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	configMapName := fmt.Sprintf(ClusterNodesConfigMap, ts.Name)
+
+	var cm = &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: configMapName}, cm); err != nil {
+		r.logger.Error(err, fmt.Sprintf("unable to fetch config map: %s", configMapName))
+		return err
+	}
+
+	if _, _, _, err := r.updateConfigMap(ctx, ts, cm, nil, true); err != nil {
+		return err
+	}
+
+	return r.forcePodsConfigMapUpdate(ctx, ts)
+}
+
+func getNodeReplacementInProgress(ts *tsv1alpha1.TypesenseCluster) *tsv1alpha1.RemediationStatus {
+	for i, remediation := range ts.Status.Remediations {
+		if remediation.Type == tsv1alpha1.RemediationNodeReplacement && remediation.Phase == tsv1alpha1.RemediationInProgress {
+			return &ts.Status.Remediations[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+// createdAtClient moves the creation timestamp of the pods back in time, it cannot be set when they are created
+type createdAtClient struct {
+	client.Client
+	createdAt time.Time
+}
+
+func (c *createdAtClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+
+	if _, ok := obj.(*corev1.Pod); ok {
+		obj.SetCreationTimestamp(metav1.NewTime(c.createdAt))
+	}
+
+	return nil
+}
+
+var _ = Describe("TypesenseCluster Node Replacement", func() {
+	const resourceName = "test-node-replacement"
+
+	ctx := context.Background()
+
+	var ts *tsv1alpha1.TypesenseCluster
+	var sts *appsv1.StatefulSet
+	var cm *corev1.ConfigMap
+	var slice *discoveryv1.EndpointSlice
+	var pods []corev1.Pod
+	var controllerReconciler *TypesenseClusterReconciler
+
+	getNodes := func() []string {
+		current := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+		return strings.Split(current.Data["nodes"], ",")
+	}
+
+	// setNodes reports the role and health of the nodes by their ordinal
+	setNodes := func(nodes ...tsv1alpha1.NodeStatus) {
+		for i := range nodes {
+			nodes[i].Pod = pods[i].Name
+		}
+
+		Expect(controllerReconciler.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Nodes = nodes
+		})).To(Succeed())
+	}
+
+	annotate := func(ordinal int) {
+		pod := &corev1.Pod{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&pods[ordinal]), pod)).To(Succeed())
+		pod.Annotations = map[string]string{replaceNodeAnnotationKey: "true"}
+		Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+	}
+
+	startReplacement := func(startedAt time.Time) {
+		Expect(controllerReconciler.updateRemediation(ctx, ts, tsv1alpha1.RemediationStatus{
+			Type:      tsv1alpha1.RemediationNodeReplacement,
+			Pod:       pods[2].Name,
+			Phase:     tsv1alpha1.RemediationInProgress,
+			Object:    fmt.Sprintf(ClusterDataVolumeClaim, pods[2].Name),
+			StartedAt: ptr.To(metav1.NewTime(startedAt)),
+		})).To(Succeed())
+	}
+
+	isDeleted := func(obj client.Object) bool {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return true
+		}
+
+		Expect(err).NotTo(HaveOccurred())
+		return obj.GetDeletionTimestamp() != nil
+	}
+
+	BeforeEach(func() {
+		ts = &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec: tsv1alpha1.TypesenseClusterSpec{
+				Replicas:    3,
+				ApiPort:     8108,
+				PeeringPort: 8107,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+		sts = newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+		sts.Spec.ServiceName = fmt.Sprintf("%s-svc", sts.Name)
+		Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+
+		slice = &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      sts.Spec.ServiceName,
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: sts.Spec.ServiceName},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+		}
+
+		pods = nil
+		nodes := make([]string, 0, 3)
+		for i := range 3 {
+			ip := fmt.Sprintf("10.0.0.%d", i+1)
+
+			pod := newStatefulSetPod(sts, i, sts.Status.CurrentRevision, true)
+			podStatus := pod.Status
+			podStatus.PodIP = ip
+			Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+			pod.Status = podStatus
+			Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+			pods = append(pods, pod)
+
+			slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+				Addresses: []string{ip},
+				TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod.Name, Namespace: "default"},
+			})
+			nodes = append(nodes, fmt.Sprintf("%s:%d:%d", ip, ts.Spec.PeeringPort, ts.Spec.ApiPort))
+		}
+		Expect(k8sClient.Create(ctx, slice)).To(Succeed())
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+			Data:       map[string]string{"nodes": strings.Join(nodes, ",")},
+		}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+		controllerReconciler = &TypesenseClusterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+	})
+
+	AfterEach(func() {
+		for _, pod := range pods {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &pod))).To(Succeed())
+		}
+		Expect(k8sClient.Delete(ctx, slice)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, cm)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(sts.Name, 3, "1"))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+	})
+
+	Context("When a node replacement is requested", func() {
+		It("should leave an annotated node out of the nodes list", func() {
+			setNodes(
+				tsv1alpha1.NodeStatus{Role: string(LeaderState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(FollowerState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(ErrorState)},
+			)
+			annotate(2)
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+
+			replacement := getNodeReplacementInProgress(ts)
+			Expect(replacement).NotTo(BeNil())
+			Expect(replacement.Pod).To(Equal(pods[2].Name))
+			Expect(replacement.Object).To(Equal(fmt.Sprintf("data-%s", pods[2].Name)))
+			Expect(getNodes()).To(ConsistOf("10.0.0.1:8107:8108", "10.0.0.2:8107:8108"))
+		})
+
+		It("should not replace the leader", func() {
+			setNodes(
+				tsv1alpha1.NodeStatus{Role: string(LeaderState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(FollowerState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(FollowerState), Healthy: true},
+			)
+			annotate(0)
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+
+			Expect(getNodeReplacementInProgress(ts)).To(BeNil())
+			latest := getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, pods[0].Name)
+			Expect(latest.Phase).To(Equal(tsv1alpha1.RemediationFailed))
+			Expect(latest.Message).To(ContainSubstring("leads the quorum"))
+			Expect(getNodes()).To(HaveLen(3))
+		})
+
+		It("should not replace a node when too few healthy nodes would remain", func() {
+			setNodes(
+				tsv1alpha1.NodeStatus{Role: string(LeaderState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(ErrorState)},
+				tsv1alpha1.NodeStatus{Role: string(ErrorState)},
+			)
+			annotate(2)
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+
+			Expect(getNodeReplacementInProgress(ts)).To(BeNil())
+			latest := getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, pods[2].Name)
+			Expect(latest.Phase).To(Equal(tsv1alpha1.RemediationFailed))
+			Expect(latest.Message).To(Equal("only 1 healthy nodes would remain, 2 are required"))
+		})
+
+		It("should replace a node reporting ERROR only when automatic replacement is enabled", func() {
+			setNodes(
+				tsv1alpha1.NodeStatus{Role: string(LeaderState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(FollowerState), Healthy: true},
+				tsv1alpha1.NodeStatus{Role: string(ErrorState)},
+			)
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+			Expect(getNodeReplacementInProgress(ts)).To(BeNil())
+
+			ts.Spec.Remediation = &tsv1alpha1.RemediationSpec{NodeReplacement: &tsv1alpha1.NodeReplacementRemediationSpec{Automatic: true}}
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+			replacement := getNodeReplacementInProgress(ts)
+			Expect(replacement).NotTo(BeNil())
+			Expect(replacement.Pod).To(Equal(pods[2].Name))
+		})
+	})
+
+	Context("When a node replacement is in progress", func() {
+		var pvc *corev1.PersistentVolumeClaim
+
+		BeforeEach(func() {
+			pvc = &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterDataVolumeClaim, pods[2].Name), Namespace: "default"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Mi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			// the protection finalizer would keep the claim of the next test from being created
+			current := &corev1.PersistentVolumeClaim{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), current); err == nil {
+				current.Finalizers = nil
+				Expect(k8sClient.Update(ctx, current)).To(Succeed())
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, current))).To(Succeed())
+			}
+		})
+
+		It("should wait for the other nodes to sync the nodes list before deleting the node", func() {
+			startReplacement(time.Now().Add(-time.Minute))
+			controllerReconciler.Client = &createdAtClient{Client: k8sClient, createdAt: time.Now().Add(-time.Hour)}
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+			Expect(isDeleted(&corev1.Pod{ObjectMeta: pods[2].ObjectMeta})).To(BeFalse())
+			Expect(isDeleted(&corev1.PersistentVolumeClaim{ObjectMeta: pvc.ObjectMeta})).To(BeFalse())
+		})
+
+		It("should delete the data volume and the pod of the node", func() {
+			startReplacement(time.Now().Add(-configMapRequeuePeriod - time.Minute))
+			controllerReconciler.Client = &createdAtClient{Client: k8sClient, createdAt: time.Now().Add(-time.Hour)}
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+			Expect(isDeleted(&corev1.Pod{ObjectMeta: pods[2].ObjectMeta})).To(BeTrue())
+			Expect(isDeleted(&corev1.PersistentVolumeClaim{ObjectMeta: pvc.ObjectMeta})).To(BeTrue())
+			Expect(getNodeReplacementInProgress(ts)).NotTo(BeNil())
+		})
+
+		It("should add the fresh replica back to the nodes list once it runs", func() {
+			startReplacement(time.Now().Add(-configMapRequeuePeriod - time.Minute))
+			Expect(controllerReconciler.updateNodesConfigMap(ctx, ts)).To(Succeed())
+			Expect(getNodes()).To(HaveLen(2))
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+
+			Expect(getNodeReplacementInProgress(ts)).To(BeNil())
+			latest := getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, pods[2].Name)
+			Expect(latest.Phase).To(Equal(tsv1alpha1.RemediationCompleted))
+			Expect(getNodes()).To(HaveLen(3))
+		})
+
+		It("should give up on a node that is not replaced in time", func() {
+			startReplacement(time.Now().Add(-nodeReplacementTimeout - time.Minute))
+
+			Expect(controllerReconciler.ReconcileNodeReplacement(ctx, ts)).To(Succeed())
+
+			Expect(getNodeReplacementInProgress(ts)).To(BeNil())
+			latest := getLatestRemediation(ts, tsv1alpha1.RemediationNodeReplacement, pods[2].Name)
+			Expect(latest.Phase).To(Equal(tsv1alpha1.RemediationFailed))
+			Expect(getNodes()).To(HaveLen(3))
+			Expect(isDeleted(&corev1.PersistentVolumeClaim{ObjectMeta: pvc.ObjectMeta})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Nodes Status", func() {
+	const resourceName = "test-nodes-status"
+
+	ctx := context.Background()
+
+	nodeEndpoints := []NodeEndpoint{
+		{PodName: "test-nodes-status-sts-0", IP: net.ParseIP("10.0.0.1")},
+		{PodName: "test-nodes-status-sts-1", IP: net.ParseIP("10.0.0.2")},
+		{PodName: "test-nodes-status-sts-2", IP: net.ParseIP("10.0.0.3")},
+	}
+
+	var ts *tsv1alpha1.TypesenseCluster
+	var sts *appsv1.StatefulSet
+	var controllerReconciler *TypesenseClusterReconciler
+
+	BeforeEach(func() {
+		controllerReconciler = &TypesenseClusterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		ts = &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec:       tsv1alpha1.TypesenseClusterSpec{Replicas: 3},
+		}
+		Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+		Expect(controllerReconciler.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Image = "typesense/typesense:28.0"
+		})).To(Succeed())
+
+		// only the template of the statefulset is read, it is never created
+		sts = newRolledOutStatefulSet("test-nodes-status-sts", 3, "1")
+		sts.Status.UpdatedReplicas = 3
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+	})
+
+	getStatus := func() tsv1alpha1.TypesenseClusterStatus {
+		current := &tsv1alpha1.TypesenseCluster{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ts), current)).To(Succeed())
+		return current.Status
+	}
+
+	It("should report the probed state of every node", func() {
+		controllerReconciler.reportNodesStatus(ctx, ts, sts, nodeEndpoints,
+			map[string]NodeStatus{
+				nodeEndpoints[0].PodName: {State: FollowerState, CommittedIndex: 41, QueuedWrites: 3},
+				nodeEndpoints[1].PodName: {State: LeaderState, CommittedIndex: 42},
+			},
+			map[string]NodeHealth{
+				nodeEndpoints[0].PodName: {Ok: false, ResourceError: ptr.To(OutOfMemory)},
+				nodeEndpoints[1].PodName: {Ok: true},
+			},
+		)
+
+		status := getStatus()
+		Expect(status.Leader).To(Equal(nodeEndpoints[1].PodName))
+		Expect(status.Image).To(Equal("typesense/typesense:29.0"))
+		Expect(status.Nodes).To(HaveLen(3))
+
+		Expect(status.Nodes[0].Pod).To(Equal(nodeEndpoints[0].PodName))
+		Expect(status.Nodes[0].IP).To(Equal("10.0.0.1"))
+		Expect(status.Nodes[0].Role).To(Equal(string(FollowerState)))
+		Expect(status.Nodes[0].CommittedIndex).To(BeEquivalentTo(41))
+		Expect(status.Nodes[0].QueuedWrites).To(BeEquivalentTo(3))
+		Expect(status.Nodes[0].Healthy).To(BeFalse())
+		Expect(status.Nodes[0].ResourceError).To(Equal(string(OutOfMemory)))
+		Expect(status.Nodes[0].LastProbeTime.IsZero()).To(BeFalse())
+
+		Expect(status.Nodes[1].Role).To(Equal(string(LeaderState)))
+		Expect(status.Nodes[1].Healthy).To(BeTrue())
+		Expect(status.Nodes[1].ResourceError).To(BeEmpty())
+
+		// a node that was not probed has no role
+		Expect(status.Nodes[2].Role).To(BeEmpty())
+		Expect(status.Nodes[2].Healthy).To(BeFalse())
+	})
+
+	It("should not report a leader during a split brain", func() {
+		controllerReconciler.reportNodesStatus(ctx, ts, sts, nodeEndpoints,
+			map[string]NodeStatus{
+				nodeEndpoints[0].PodName: {State: LeaderState},
+				nodeEndpoints[1].PodName: {State: LeaderState},
+				nodeEndpoints[2].PodName: {State: FollowerState},
+			},
+			map[string]NodeHealth{},
+		)
+
+		Expect(getStatus().Leader).To(BeEmpty())
+	})
+
+	It("should keep reporting the previous image until the statefulset is rolled out", func() {
+		sts.Status.UpdatedReplicas = 1
+
+		controllerReconciler.reportNodesStatus(ctx, ts, sts, nodeEndpoints, map[string]NodeStatus{}, map[string]NodeHealth{})
+		Expect(getStatus().Image).To(Equal("typesense/typesense:28.0"))
+	})
+
+	It("should report the generation the conditions were observed at", func() {
+		ts.Generation = 7
+		Expect(controllerReconciler.setConditionReady(ctx, ts, "QuorumReady")).To(Succeed())
+		Expect(getStatus().ObservedGeneration).To(BeEquivalentTo(7))
+	})
+})
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	pausedAnnotationKey = "ts.opentelekomcloud.com/paused"
+)
+
+// ReconcilePaused reports whether the cluster is paused, by spec or by annotation, in the Paused condition and emits
+// an event whenever it is paused or resumed. It returns true while the cluster is paused.
+func (r *TypesenseClusterReconciler) ReconcilePaused(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (bool, error) {
+	paused := isPaused(ts)
+	condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypePaused)
+
+	if !paused && (condition == nil || condition.Status == metav1.ConditionFalse) {
+		return false, nil
+	}
+
+	if paused && condition != nil && condition.Status == metav1.ConditionTrue {
+		return true, nil
+	}
+
+	conditionStatus := metav1.ConditionFalse
+	reason := ConditionReasonReconciliationResumed
+	message := "automated actions are resumed"
+	if paused {
+		conditionStatus = metav1.ConditionTrue
+		reason = ConditionReasonReconciliationPaused
+		message = "automated actions are paused, the cluster is only observed"
+		if !ts.Spec.Paused {
+			message = fmt.Sprintf("%s as long as annotation %s is set", message, pausedAnnotationKey)
+		}
+	}
+
+	err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypePaused, Status: conditionStatus, Reason: reason, Message: message})
+	})
+	if err != nil {
+		return paused, err
+	}
+
+	r.logger.Info(message)
+	r.Recorder.Eventf(ts, "Normal", reason, toTitle(message))
+
+	return paused, nil
+}
+
+// observe probes the nodes and reports their status without creating, updating or deleting anything but the readiness
+// gates of the pods, which keep following the health of the nodes. The raft role labels are left as they are, so the
+// leader and followers services keep their endpoints, and downgrading, upgrading and purging the quorum are skipped by
+// ReconcileQuorum while the cluster is paused or a plan waits for approval.
+func (r *TypesenseClusterReconciler) observe(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (ctrl.Result, error) {
+	var secret = &corev1.Secret{}
+	if err := r.Get(ctx, getAdminApiKeyObjectKey(ts), secret); err != nil {
+		r.logger.V(debugLevel).Info("unable to fetch admin api key while paused", "reason", err.Error())
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, client.IgnoreNotFound(err)
+	}
+
+	stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterStatefulSet, ts.Name)}
+
+	var sts = &appsv1.StatefulSet{}
+	if err := r.Get(ctx, stsObjectKey, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.Info("observing cluster completed, no statefulset found", "requeueAfter", reconcileRequeuePeriod)
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch statefulset: %s", stsObjectKey.Name))
+		return ctrl.Result{}, err
+	}
+
+	err := r.ReconcileScaleStatus(ctx, ts, sts)
+	if err != nil {
+		r.logger.Error(err, "reporting scale status failed")
+	}
+
+	condition, _, err := r.ReconcileQuorum(ctx, ts, secret, stsObjectKey)
+	if err != nil {
+		r.logger.Error(err, "reconciling quorum health failed")
+	}
+
+	if condition == ConditionReasonQuorumReady {
+		if cerr := r.setConditionReady(ctx, ts, string(condition)); cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+	} else {
+		if err == nil {
+			err = fmt.Errorf("quorum is not ready while observing")
+		}
+		if cerr := r.setConditionNotReady(ctx, ts, string(condition), err); cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+	}
+
+	r.logger.Info("observing cluster completed", "condition", condition, "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+func isPaused(ts *tsv1alpha1.TypesenseCluster) bool {
+	return ts.Spec.Paused || ts.Annotations[pausedAnnotationKey] == "true"
+}
+
+// isObserving reports whether the cluster is only observed, because it is paused or a plan waits for approval
+func isObserving(ts *tsv1alpha1.TypesenseCluster) bool {
+	return isPaused(ts) || hasPendingPlan(ts)
+}
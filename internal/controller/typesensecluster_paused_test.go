@@ -0,0 +1,161 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Paused", func() {
+	const resourceName = "test-paused"
+
+	ctx := context.Background()
+
+	DescribeTable("pausing a cluster",
+		func(paused bool, annotations map[string]string, expected bool) {
+			ts := &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Spec:       tsv1alpha1.TypesenseClusterSpec{Paused: paused},
+			}
+			Expect(isPaused(ts)).To(Equal(expected))
+			Expect(isObserving(ts)).To(Equal(expected))
+		},
+		Entry("is not paused by default", false, nil, false),
+		Entry("is paused by spec", true, nil, true),
+		Entry("is paused by annotation", false, map[string]string{pausedAnnotationKey: "true"}, true),
+		Entry("is not paused by an annotation set to false", false, map[string]string{pausedAnnotationKey: "false"}, false),
+	)
+
+	Context("When a cluster is paused and resumed", func() {
+		var ts *tsv1alpha1.TypesenseCluster
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseClusterReconciler
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        resourceName,
+					Namespace:   "default",
+					Annotations: map[string]string{pausedAnnotationKey: "true"},
+				},
+				Spec: tsv1alpha1.TypesenseClusterSpec{Replicas: 3},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should report the paused condition and an event on every transition only", func() {
+			paused, err := controllerReconciler.ReconcilePaused(ctx, ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paused).To(BeTrue())
+
+			condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypePaused)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(ConditionReasonReconciliationPaused))
+			Expect(condition.Message).To(ContainSubstring(pausedAnnotationKey))
+			Expect(recorder.Events).To(HaveLen(1))
+
+			paused, err = controllerReconciler.ReconcilePaused(ctx, ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paused).To(BeTrue())
+			Expect(recorder.Events).To(HaveLen(1))
+
+			delete(ts.Annotations, pausedAnnotationKey)
+
+			paused, err = controllerReconciler.ReconcilePaused(ctx, ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paused).To(BeFalse())
+
+			condition = meta.FindStatusCondition(ts.Status.Conditions, ConditionTypePaused)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(ConditionReasonReconciliationResumed))
+			Expect(recorder.Events).To(HaveLen(2))
+		})
+
+		It("should neither downgrade nor purge the quorum while paused", func() {
+			sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, sts)).To(Succeed())
+			}()
+
+			pod := newStatefulSetPod(sts, 0, sts.Status.CurrentRevision, false)
+			Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, &pod)).To(Succeed())
+			}()
+
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+				Data:       map[string]string{"nodes": "10.0.0.1:8107:8108,10.0.0.2:8107:8108,10.0.0.3:8107:8108"},
+			}
+
+			condition, _, err := controllerReconciler.downgradeQuorum(ctx, ts, cm, client.ObjectKeyFromObject(sts), 0, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumNotReady))
+
+			current := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(sts), current)).To(Succeed())
+			Expect(*current.Spec.Replicas).To(BeEquivalentTo(3))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &corev1.Pod{})).To(Succeed())
+		})
+
+		It("should neither upgrade the quorum nor report it ready while paused", func() {
+			sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 1, "1")
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, sts)).To(Succeed())
+			}()
+
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+				Data:       map[string]string{"nodes": "10.0.0.1:8107:8108"},
+			}
+
+			condition, _, err := controllerReconciler.upgradeQuorum(ctx, ts, cm, client.ObjectKeyFromObject(sts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumNotReady))
+
+			current := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(sts), current)).To(Succeed())
+			Expect(*current.Spec.Replicas).To(BeEquivalentTo(1))
+		})
+	})
+})
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	approvePlanAnnotationKey = "ts.opentelekomcloud.com/approve-plan"
+	// maxPlanDiffLength keeps the status of the cluster within reasonable bounds
+	maxPlanDiffLength = 4096
+)
+
+// ReconcilePlan computes the changes the next reconciliation would apply to the StatefulSet, the nodes ConfigMap, the
+// Services, the Ingress, the HTTPRoutes and the data volumes, and holds them back in plan mode until the plan is
+// approved. A cluster that is still bootstrapping has nothing to review, so its objects are created straight away. It
+// returns true when the changes may be applied.
+func (r *TypesenseClusterReconciler) ReconcilePlan(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (bool, error) {
+	if !ts.Spec.PlanMode {
+		if ts.Status.Plan == nil {
+			return true, nil
+		}
+
+		return true, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Plan = nil
+		})
+	}
+
+	r.logger.V(debugLevel).Info("reconciling plan")
+
+	stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterStatefulSet, ts.Name)}
+
+	var sts = &appsv1.StatefulSet{}
+	if err := r.Get(ctx, stsObjectKey, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch statefulset: %s", stsObjectKey.Name))
+		return false, err
+	}
+
+	changes, triggers, err := r.getPlannedChanges(ctx, ts, sts)
+	if err != nil {
+		return false, err
+	}
+
+	return r.reviewPlan(ctx, ts, changes, triggers)
+}
+
+// reviewPlan reports the planned changes in status and returns true once the approval annotation carries the id of
+// the plan. A plan without changes is cleared, as it has been applied or is not needed anymore.
+func (r *TypesenseClusterReconciler) reviewPlan(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, changes []tsv1alpha1.PlanChange, triggers []string) (bool, error) {
+	if len(changes) == 0 {
+		if ts.Status.Plan == nil {
+			return true, nil
+		}
+
+		if ts.Status.Plan.ApprovedAt != nil {
+			r.logger.Info("plan applied", "plan", ts.Status.Plan.Id)
+			r.Recorder.Eventf(ts, "Normal", "PlanApplied", "Applied plan %s", ts.Status.Plan.Id)
+		}
+
+		return true, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Plan = nil
+		})
+	}
+
+	id := getPlanId(ts, changes, triggers)
+	approved := ts.Annotations[approvePlanAnnotationKey] == id
+
+	if ts.Status.Plan != nil && ts.Status.Plan.Id == id {
+		if !approved || ts.Status.Plan.ApprovedAt != nil {
+			return approved, nil
+		}
+
+		r.logger.Info("plan approved", "plan", id)
+		r.Recorder.Eventf(ts, "Normal", "PlanApproved", "Applying plan %s", id)
+
+		return true, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Plan.ApprovedAt = ptr.To(metav1.Now())
+		})
+	}
+
+	now := metav1.Now()
+	plan := &tsv1alpha1.PlanStatus{
+		Id:         id,
+		Generation: ts.Generation,
+		Triggers:   triggers,
+		Changes:    changes,
+		CreatedAt:  &now,
+	}
+	if approved {
+		plan.ApprovedAt = &now
+	}
+
+	err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Plan = plan
+	})
+	if err != nil {
+		return false, err
+	}
+
+	summary := make([]string, 0, len(changes))
+	for _, change := range changes {
+		summary = append(summary, fmt.Sprintf("%s %s %s", strings.ToLower(string(change.Action)), change.Kind, change.Name))
+	}
+
+	r.logger.Info("plan computed", "plan", id, "changes", summary, "triggers", triggers)
+	r.Recorder.Eventf(ts, "Normal", "PlanPending", "Plan %s will %s; approve it with annotation %s=%s",
+		id, strings.Join(summary, ", "), approvePlanAnnotationKey, id)
+
+	return approved, nil
+}
+
+// getPlannedChanges runs the reconciliation of the planned objects against a client that sends every write as a
+// server side dry run and records its outcome, so the changes are exactly the ones that would be applied. The nodes
+// ConfigMap is only compared for its fallback, as its nodes follow the pod IPs and are not driven by the spec.
+func (r *TypesenseClusterReconciler) getPlannedChanges(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, sts *appsv1.StatefulSet) ([]tsv1alpha1.PlanChange, []string, error) {
+	changes := make([]tsv1alpha1.PlanChange, 0)
+	triggers := make([]string, 0)
+
+	configMapName := fmt.Sprintf(ClusterNodesConfigMap, ts.Name)
+	var cm = &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: configMapName}, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch config map: %s", configMapName))
+			return nil, nil, err
+		}
+	} else {
+		fallback, err := r.getNodes(ctx, ts, ts.Spec.Replicas, true)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if desired := strings.Join(fallback, ","); cm.Data["fallback"] != desired {
+			changes = append(changes, tsv1alpha1.PlanChange{
+				Kind:   "ConfigMap",
+				Name:   configMapName,
+				Action: tsv1alpha1.PlanActionUpdate,
+				Diff:   truncateDiff(diff.Diff(map[string]string{"fallback": cm.Data["fallback"]}, map[string]string{"fallback": desired})),
+			})
+		}
+	}
+
+	pc := &planClient{Client: r.Client}
+	planner := *r
+	planner.Client = pc
+	planner.Recorder = &record.FakeRecorder{}
+	planner.logger = r.logger.WithValues("dryRun", true)
+
+	planned := ts.DeepCopy()
+	if err := planner.ReconcileServices(ctx, *planned); err != nil {
+		return nil, nil, err
+	}
+	if err := planner.ReconcileIngress(ctx, planned); err != nil {
+		return nil, nil, err
+	}
+	if err := planner.ReconcileHttpRoute(ctx, planned); err != nil {
+		return nil, nil, err
+	}
+	// expanding the claims and recreating the statefulset for its new volume claim template are planned as well
+	if _, err := planner.ReconcileStorage(ctx, planned); err != nil {
+		return nil, nil, err
+	}
+	if _, _, err := planner.ReconcileStatefulSet(ctx, planned); err != nil {
+		return nil, nil, err
+	}
+
+	changes = append(changes, pc.changes...)
+
+	for _, change := range pc.changes {
+		if change.Kind != "StatefulSet" {
+			continue
+		}
+
+		desired, err := r.buildStatefulSet(ctx, client.ObjectKeyFromObject(sts), ts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, _, stsTriggers := r.shouldUpdateStatefulSet(sts, desired, ts)
+		for _, trigger := range stsTriggers {
+			triggers = append(triggers, string(trigger))
+		}
+
+		if getImageTag(sts.Spec.Template.Spec.Containers[0].Image) != getImageTag(desired.Spec.Template.Spec.Containers[0].Image) {
+			triggers = append(triggers, string(SpecTypesenseVersionChanged))
+		}
+		break
+	}
+
+	return changes, triggers, nil
+}
+
+// getPlanId identifies the plan by the generation of the spec and the objects it changes, so an approval does not
+// carry over to a plan that changed in the meantime
+func getPlanId(ts *tsv1alpha1.TypesenseCluster, changes []tsv1alpha1.PlanChange, triggers []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", ts.Generation)
+	for _, change := range changes {
+		fmt.Fprintf(&b, ";%s/%s/%s", change.Action, change.Kind, change.Name)
+	}
+	for _, trigger := range triggers {
+		fmt.Fprintf(&b, ";%s", trigger)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(b.String())))[:10]
+}
+
+// hasPendingPlan reports whether a plan waits for approval
+func hasPendingPlan(ts *tsv1alpha1.TypesenseCluster) bool {
+	return ts.Spec.PlanMode && ts.Status.Plan != nil && ts.Annotations[approvePlanAnnotationKey] != ts.Status.Plan.Id
+}
+
+// planClient sends every write as a server side dry run and records the changes it would have made, reads go
+// straight to the underlying client. Status updates are dropped.
+type planClient struct {
+	client.Client
+	changes []tsv1alpha1.PlanChange
+}
+
+func (c *planClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+
+	return c.record(tsv1alpha1.PlanActionCreate, nil, obj)
+}
+
+func (c *planClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	current, err := c.getCurrent(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+
+	return c.record(tsv1alpha1.PlanActionUpdate, current, obj)
+}
+
+func (c *planClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	current, err := c.getCurrent(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+
+	return c.record(tsv1alpha1.PlanActionUpdate, current, obj)
+}
+
+func (c *planClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+
+	return c.record(tsv1alpha1.PlanActionDelete, obj, nil)
+}
+
+func (c *planClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.Client.DeleteAllOf(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *planClient) Apply(ctx context.Context, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+	return c.Client.Apply(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *planClient) Status() client.SubResourceWriter {
+	return planStatusWriter{}
+}
+
+func (c *planClient) getCurrent(ctx context.Context, obj client.Object) (client.Object, error) {
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (c *planClient) record(action tsv1alpha1.PlanAction, current client.Object, desired client.Object) error {
+	obj := desired
+	if obj == nil {
+		obj = current
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	before, err := getPlanView(current)
+	if err != nil {
+		return err
+	}
+
+	after, err := getPlanView(desired)
+	if err != nil {
+		return err
+	}
+
+	if action == tsv1alpha1.PlanActionUpdate && diff.Diff(before, after) == "" {
+		return nil
+	}
+
+	c.changes = append(c.changes, tsv1alpha1.PlanChange{
+		Kind:   gvk.Kind,
+		Name:   obj.GetName(),
+		Action: action,
+		Diff:   truncateDiff(diff.Diff(before, after)),
+	})
+
+	return nil
+}
+
+// getPlanView keeps the labels, the annotations and the spec or data of an object, which is what the plan reviews
+func getPlanView(obj client.Object) (map[string]any, error) {
+	if obj == nil {
+		return map[string]any{}, nil
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	view := map[string]any{
+		"labels":      obj.GetLabels(),
+		"annotations": obj.GetAnnotations(),
+	}
+
+	for key, value := range u {
+		if key != "metadata" && key != "status" && key != "apiVersion" && key != "kind" {
+			view[key] = value
+		}
+	}
+
+	return view, nil
+}
+
+func truncateDiff(d string) string {
+	if len(d) <= maxPlanDiffLength {
+		return d
+	}
+
+	return d[:maxPlanDiffLength] + "\n... truncated"
+}
+
+type planStatusWriter struct{}
+
+func (planStatusWriter) Create(_ context.Context, _ client.Object, _ client.Object, _ ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+func (planStatusWriter) Update(_ context.Context, _ client.Object, _ ...client.SubResourceUpdateOption) error {
+	return nil
+}
+
+func (planStatusWriter) Patch(_ context.Context, _ client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+	return nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster plan mode", func() {
+	ctx := context.Background()
+
+	It("should identify a plan by the generation, the changes and the triggers", func() {
+		ts := &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+		changes := []tsv1alpha1.PlanChange{{Kind: "StatefulSet", Name: "plan-sts", Action: tsv1alpha1.PlanActionUpdate}}
+
+		id := getPlanId(ts, changes, nil)
+		Expect(id).To(HaveLen(10))
+		Expect(getPlanId(ts, changes, nil)).To(Equal(id))
+
+		By("changing the generation")
+		Expect(getPlanId(&tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Generation: 3}}, changes, nil)).NotTo(Equal(id))
+
+		By("changing the changes")
+		more := append(changes, tsv1alpha1.PlanChange{Kind: "Service", Name: "plan-svc", Action: tsv1alpha1.PlanActionCreate})
+		Expect(getPlanId(ts, more, nil)).NotTo(Equal(id))
+
+		By("changing the triggers")
+		Expect(getPlanId(ts, changes, []string{string(SpecTypesenseVersionChanged)})).NotTo(Equal(id))
+	})
+
+	Context("When writing through the plan client", func() {
+		const configMapName = "plan-configmap"
+
+		BeforeEach(func() {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: "default"},
+				Data:       map[string]string{"nodes": "a,b,c"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: "default"}})).To(Succeed())
+		})
+
+		It("should record the changes without applying them", func() {
+			pc := &planClient{Client: k8sClient}
+
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: "default"}, cm)).To(Succeed())
+
+			By("updating without changes")
+			Expect(pc.Update(ctx, cm.DeepCopy())).To(Succeed())
+			Expect(pc.changes).To(BeEmpty())
+
+			By("updating the data")
+			updated := cm.DeepCopy()
+			updated.Data["nodes"] = "a,b"
+			Expect(pc.Update(ctx, updated)).To(Succeed())
+			Expect(pc.changes).To(HaveLen(1))
+			Expect(pc.changes[0].Kind).To(Equal("ConfigMap"))
+			Expect(pc.changes[0].Name).To(Equal(configMapName))
+			Expect(pc.changes[0].Action).To(Equal(tsv1alpha1.PlanActionUpdate))
+			Expect(pc.changes[0].Diff).To(ContainSubstring("a,b"))
+
+			By("creating and deleting")
+			created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName + "-new", Namespace: "default"}}
+			Expect(pc.Create(ctx, created)).To(Succeed())
+			Expect(pc.Delete(ctx, cm.DeepCopy())).To(Succeed())
+			Expect(pc.changes).To(HaveLen(3))
+			Expect(pc.changes[1].Action).To(Equal(tsv1alpha1.PlanActionCreate))
+			Expect(pc.changes[2].Action).To(Equal(tsv1alpha1.PlanActionDelete))
+
+			By("checking that nothing was applied")
+			current := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			Expect(current.Data).To(Equal(cm.Data))
+			Expect(current.DeletionTimestamp).To(BeNil())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(created), &corev1.ConfigMap{}))).To(BeTrue())
+		})
+	})
+
+	Context("When the storage of a cluster in plan mode grows", func() {
+		const resourceName = "test-plan-storage"
+		const storageClassName = "plan-expandable"
+
+		stsName := fmt.Sprintf(ClusterStatefulSet, resourceName)
+		pvcName := fmt.Sprintf("%s-0", fmt.Sprintf(ClusterDataVolumeClaim, stsName))
+
+		var ts *tsv1alpha1.TypesenseCluster
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					PlanMode: true,
+					Storage:  &tsv1alpha1.StorageSpec{Size: resource.MustParse("2Gi"), StorageClassName: storageClassName},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			sc := &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: storageClassName},
+				Provisioner:          "kubernetes.io/no-provisioner",
+				AllowVolumeExpansion: ptr.To(true),
+			}
+			Expect(k8sClient.Create(ctx, sc)).To(Succeed())
+
+			labels := getLabels(ts)
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: stsName, Namespace: "default"},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: ptr.To(int32(1)),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}}},
+					},
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+						ObjectMeta: metav1.ObjectMeta{Name: "data"},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							StorageClassName: ptr.To(storageClassName),
+							Resources: corev1.VolumeResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+							},
+						},
+					}},
+					PersistentVolumeClaimRetentionPolicy: getVolumeClaimRetentionPolicy(ts),
+				},
+			}
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default", Labels: labels},
+				Spec:       sts.Spec.VolumeClaimTemplates[0].Spec,
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			pvc.Status = corev1.PersistentVolumeClaimStatus{
+				Phase:    corev1.ClaimBound,
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			}
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: stsName, Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: storageClassName}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should plan the expansion of the claims and the recreation of the statefulset", func() {
+			pc := &planClient{Client: k8sClient}
+			planner := &TypesenseClusterReconciler{
+				Client:   pc,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := planner.ReconcileStorage(ctx, ts.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+
+			actions := make([]string, 0, len(pc.changes))
+			for _, change := range pc.changes {
+				actions = append(actions, fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.Name))
+			}
+			Expect(actions).To(ConsistOf(
+				fmt.Sprintf("%s PersistentVolumeClaim %s", tsv1alpha1.PlanActionUpdate, pvcName),
+				fmt.Sprintf("%s StatefulSet %s", tsv1alpha1.PlanActionDelete, stsName),
+			))
+
+			By("checking that nothing was applied")
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: pvcName, Namespace: "default"}, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("1Gi")))
+
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: stsName, Namespace: "default"}, sts)).To(Succeed())
+			Expect(sts.DeletionTimestamp).To(BeNil())
+		})
+	})
+
+	Context("When reviewing a plan", func() {
+		const resourceName = "test-plan-review"
+
+		var ts *tsv1alpha1.TypesenseCluster
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       tsv1alpha1.TypesenseClusterSpec{PlanMode: true},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		approve := func(id string) {
+			patch := client.MergeFrom(ts.DeepCopy())
+			ts.Annotations = map[string]string{approvePlanAnnotationKey: id}
+			Expect(k8sClient.Patch(ctx, ts, patch)).To(Succeed())
+		}
+
+		It("should hold back the changes until the plan is approved", func() {
+			controllerReconciler := &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			changes := []tsv1alpha1.PlanChange{{Kind: "StatefulSet", Name: "plan-sts", Action: tsv1alpha1.PlanActionUpdate}}
+			id := getPlanId(ts, changes, nil)
+
+			By("reporting the plan")
+			approved, err := controllerReconciler.reviewPlan(ctx, ts, changes, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(ts.Status.Plan.Id).To(Equal(id))
+			Expect(ts.Status.Plan.ApprovedAt).To(BeNil())
+			Expect(hasPendingPlan(ts)).To(BeTrue())
+
+			By("approving another plan")
+			approve("other")
+			approved, err = controllerReconciler.reviewPlan(ctx, ts, changes, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeFalse())
+
+			By("approving the plan")
+			approve(id)
+			approved, err = controllerReconciler.reviewPlan(ctx, ts, changes, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeTrue())
+			Expect(ts.Status.Plan.ApprovedAt).NotTo(BeNil())
+			Expect(hasPendingPlan(ts)).To(BeFalse())
+
+			By("changing the plan after it was approved")
+			more := append(changes, tsv1alpha1.PlanChange{Kind: "Service", Name: "plan-svc", Action: tsv1alpha1.PlanActionCreate})
+			approved, err = controllerReconciler.reviewPlan(ctx, ts, more, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(ts.Status.Plan.Id).NotTo(Equal(id))
+
+			By("applying all the changes")
+			approved, err = controllerReconciler.reviewPlan(ctx, ts, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(BeTrue())
+			Expect(ts.Status.Plan).To(BeNil())
+		})
+	})
+})
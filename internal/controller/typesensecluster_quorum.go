@@ -9,13 +9,16 @@ import (
 	"strings"
 
 	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	QuorumReadinessGateCondition = "RaftQuorumReady"
+	RaftRoleLabelKey             = "ts.opentelekomcloud.com/raft-role"
 	HealthyWriteLagKey           = "TYPESENSE_HEALTHY_WRITE_LAG"
 	HealthyWriteLagDefaultValue  = 500
 	HealthyReadLagKey            = "TYPESENSE_HEALTHY_READ_LAG"
@@ -36,22 +39,31 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 	}
 
 	r.logger.Info("calculated quorum", "minRequiredNodes", quorum.MinRequiredNodes, "availableNodes", quorum.AvailableNodes)
+	quorumMinRequiredNodes.WithLabelValues(ts.Namespace, ts.Name).Set(float64(quorum.MinRequiredNodes))
+	quorumAvailableNodes.WithLabelValues(ts.Namespace, ts.Name).Set(float64(quorum.AvailableNodes))
 
 	if quorum.AvailableNodes != int(ts.Spec.Replicas) {
 		r.logger.Info("resizing quorum pending", "size", ts.Spec.Replicas)
 	}
 
+	// nothing is scaled, purged or restarted while observing, the nodes are only probed
+	paused := isObserving(ts)
+
 	unscheduledPods, _ := r.GetUnscheduledPods(ctx, sts)
-	if len(unscheduledPods) > 0 {
+	if len(unscheduledPods) > 0 && !paused {
 		_ = r.RestartUnscheduledPods(ctx, unscheduledPods, ts)
 	}
 
 	if quorum.AvailableNodes < quorum.MinRequiredNodes {
+		if !paused {
+			r.reconcilePodRoleLabels(ctx, sts, nil)
+		}
+
 		return ConditionReasonStatefulSetNotReady, 0, nil
 	}
 
 	nodesStatus := make(map[string]NodeStatus)
-	httpClient, err := r.getHttpClient(ts)
+	httpClient, err := r.getHttpClient(ctx, ts)
 	if err != nil {
 		return ConditionReasonQuorumNotReady, 0, err
 	}
@@ -79,7 +91,7 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 	for _, ne := range nodeEndpoints {
 		l, err := r.getPodLogs(ctx, ne, ts.Namespace)
 		if err != nil {
-			r.logger.Error(err, "fetching pod logs failed", "node", r.getShortName(ne.PodName), "ip", ne.IP)
+			r.logger.Error(err, "fetching pod logs failed", "node", getShortName(ne.PodName), "ip", ne.IP)
 		}
 
 		logs[ne.PodName] = l
@@ -89,7 +101,7 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 	for _, ne := range nodeEndpoints {
 		status, err := r.getNodeStatus(ctx, httpClient, ne, ts, secret, logs[ne.PodName])
 		if err != nil {
-			r.logger.Error(err, "fetching node status failed", "node", r.getShortName(ne.PodName), "ip", ne.IP)
+			r.logger.Error(err, "fetching node status failed", "node", getShortName(ne.PodName), "ip", ne.IP)
 		}
 
 		if status.QueuedWrites > 0 && queuedWrites < status.QueuedWrites {
@@ -99,7 +111,7 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 		r.logger.V(debugLevel).Info(
 			"reporting node status",
 			"node",
-			r.getShortName(ne.PodName),
+			getShortName(ne.PodName),
 			"state",
 			status.State,
 			"ip",
@@ -112,10 +124,42 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 		nodesStatus[ne.PodName] = status
 	}
 
+	quorumMaxQueuedWrites.WithLabelValues(ts.Namespace, ts.Name).Set(float64(queuedWrites))
+	quorumHealthyWriteLagThreshold.WithLabelValues(ts.Namespace, ts.Name).Set(float64(healthyWriteLagThreshold))
+
+	if !paused {
+		r.reconcilePodRoleLabels(ctx, sts, nodesStatus)
+	}
+
+	// nodes whose certificate cannot be verified may be perfectly healthy, so none is purged, downgraded or recovered
+	// until the operator trusts them again
+	if untrusted := getUntrustedNodes(nodesStatus); len(untrusted) > 0 {
+		r.reportNodesStatus(ctx, ts, sts, nodeEndpoints, nodesStatus, nil)
+
+		err := fmt.Errorf("certificate of %s could not be verified with the CA in secret %s", strings.Join(untrusted, ", "), getTLSSecretName(ts))
+		return ConditionQuorum(ConditionReasonCertificateNotReady), 0, err
+	}
+
 	clusterStatus := r.getClusterStatus(nodesStatus)
 	r.logger.V(debugLevel).Info("reporting cluster status", "status", clusterStatus)
 
+	nodesHealthReport := make(map[string]NodeHealth, len(nodeEndpoints))
+	defer r.reportNodesStatus(ctx, ts, sts, nodeEndpoints, nodesStatus, nodesHealthReport)
+
+	// a restoring cluster is still bootstrapping, its nodes must not be downgraded or purged while seeding the volumes
+	restoring := ts.Status.IsRestoring()
+
+	if recovery := getQuorumRecoveryInProgress(ts); recovery != nil && !paused {
+		return r.continueQuorumRecovery(ctx, ts, quorum.NodesListConfigMap, sts, httpClient, secret, nodeEndpoints, nodesStatus, *recovery)
+	}
+
 	if clusterStatus == ClusterStatusSplitBrain {
+		quorumSplitBrainsTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
+
+		if restoring {
+			return ConditionReasonQuorumRestoring, 0, nil
+		}
+
 		hbv, err := r.hasBootstrapValues(ts, quorum.NodesListConfigMap)
 		if err != nil {
 			return ConditionReasonQuorumNotReady, 0, err
@@ -125,7 +169,7 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 			return ConditionReasonQuorumNotReadyWaitATerm, 0, nil
 		}
 
-		return r.downgradeQuorum(ctx, ts, quorum.NodesListConfigMap, stsObjectKey, sts.Status.ReadyReplicas, int32(quorum.MinRequiredNodes))
+		return r.recoverQuorum(ctx, ts, quorum.NodesListConfigMap, stsObjectKey, clusterStatus, nodeEndpoints, nodesStatus, sts.Status.ReadyReplicas, int32(quorum.MinRequiredNodes))
 	}
 
 	clusterNeedsAttention := false
@@ -135,7 +179,8 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 		key := ne.PodName
 		nodeStatus := nodesStatus[key]
 
-		condition := r.calculatePodReadinessGate(ctx, httpClient, ne, nodeStatus, ts, logs[key])
+		condition, health := r.calculatePodReadinessGate(ctx, httpClient, ne, nodeStatus, ts, logs[key])
+		nodesHealthReport[key] = health
 		if condition.Reason == string(nodeNotRecoverable) {
 			clusterNeedsAttention = true
 		}
@@ -147,7 +192,7 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 		podName := fmt.Sprintf("%s-%s", podPrefix, podIndex)
 		podObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: podName}
 
-		err = r.updatePodReadinessGate(ctx, podObjectKey, condition)
+		err = r.updatePodReadinessGate(ctx, ts, podObjectKey, condition)
 		if err != nil {
 			r.logger.Error(err, fmt.Sprintf("unable to update statefulset pod: %s", podObjectKey.Name))
 			return ConditionReasonQuorumNotReady, 0, err
@@ -169,12 +214,17 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 	}
 
 	r.logger.Info("evaluated quorum", "minRequiredNodes", minRequiredNodes, "availableNodes", availableNodes, "healthyNodes", healthyNodes)
+	quorumHealthyNodes.WithLabelValues(ts.Namespace, ts.Name).Set(float64(healthyNodes))
 
 	if (queuedWrites > healthyWriteLagThreshold) && healthyNodes > 0 {
 		return ConditionReasonQuorumNeedsAttentionClusterIsLagging, 0, nil
 	}
 
 	if clusterStatus == ClusterStatusElectionDeadlock {
+		if restoring {
+			return ConditionReasonQuorumRestoring, 0, nil
+		}
+
 		hbv, err := r.hasBootstrapValues(ts, quorum.NodesListConfigMap)
 		if err != nil {
 			return ConditionReasonQuorumNotReady, 0, err
@@ -184,16 +234,20 @@ func (r *TypesenseClusterReconciler) ReconcileQuorum(ctx context.Context, ts *ts
 			return ConditionReasonQuorumNotReadyWaitATerm, 0, nil
 		}
 
-		return r.downgradeQuorum(ctx, ts, quorum.NodesListConfigMap, stsObjectKey, int32(healthyNodes), int32(minRequiredNodes))
+		return r.recoverQuorum(ctx, ts, quorum.NodesListConfigMap, stsObjectKey, clusterStatus, nodeEndpoints, nodesStatus, int32(healthyNodes), int32(minRequiredNodes))
 	}
 
 	if clusterStatus == ClusterStatusNotReady {
+		if restoring {
+			return ConditionReasonQuorumRestoring, 0, nil
+		}
+
 		if availableNodes == 1 {
 			podName := fmt.Sprintf("%s-%d", fmt.Sprintf(ClusterStatefulSet, ts.Name), 0)
 			nodeStatus := nodesStatus[podName]
 			state := nodeStatus.State
 
-			if state == ErrorState || state == UnreachableState {
+			if (state == ErrorState || state == UnreachableState) && !paused {
 				r.logger.Info("purging quorum")
 				err := r.PurgeStatefulSetPods(ctx, sts, ts)
 				if err != nil {
@@ -239,7 +293,13 @@ func (r *TypesenseClusterReconciler) downgradeQuorum(
 	healthyNodes, minRequiredNodes int32,
 ) (ConditionQuorum, int, error) {
 	//r.logger.Info("downgrading quorum")
+	if isObserving(ts) {
+		r.logger.Info("skipping quorum downgrade while observing")
+		return ConditionReasonQuorumNotReady, 0, nil
+	}
+
 	r.logger.V(debugLevel).Info("scaling statefulset", "sts", stsObjectKey.Name, "triggers", QuorumDowngraded)
+	quorumDowngradesTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
 
 	sts, err := r.GetFreshStatefulSet(ctx, stsObjectKey)
 	if err != nil {
@@ -281,7 +341,13 @@ func (r *TypesenseClusterReconciler) upgradeQuorum(
 	stsObjectKey client.ObjectKey,
 ) (ConditionQuorum, int, error) {
 	//r.logger.Info("upgrading quorum", "incremental", ts.Spec.IncrementalQuorumRecovery)
+	if isObserving(ts) {
+		r.logger.Info("skipping quorum upgrade while observing")
+		return ConditionReasonQuorumNotReady, 0, nil
+	}
+
 	r.logger.V(debugLevel).Info("scaling statefulset", "sts", stsObjectKey.Name, "triggers", QuorumUpgraded, "incremental", ts.Spec.IncrementalQuorumRecovery)
+	quorumUpgradesTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
 
 	sts, err := r.GetFreshStatefulSet(ctx, stsObjectKey)
 	if err != nil {
@@ -317,7 +383,7 @@ const (
 	nodeNotRecoverable readinessGateReason = "NodeNotRecoverable"
 )
 
-func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Context, httpClient *http.Client, node NodeEndpoint, nodeStatus NodeStatus, ts *tsv1alpha1.TypesenseCluster, logs string) *v1.PodCondition {
+func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Context, httpClient *http.Client, node NodeEndpoint, nodeStatus NodeStatus, ts *tsv1alpha1.TypesenseCluster, logs string) (*v1.PodCondition, NodeHealth) {
 	conditionReason := nodeHealthy
 	conditionMessage := fmt.Sprintf("node's role is now: %s", nodeStatus.State)
 	conditionStatus := v1.ConditionTrue
@@ -327,7 +393,7 @@ func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Conte
 		conditionReason = nodeNotHealthy
 		conditionStatus = v1.ConditionFalse
 
-		r.logger.Error(err, "fetching node health failed", "node", r.getShortName(node.PodName), "ip", node.IP)
+		r.logger.Error(err, "fetching node health failed", "node", getShortName(node.PodName), "ip", node.IP)
 	} else {
 		if !health.Ok {
 			if health.ResourceError != nil && (*health.ResourceError == OutOfMemory || *health.ResourceError == OutOfDisk) {
@@ -335,7 +401,7 @@ func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Conte
 				conditionMessage = fmt.Sprintf("node is failing: %s", string(*health.ResourceError))
 				conditionStatus = v1.ConditionFalse
 
-				err := fmt.Errorf("health check reported a blocking node error on %s: %s", r.getShortName(node.PodName), string(*health.ResourceError))
+				err := fmt.Errorf("health check reported a blocking node error on %s: %s", getShortName(node.PodName), string(*health.ResourceError))
 				r.logger.Error(err, "quorum cannot be recovered automatically")
 			}
 
@@ -344,7 +410,7 @@ func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Conte
 		}
 	}
 
-	r.logger.V(debugLevel).Info("reporting node health", "node", r.getShortName(node.PodName), "healthy", health.Ok, "ip", node.IP)
+	r.logger.V(debugLevel).Info("reporting node health", "node", getShortName(node.PodName), "healthy", health.Ok, "ip", node.IP)
 	condition := &v1.PodCondition{
 		Type:    QuorumReadinessGateCondition,
 		Status:  conditionStatus,
@@ -352,10 +418,10 @@ func (r *TypesenseClusterReconciler) calculatePodReadinessGate(ctx context.Conte
 		Message: conditionMessage,
 	}
 
-	return condition
+	return condition, health
 }
 
-func (r *TypesenseClusterReconciler) updatePodReadinessGate(ctx context.Context, podObjectKey client.ObjectKey, condition *v1.PodCondition) error {
+func (r *TypesenseClusterReconciler) updatePodReadinessGate(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, podObjectKey client.ObjectKey, condition *v1.PodCondition) error {
 	pod := &v1.Pod{}
 	err := r.Get(ctx, podObjectKey, pod)
 	if err != nil {
@@ -370,6 +436,10 @@ func (r *TypesenseClusterReconciler) updatePodReadinessGate(ctx context.Context,
 	for _, c := range pod.Status.Conditions {
 		if c.Type == condition.Type {
 			if !found {
+				if c.Status != condition.Status {
+					readinessGateTransitionsTotal.WithLabelValues(ts.Namespace, ts.Name, string(condition.Status)).Inc()
+				}
+
 				updatedConditions = append(updatedConditions, *condition)
 				found = true
 			}
@@ -391,3 +461,52 @@ func (r *TypesenseClusterReconciler) updatePodReadinessGate(ctx context.Context,
 	//r.logger.V(debugLevel).Info("updating pod readiness gate condition", "pod", pod.Name, "condition", condition.Type, "conditionStatus", condition.Status)
 	return nil
 }
+
+// reconcilePodRoleLabels labels the pods with the raft role of their node, the leader and followers services select
+// on it. The label is removed from the pods whose role is unknown, because they were not probed or their certificate
+// could not be verified, so the services never select a node by a role it no longer has.
+func (r *TypesenseClusterReconciler) reconcilePodRoleLabels(ctx context.Context, sts *appsv1.StatefulSet, nodesStatus map[string]NodeStatus) {
+	var pods v1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     sts.Namespace,
+		LabelSelector: labels.SelectorFromSet(sts.Spec.Selector.MatchLabels),
+	}); err != nil {
+		r.logger.Error(err, "failed to list pods", "statefulset", sts.Name)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		role := ""
+		if status, ok := nodesStatus[pod.Name]; ok && status.State != UntrustedState {
+			role = strings.ToLower(string(status.State))
+		}
+
+		if err := r.updatePodRoleLabel(ctx, &pod, role); err != nil {
+			r.logger.Error(err, "updating pod raft role label failed", "node", getShortName(pod.Name))
+		}
+	}
+}
+
+// updatePodRoleLabel sets the raft role label of the pod, or removes it if the role is empty
+func (r *TypesenseClusterReconciler) updatePodRoleLabel(ctx context.Context, pod *v1.Pod, role string) error {
+	if pod.Labels[RaftRoleLabelKey] == role {
+		return nil
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if role == "" {
+		delete(pod.Labels, RaftRoleLabelKey)
+	} else {
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[RaftRoleLabelKey] = role
+	}
+
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	r.logger.V(debugLevel).Info("updating pod raft role label", "pod", pod.Name, "role", role)
+	return nil
+}
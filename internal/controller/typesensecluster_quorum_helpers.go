@@ -2,12 +2,14 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,14 +17,20 @@ import (
 	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 func (r *TypesenseClusterReconciler) getNodeStatus(ctx context.Context, httpClient *http.Client, node NodeEndpoint, ts *tsv1alpha1.TypesenseCluster, secret *v1.Secret, logs string) (NodeStatus, error) {
+	defer func(start time.Time) {
+		nodeStatusProbeDuration.WithLabelValues(ts.Namespace, ts.Name).Observe(time.Since(start).Seconds())
+	}(time.Now())
+
 	u, err := r.buildUrl(node, ts, ts.Spec.ApiPort, "/status")
 	if err != nil {
 		return NodeStatus{State: UnreachableState}, nil
@@ -40,6 +48,9 @@ func (r *TypesenseClusterReconciler) getNodeStatus(ctx context.Context, httpClie
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		r.logger.Error(err, "request failed")
+		if isCertificateVerificationError(err) {
+			return NodeStatus{State: UntrustedState}, nil
+		}
 		return NodeStatus{State: UnreachableState}, nil
 	}
 	defer resp.Body.Close()
@@ -71,6 +82,79 @@ func (r *TypesenseClusterReconciler) getNodeStatus(ctx context.Context, httpClie
 	return nodeStatus, nil
 }
 
+// getUntrustedNodes returns the nodes whose certificate could not be verified, sorted by name
+func getUntrustedNodes(nodesStatus map[string]NodeStatus) []string {
+	untrusted := make([]string, 0)
+	for pod, status := range nodesStatus {
+		if status.State == UntrustedState {
+			untrusted = append(untrusted, getShortName(pod))
+		}
+	}
+
+	sort.Strings(untrusted)
+	return untrusted
+}
+
+// reportNodesStatus persists the probed state of every node in the status, so the topology of the cluster is visible
+// without reading the operator logs
+func (r *TypesenseClusterReconciler) reportNodesStatus(
+	ctx context.Context,
+	ts *tsv1alpha1.TypesenseCluster,
+	sts *appsv1.StatefulSet,
+	nodeEndpoints []NodeEndpoint,
+	nodesStatus map[string]NodeStatus,
+	nodesHealth map[string]NodeHealth,
+) {
+	now := metav1.Now()
+	leaders := make([]string, 0, 1)
+	nodes := make([]tsv1alpha1.NodeStatus, 0, len(nodeEndpoints))
+
+	for _, ne := range nodeEndpoints {
+		status := nodesStatus[ne.PodName]
+		node := tsv1alpha1.NodeStatus{
+			Pod:            ne.PodName,
+			IP:             ne.IP.String(),
+			Role:           string(status.State),
+			CommittedIndex: int64(status.CommittedIndex),
+			QueuedWrites:   int64(status.QueuedWrites),
+			LastProbeTime:  now,
+		}
+
+		if health, ok := nodesHealth[ne.PodName]; ok {
+			node.Healthy = health.Ok
+			if health.ResourceError != nil {
+				node.ResourceError = string(*health.ResourceError)
+			}
+		}
+
+		if status.State == LeaderState {
+			leaders = append(leaders, ne.PodName)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	// a split brain has no single leader to report
+	leader := ""
+	if len(leaders) == 1 {
+		leader = leaders[0]
+	}
+
+	image := ts.Status.Image
+	if sts.Status.ObservedGeneration == sts.Generation && sts.Status.UpdatedReplicas == sts.Status.Replicas {
+		image = getTypesenseContainerImage(&v1.Pod{Spec: sts.Spec.Template.Spec})
+	}
+
+	err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Nodes = nodes
+		status.Leader = leader
+		status.Image = image
+	})
+	if err != nil {
+		r.logger.Error(err, "reporting nodes status failed")
+	}
+}
+
 func (r *TypesenseClusterReconciler) getClusterStatus(nodesStatus map[string]NodeStatus) ClusterStatus {
 	leaderNodes := 0
 	notReadyNodes := 0
@@ -295,41 +379,73 @@ func (r *TypesenseClusterReconciler) getHealthyLagThresholds(ctx context.Context
 	return
 }
 
-func (r *TypesenseClusterReconciler) getHttpClient(ts *tsv1alpha1.TypesenseCluster) (*http.Client, error) {
-	if r.InCluster {
-		return &http.Client{
-			Timeout: time.Duration(ts.Spec.HealthProbeTimeoutInMilliseconds) * time.Millisecond,
-		}, nil
+func (r *TypesenseClusterReconciler) getHttpClient(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*http.Client, error) {
+	tlsConfig, err := newTLSClientConfig(ctx, r.Client, ts)
+	if err != nil {
+		r.logger.Error(err, "failed to build tls client configuration")
+		return nil, err
 	}
 
-	restConfig := rest.CopyConfig(r.Configuration)
-	httpClient, err := rest.HTTPClientFor(restConfig)
+	timeout := time.Duration(ts.Spec.HealthProbeTimeoutInMilliseconds) * time.Millisecond
+	httpClient, err := newHttpClient(r.Configuration, r.InCluster, timeout, tlsConfig)
 	if err != nil {
 		r.logger.Error(err, "failed to build kubernetes http client: %v")
 		return nil, err
 	}
 
-	httpClient.Timeout = time.Duration(ts.Spec.HealthProbeTimeoutInMilliseconds) * time.Millisecond
-	r.logger.V(debugLevel).Info("fallback to kube-proxy for http calls")
+	if !r.InCluster {
+		r.logger.V(debugLevel).Info("fallback to kube-proxy for http calls")
+	}
 
 	return httpClient, nil
 }
 
 func (r *TypesenseClusterReconciler) buildUrl(node NodeEndpoint, ts *tsv1alpha1.TypesenseCluster, port int, path string) (string, error) {
-	if !r.InCluster {
-		req := r.ClientSet.CoreV1().RESTClient().
+	return buildNodeUrl(r.ClientSet, r.InCluster, node, ts, port, path)
+}
+
+// newHttpClient returns a client that reaches the nodes directly when running in-cluster, trusting the certificate
+// of the cluster if tlsConfig is set, or through the pods proxy of the api server otherwise
+func newHttpClient(config *rest.Config, inCluster bool, timeout time.Duration, tlsConfig *tls.Config) (*http.Client, error) {
+	if inCluster {
+		httpClient := &http.Client{
+			Timeout: timeout,
+		}
+
+		if tlsConfig != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = tlsConfig
+			httpClient.Transport = transport
+		}
+
+		return httpClient, nil
+	}
+
+	restConfig := rest.CopyConfig(config)
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient.Timeout = timeout
+	return httpClient, nil
+}
+
+func buildNodeUrl(clientSet *kubernetes.Clientset, inCluster bool, node NodeEndpoint, ts *tsv1alpha1.TypesenseCluster, port int, path string) (string, error) {
+	if !inCluster {
+		req := clientSet.CoreV1().RESTClient().
 			Get().
 			Namespace(ts.Namespace).
 			Resource("pods").
-			Name(fmt.Sprintf("%s:%d", r.getShortName(node.PodName), port)).
+			Name(fmt.Sprintf("%s:%s:%d", ts.Spec.GetApiScheme(), getShortName(node.PodName), port)).
 			SubResource("proxy").
 			Suffix(strings.TrimPrefix(path, "/"))
 
 		return req.URL().String(), nil
 	}
 
-	host := r.getNodeEndpoint(ts, node.IP.String())
-	return url.JoinPath(fmt.Sprintf("http://%s:%d", host, port), path)
+	host := getNodeEndpoint(ts, node.IP.String())
+	return url.JoinPath(fmt.Sprintf("%s://%s:%d", ts.Spec.GetApiScheme(), host, port), path)
 }
 
 func (r *TypesenseClusterReconciler) getPodLogs(ctx context.Context, node NodeEndpoint, namespace string) (string, error) {
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// quorumRecoveryTimeout after which a survivor that does not lead is given up on, and the quorum is downgraded
+	quorumRecoveryTimeout = 10 * time.Minute
+)
+
+// recoverQuorum recovers from a split brain or an election deadlock without losing the writes that only some nodes
+// have. The node with the highest committed index survives: the nodes list is rewritten to a single node configuration
+// pointing at it, and the quorum is regrown from it by continueQuorumRecovery once it leads. The quorum is downgraded
+// to the first node, as before, if no node reports its committed index.
+func (r *TypesenseClusterReconciler) recoverQuorum(
+	ctx context.Context,
+	ts *tsv1alpha1.TypesenseCluster,
+	cm *v1.ConfigMap,
+	stsObjectKey client.ObjectKey,
+	clusterStatus ClusterStatus,
+	nodeEndpoints []NodeEndpoint,
+	nodesStatus map[string]NodeStatus,
+	healthyNodes, minRequiredNodes int32,
+) (ConditionQuorum, int, error) {
+	if isObserving(ts) {
+		r.logger.Info("skipping quorum recovery while observing")
+		return ConditionReasonQuorumNotReady, 0, nil
+	}
+
+	survivor, ok := getQuorumSurvivor(nodeEndpoints, nodesStatus)
+	if !ok {
+		return r.downgradeQuorum(ctx, ts, cm, stsObjectKey, healthyNodes, minRequiredNodes)
+	}
+
+	committedIndex := nodesStatus[survivor.PodName].CommittedIndex
+	r.logger.Info("recovering quorum from survivor", "status", clusterStatus, "survivor", survivor.PodName, "committedIndex", committedIndex)
+	quorumDowngradesTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
+
+	desired := cm.DeepCopy()
+	desired.Data["nodes"] = fmt.Sprintf("%s:%d:%d", survivor.IP.String(), ts.Spec.PeeringPort, ts.Spec.ApiPort)
+	if err := r.Update(ctx, desired); err != nil {
+		r.logger.Error(err, "updating quorum configuration failed")
+		return ConditionReasonQuorumNotReady, 0, err
+	}
+
+	_ = r.forcePodsConfigMapUpdate(ctx, ts)
+
+	// the other nodes restart outside of the configuration, so none of them keeps competing for leadership
+	if err := r.deleteNodePods(ctx, ts, nodeEndpoints, survivor.PodName); err != nil {
+		return ConditionReasonQuorumNotReady, 0, err
+	}
+
+	remediation := tsv1alpha1.RemediationStatus{
+		Type:           tsv1alpha1.RemediationQuorumRecovery,
+		Pod:            survivor.PodName,
+		Phase:          tsv1alpha1.RemediationInProgress,
+		Object:         cm.Name,
+		CommittedIndex: int64(committedIndex),
+		Message:        fmt.Sprintf("recovering quorum from %s at committed index %d after %s", survivor.PodName, committedIndex, clusterStatus),
+		StartedAt:      ptr.To(metav1.Now()),
+	}
+	if err := r.updateRemediation(ctx, ts, remediation); err != nil {
+		return ConditionReasonQuorumNotReady, 0, err
+	}
+
+	r.Recorder.Eventf(ts, "Warning", "QuorumRecoveryStarted", toTitle(remediation.Message))
+	return ConditionReasonQuorumDowngraded, 1, nil
+}
+
+// continueQuorumRecovery waits for the survivor to lead its single node configuration, resetting its peers once the
+// kubelet had the time to sync the nodes list, and regrows the quorum from it: the nodes list is restored, and the
+// other nodes are added back by the survivor and catch up with it as followers.
+func (r *TypesenseClusterReconciler) continueQuorumRecovery(
+	ctx context.Context,
+	ts *tsv1alpha1.TypesenseCluster,
+	cm *v1.ConfigMap,
+	sts *appsv1.StatefulSet,
+	httpClient *http.Client,
+	secret *v1.Secret,
+	nodeEndpoints []NodeEndpoint,
+	nodesStatus map[string]NodeStatus,
+	recovery tsv1alpha1.RemediationStatus,
+) (ConditionQuorum, int, error) {
+	survivorStatus, ok := nodesStatus[recovery.Pod]
+	if !ok {
+		return ConditionReasonQuorumNotReady, 0, r.failRemediation(ctx, ts, recovery, nil, fmt.Sprintf("survivor %s is gone", recovery.Pod))
+	}
+
+	elapsed := time.Since(recovery.StartedAt.Time)
+	if elapsed < configMapRequeuePeriod {
+		r.logger.Info("waiting for survivor to sync its quorum configuration", "survivor", recovery.Pod)
+		return ConditionReasonQuorumNotReadyWaitATerm, 0, nil
+	}
+
+	if survivorStatus.State != LeaderState {
+		if elapsed > quorumRecoveryTimeout {
+			return ConditionReasonQuorumNotReady, 0, r.failRemediation(ctx, ts, recovery, nil, fmt.Sprintf("survivor %s did not become leader within %s", recovery.Pod, quorumRecoveryTimeout))
+		}
+
+		for _, ne := range nodeEndpoints {
+			if ne.PodName != recovery.Pod {
+				continue
+			}
+
+			if err := r.resetPeers(ctx, httpClient, ne, ts, secret); err != nil {
+				r.logger.Error(err, "resetting peers of survivor failed", "node", getShortName(ne.PodName))
+			}
+		}
+
+		r.logger.Info("waiting for survivor to become leader", "survivor", recovery.Pod, "state", survivorStatus.State)
+		return ConditionReasonQuorumNotReadyWaitATerm, 0, nil
+	}
+
+	r.logger.Info("regrowing quorum from survivor", "survivor", recovery.Pod, "size", ptr.Deref(sts.Spec.Replicas, ts.Spec.Replicas))
+	quorumUpgradesTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
+
+	// the recovery is completed first, the nodes list would otherwise keep pointing at the survivor only
+	recovery.Phase = tsv1alpha1.RemediationCompleted
+	recovery.Message = fmt.Sprintf("regrew quorum from %s at committed index %d", recovery.Pod, recovery.CommittedIndex)
+	recovery.CompletedAt = ptr.To(metav1.Now())
+	if err := r.updateRemediation(ctx, ts, recovery); err != nil {
+		return ConditionReasonQuorumNotReady, 0, err
+	}
+
+	_, size, _, err := r.updateConfigMap(ctx, ts, cm, sts.Spec.Replicas, true)
+	if err != nil {
+		return ConditionReasonQuorumNotReady, 0, err
+	}
+
+	_ = r.forcePodsConfigMapUpdate(ctx, ts)
+
+	r.Recorder.Eventf(ts, "Normal", "QuorumRecoveryCompleted", toTitle(recovery.Message))
+	return ConditionReasonQuorumUpgraded, size, nil
+}
+
+func (r *TypesenseClusterReconciler) deleteNodePods(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, nodeEndpoints []NodeEndpoint, survivor string) error {
+	for _, ne := range nodeEndpoints {
+		if ne.PodName == survivor {
+			continue
+		}
+
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ts.Namespace, Name: ne.PodName}}
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "failed to delete pod", "pod", ne.PodName)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resetPeers makes the node reload the nodes list, and start an election within it
+func (r *TypesenseClusterReconciler) resetPeers(ctx context.Context, httpClient *http.Client, node NodeEndpoint, ts *tsv1alpha1.TypesenseCluster, secret *v1.Secret) error {
+	u, err := r.buildUrl(node, ts, ts.Spec.ApiPort, "/operations/reset_peers")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return err
+	}
+
+	apiKey := secret.Data[ClusterAdminApiKeySecretKeyName]
+	req.Header.Set("x-typesense-api-key", string(apiKey))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("reset peers on %s returned status code %d", getShortName(node.PodName), resp.StatusCode)
+	}
+
+	r.logger.V(debugLevel).Info("reset peers", "node", getShortName(node.PodName))
+	return nil
+}
+
+// getQuorumSurvivor returns the reachable node with the highest committed index, the first one on a tie
+func getQuorumSurvivor(nodeEndpoints []NodeEndpoint, nodesStatus map[string]NodeStatus) (NodeEndpoint, bool) {
+	var survivor NodeEndpoint
+	found := false
+	committedIndex := 0
+
+	for _, ne := range nodeEndpoints {
+		status, ok := nodesStatus[ne.PodName]
+		if !ok || status.State == UnreachableState || status.CommittedIndex <= 0 {
+			continue
+		}
+
+		if !found || status.CommittedIndex > committedIndex {
+			survivor = ne
+			committedIndex = status.CommittedIndex
+			found = true
+		}
+	}
+
+	return survivor, found
+}
+
+func getQuorumRecoveryInProgress(ts *tsv1alpha1.TypesenseCluster) *tsv1alpha1.RemediationStatus {
+	for i, remediation := range ts.Status.Remediations {
+		if remediation.Type == tsv1alpha1.RemediationQuorumRecovery && remediation.Phase == tsv1alpha1.RemediationInProgress {
+			return &ts.Status.Remediations[i]
+		}
+	}
+
+	return nil
+}
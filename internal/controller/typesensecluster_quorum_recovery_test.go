@@ -0,0 +1,330 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Quorum Recovery", func() {
+	ctx := context.Background()
+
+	nodeEndpoints := []NodeEndpoint{
+		{PodName: "node-0", IP: net.ParseIP("10.0.0.1")},
+		{PodName: "node-1", IP: net.ParseIP("10.0.0.2")},
+		{PodName: "node-2", IP: net.ParseIP("10.0.0.3")},
+	}
+
+	DescribeTable("choosing the survivor",
+		func(nodesStatus map[string]NodeStatus, expected string) {
+			survivor, ok := getQuorumSurvivor(nodeEndpoints, nodesStatus)
+			Expect(ok).To(Equal(expected != ""))
+			Expect(survivor.PodName).To(Equal(expected))
+		},
+		Entry("the node with the highest committed index survives",
+			map[string]NodeStatus{
+				"node-0": {State: FollowerState, CommittedIndex: 5},
+				"node-1": {State: CandidateState, CommittedIndex: 9},
+				"node-2": {State: FollowerState, CommittedIndex: 7},
+			}, "node-1"),
+		Entry("the first node survives a tie",
+			map[string]NodeStatus{
+				"node-0": {State: FollowerState, CommittedIndex: 5},
+				"node-1": {State: CandidateState, CommittedIndex: 9},
+				"node-2": {State: FollowerState, CommittedIndex: 9},
+			}, "node-1"),
+		Entry("an unreachable node does not survive",
+			map[string]NodeStatus{
+				"node-0": {State: UnreachableState, CommittedIndex: 12},
+				"node-1": {State: FollowerState, CommittedIndex: 3},
+			}, "node-1"),
+		Entry("a node without a committed index does not survive",
+			map[string]NodeStatus{
+				"node-0": {State: FollowerState},
+				"node-1": {State: NotReadyState},
+				"node-2": {State: UnreachableState, CommittedIndex: 4},
+			}, ""),
+	)
+
+	Context("When the quorum is recovered from a survivor", func() {
+		const resourceName = "test-quorum-recovery"
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var sts *appsv1.StatefulSet
+		var cm *corev1.ConfigMap
+		var slice *discoveryv1.EndpointSlice
+		var pods []corev1.Pod
+		var endpoints []NodeEndpoint
+		var server *httptest.Server
+		var resets []string
+		var mu sync.Mutex
+		var controllerReconciler *TypesenseClusterReconciler
+
+		getNodes := func() string {
+			current := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			return current.Data["nodes"]
+		}
+
+		getResets := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string{}, resets...)
+		}
+
+		BeforeEach(func() {
+			// every node listens on its own loopback address, so a reset of the peers is traced back to its node
+			resets = nil
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && r.URL.Path == "/operations/reset_peers" {
+					host, _, err := net.SplitHostPort(r.Host)
+					Expect(err).NotTo(HaveOccurred())
+
+					mu.Lock()
+					resets = append(resets, host)
+					mu.Unlock()
+				}
+
+				writeJson(w, http.StatusOK, map[string]bool{"success": true})
+			}))
+			listener, err := net.Listen("tcp", "0.0.0.0:0")
+			Expect(err).NotTo(HaveOccurred())
+			server.Listener = listener
+			server.Start()
+
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port, err := strconv.Atoi(u.Port())
+			Expect(err).NotTo(HaveOccurred())
+
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Replicas:    3,
+					ApiPort:     port,
+					PeeringPort: 8107,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			sts = newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			sts.Spec.ServiceName = fmt.Sprintf("%s-svc", sts.Name)
+			status := sts.Status
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			sts.Status = status
+			sts.Status.ObservedGeneration = sts.Generation
+			Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+			slice = &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      sts.Spec.ServiceName,
+					Namespace: "default",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: sts.Spec.ServiceName},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			}
+
+			pods = nil
+			endpoints = nil
+			nodes := make([]string, 0, 3)
+			for i := range 3 {
+				ip := fmt.Sprintf("127.0.0.%d", i+1)
+
+				pod := newStatefulSetPod(sts, i, sts.Status.CurrentRevision, true)
+				podStatus := pod.Status
+				podStatus.PodIP = ip
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+				pod.Status = podStatus
+				Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+				pods = append(pods, pod)
+
+				endpoints = append(endpoints, NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(ip)})
+				slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+					Addresses: []string{ip},
+					TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod.Name, Namespace: "default"},
+				})
+				nodes = append(nodes, fmt.Sprintf("%s:%d:%d", ip, ts.Spec.PeeringPort, ts.Spec.ApiPort))
+			}
+			Expect(k8sClient.Create(ctx, slice)).To(Succeed())
+
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+				Data:       map[string]string{"nodes": strings.Join(nodes, ",")},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				Recorder:  record.NewFakeRecorder(10),
+				InCluster: true,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			for _, pod := range pods {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &pod))).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, slice)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cm)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(sts.Name, 3, "1"))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		recoverQuorum := func(nodesStatus map[string]NodeStatus) (ConditionQuorum, int, error) {
+			return controllerReconciler.recoverQuorum(ctx, ts, cm, client.ObjectKeyFromObject(sts), ClusterStatusElectionDeadlock, endpoints, nodesStatus, 0, 2)
+		}
+
+		continueQuorumRecovery := func(state NodeState, startedAt time.Time) (ConditionQuorum, int, error) {
+			recovery := tsv1alpha1.RemediationStatus{
+				Type:           tsv1alpha1.RemediationQuorumRecovery,
+				Pod:            pods[1].Name,
+				Phase:          tsv1alpha1.RemediationInProgress,
+				Object:         cm.Name,
+				CommittedIndex: 9,
+				StartedAt:      ptr.To(metav1.NewTime(startedAt)),
+			}
+			Expect(controllerReconciler.updateRemediation(ctx, ts, recovery)).To(Succeed())
+
+			current := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			current.Data["nodes"] = fmt.Sprintf("127.0.0.2:%d:%d", ts.Spec.PeeringPort, ts.Spec.ApiPort)
+			Expect(k8sClient.Update(ctx, current)).To(Succeed())
+
+			nodesStatus := map[string]NodeStatus{
+				pods[0].Name: {State: NotReadyState},
+				pods[1].Name: {State: state, CommittedIndex: 9},
+				pods[2].Name: {State: NotReadyState},
+			}
+
+			return controllerReconciler.continueQuorumRecovery(ctx, ts, current, sts, &http.Client{Timeout: time.Second}, &corev1.Secret{}, endpoints, nodesStatus, *getQuorumRecoveryInProgress(ts))
+		}
+
+		It("should shrink the nodes list to the survivor and restart the other nodes", func() {
+			condition, size, err := recoverQuorum(map[string]NodeStatus{
+				pods[0].Name: {State: FollowerState, CommittedIndex: 5},
+				pods[1].Name: {State: CandidateState, CommittedIndex: 9},
+				pods[2].Name: {State: UnreachableState},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumDowngraded))
+			Expect(size).To(Equal(1))
+
+			Expect(getNodes()).To(Equal(fmt.Sprintf("127.0.0.2:%d:%d", ts.Spec.PeeringPort, ts.Spec.ApiPort)))
+
+			for i, pod := range pods {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &corev1.Pod{})
+				if i == 1 {
+					Expect(err).NotTo(HaveOccurred())
+					continue
+				}
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+
+			recovery := getQuorumRecoveryInProgress(ts)
+			Expect(recovery).NotTo(BeNil())
+			Expect(recovery.Pod).To(Equal(pods[1].Name))
+			Expect(recovery.CommittedIndex).To(BeEquivalentTo(9))
+
+			current := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(sts), current)).To(Succeed())
+			Expect(*current.Spec.Replicas).To(BeEquivalentTo(3))
+		})
+
+		It("should fall back to downgrading the quorum when no node reports its committed index", func() {
+			downgrades := testutil.ToFloat64(quorumDowngradesTotal.WithLabelValues(ts.Namespace, ts.Name))
+
+			condition, _, err := recoverQuorum(map[string]NodeStatus{
+				pods[0].Name: {State: CandidateState},
+				pods[1].Name: {State: CandidateState},
+				pods[2].Name: {State: UnreachableState},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumDowngraded))
+			Expect(getQuorumRecoveryInProgress(ts)).To(BeNil())
+
+			current := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(sts), current)).To(Succeed())
+			Expect(*current.Spec.Replicas).To(BeEquivalentTo(1))
+			Expect(testutil.ToFloat64(quorumDowngradesTotal.WithLabelValues(ts.Namespace, ts.Name))).To(Equal(downgrades + 1))
+		})
+
+		It("should wait for the survivor to sync its nodes list", func() {
+			condition, _, err := continueQuorumRecovery(FollowerState, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumNotReadyWaitATerm))
+			Expect(getResets()).To(BeEmpty())
+			Expect(getQuorumRecoveryInProgress(ts)).NotTo(BeNil())
+		})
+
+		It("should reset the peers of the survivor until it leads", func() {
+			condition, _, err := continueQuorumRecovery(FollowerState, time.Now().Add(-configMapRequeuePeriod-time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumNotReadyWaitATerm))
+			Expect(getResets()).To(Equal([]string{"127.0.0.2"}))
+			Expect(getQuorumRecoveryInProgress(ts)).NotTo(BeNil())
+		})
+
+		It("should give up on a survivor that does not lead in time", func() {
+			condition, _, err := continueQuorumRecovery(CandidateState, time.Now().Add(-quorumRecoveryTimeout-time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumNotReady))
+			Expect(getQuorumRecoveryInProgress(ts)).To(BeNil())
+			Expect(ts.Status.Remediations[0].Phase).To(Equal(tsv1alpha1.RemediationFailed))
+			Expect(ts.Status.Remediations[0].Message).To(ContainSubstring("did not become leader"))
+		})
+
+		It("should regrow the quorum once the survivor leads", func() {
+			upgrades := testutil.ToFloat64(quorumUpgradesTotal.WithLabelValues(ts.Namespace, ts.Name))
+
+			condition, size, err := continueQuorumRecovery(LeaderState, time.Now().Add(-configMapRequeuePeriod-time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumUpgraded))
+			Expect(size).To(Equal(3))
+			Expect(getResets()).To(BeEmpty())
+
+			Expect(getQuorumRecoveryInProgress(ts)).To(BeNil())
+			Expect(ts.Status.Remediations[0].Phase).To(Equal(tsv1alpha1.RemediationCompleted))
+			Expect(strings.Split(getNodes(), ",")).To(HaveLen(3))
+			Expect(testutil.ToFloat64(quorumUpgradesTotal.WithLabelValues(ts.Namespace, ts.Name))).To(Equal(upgrades + 1))
+		})
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Quorum", func() {
+	ctx := context.Background()
+
+	Context("When labelling the pods with the raft role of their node", func() {
+		const resourceName = "test-quorum-roles"
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var sts *appsv1.StatefulSet
+		var pods []corev1.Pod
+		var controllerReconciler *TypesenseClusterReconciler
+
+		getRoles := func() map[string]string {
+			roles := map[string]string{}
+			for _, pod := range pods {
+				current := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), current)).To(Succeed())
+				roles[current.Name] = current.Labels[RaftRoleLabelKey]
+			}
+
+			return roles
+		}
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Replicas:    3,
+					ApiPort:     8108,
+					PeeringPort: 8107,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			sts = newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			status := sts.Status
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			sts.Status = status
+			sts.Status.ObservedGeneration = sts.Generation
+			Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+			// a single node is listed, so the quorum is not ready and none of the nodes is probed
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+				Data:       map[string]string{"nodes": "10.0.0.1:8107:8108"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+			pods = nil
+			for i, role := range []string{"leader", "follower", "follower"} {
+				pod := newStatefulSetPod(sts, i, sts.Status.CurrentRevision, true)
+				pod.Labels[RaftRoleLabelKey] = role
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+				pods = append(pods, pod)
+			}
+
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+		})
+
+		AfterEach(func() {
+			for _, pod := range pods {
+				Expect(k8sClient.Delete(ctx, &pod)).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(sts.Name, 3, "1"))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should follow the probed role and clear the role of the nodes that are not known", func() {
+			controllerReconciler.reconcilePodRoleLabels(ctx, sts, map[string]NodeStatus{
+				pods[0].Name: {State: UntrustedState},
+				pods[1].Name: {State: LeaderState},
+			})
+
+			Expect(getRoles()).To(Equal(map[string]string{
+				pods[0].Name: "",
+				pods[1].Name: "leader",
+				pods[2].Name: "",
+			}))
+		})
+
+		It("should clear the roles when the quorum is not ready", func() {
+			condition, _, err := controllerReconciler.ReconcileQuorum(ctx, ts, &corev1.Secret{}, client.ObjectKeyFromObject(sts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonStatefulSetNotReady))
+
+			Expect(getRoles()).To(HaveEach(BeEmpty()))
+		})
+
+		It("should leave the roles untouched while the cluster is paused", func() {
+			ts.Spec.Paused = true
+
+			condition, _, err := controllerReconciler.ReconcileQuorum(ctx, ts, &corev1.Secret{}, client.ObjectKeyFromObject(sts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonStatefulSetNotReady))
+
+			Expect(getRoles()).To(Equal(map[string]string{
+				pods[0].Name: "leader",
+				pods[1].Name: "follower",
+				pods[2].Name: "follower",
+			}))
+		})
+	})
+})
@@ -14,6 +14,8 @@ const (
 	NotReadyState    NodeState = "NOT_READY"
 	ErrorState       NodeState = "ERROR"
 	UnreachableState NodeState = "UNREACHABLE"
+	// UntrustedState is a node that answered with a certificate that could not be verified, its actual state is unknown
+	UntrustedState NodeState = "UNTRUSTED"
 )
 
 type NodeStatus struct {
@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxRemediationsHistory is the number of remediations kept in the status
+	maxRemediationsHistory = 10
+)
+
+// ReconcileOutOfDiskRemediation expands the data volume of every node reporting OUT_OF_DISK by the configured
+// increment, and restarts the node once its volume is resized, so Typesense picks up the free space. A node is only
+// remediated again if it still reports OUT_OF_DISK after it was restarted.
+func (r *TypesenseClusterReconciler) ReconcileOutOfDiskRemediation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	if !ts.Spec.IsOutOfDiskRemediationEnabled() {
+		return nil
+	}
+
+	r.logger.V(debugLevel).Info("reconciling out of disk remediation")
+
+	remediations := slices.Clone(ts.Status.Remediations)
+	for _, remediation := range remediations {
+		if remediation.Type != tsv1alpha1.RemediationOutOfDisk || remediation.Phase != tsv1alpha1.RemediationInProgress {
+			continue
+		}
+
+		if err := r.completeOutOfDiskRemediation(ctx, ts, remediation); err != nil {
+			return err
+		}
+	}
+
+	for _, node := range ts.Status.Nodes {
+		if node.ResourceError != string(OutOfDisk) {
+			continue
+		}
+
+		latest := getLatestRemediation(ts, tsv1alpha1.RemediationOutOfDisk, node.Pod)
+		if latest != nil {
+			if latest.Phase == tsv1alpha1.RemediationInProgress {
+				continue
+			}
+
+			// the report predates the restart of the node
+			if latest.Phase == tsv1alpha1.RemediationCompleted && latest.CompletedAt != nil && !node.LastProbeTime.After(latest.CompletedAt.Time) {
+				continue
+			}
+		}
+
+		if err := r.startOutOfDiskRemediation(ctx, ts, node.Pod, latest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) startOutOfDiskRemediation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, pod string, latest *tsv1alpha1.RemediationStatus) error {
+	spec := ts.Spec.Remediation.OutOfDisk
+	claimName := fmt.Sprintf(ClusterDataVolumeClaim, pod)
+
+	var pvc = &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: claimName}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch persistent volume claim: %s", claimName))
+		return err
+	}
+
+	remediation := tsv1alpha1.RemediationStatus{
+		Type:      tsv1alpha1.RemediationOutOfDisk,
+		Pod:       pod,
+		Object:    claimName,
+		StartedAt: ptr.To(metav1.Now()),
+	}
+
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requested.Cmp(spec.MaxSize) >= 0 {
+		return r.failRemediation(ctx, ts, remediation, latest, fmt.Sprintf("data volume %s already reached the max size %s", claimName, spec.MaxSize.String()))
+	}
+
+	// a volume that does not grow would have its node restarted over and over, as it keeps running out of disk
+	if spec.Increment.Sign() <= 0 {
+		return r.failRemediation(ctx, ts, remediation, latest, fmt.Sprintf("increment %s does not expand data volume %s", spec.Increment.String(), claimName))
+	}
+
+	storageClassName, err := r.resolveStorageClassName(ctx, ptr.Deref(pvc.Spec.StorageClassName, ts.Spec.GetStorage().StorageClassName))
+	if err != nil {
+		return err
+	}
+
+	expandable, err := r.isVolumeExpansionAllowed(ctx, storageClassName)
+	if err != nil {
+		return err
+	}
+
+	if !expandable {
+		return r.failRemediation(ctx, ts, remediation, latest, getVolumeExpansionNotAllowedMessage(storageClassName))
+	}
+
+	size := requested.DeepCopy()
+	size.Add(spec.Increment)
+	if size.Cmp(spec.MaxSize) > 0 {
+		size = spec.MaxSize.DeepCopy()
+	}
+
+	r.logger.Info("expanding persistent volume claim of out of disk node", "pod", pod, "pvc", claimName, "from", requested.String(), "to", size.String())
+
+	patch := client.MergeFrom(pvc.DeepCopy())
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	if err := r.Patch(ctx, pvc, patch); err != nil {
+		r.logger.Error(err, "expanding persistent volume claim failed", "pvc", claimName)
+		return err
+	}
+
+	remediation.Phase = tsv1alpha1.RemediationInProgress
+	remediation.Message = fmt.Sprintf("expanding data volume %s from %s to %s", claimName, requested.String(), size.String())
+	if err := r.updateRemediation(ctx, ts, remediation); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "OutOfDiskRemediationStarted", "%s is out of disk: %s", pod, remediation.Message)
+	return nil
+}
+
+// completeOutOfDiskRemediation restarts the node once its data volume is resized, or the file system resize waits
+// for the restart
+func (r *TypesenseClusterReconciler) completeOutOfDiskRemediation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, remediation tsv1alpha1.RemediationStatus) error {
+	var pvc = &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: remediation.Object}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.failRemediation(ctx, ts, remediation, nil, fmt.Sprintf("data volume %s not found", remediation.Object))
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch persistent volume claim: %s", remediation.Object))
+		return err
+	}
+
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+
+	resized := capacity.Cmp(requested) >= 0
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			resized = true
+		}
+	}
+
+	if !resized {
+		r.logger.V(debugLevel).Info("waiting for persistent volume claim to be resized", "pvc", pvc.Name, "capacity", capacity.String(), "requested", requested.String())
+		return nil
+	}
+
+	r.logger.Info("restarting out of disk node", "pod", remediation.Pod, "pvc", pvc.Name)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ts.Namespace, Name: remediation.Pod}}
+	if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		r.logger.Error(err, "failed to delete pod", "pod", remediation.Pod)
+		return err
+	}
+
+	remediation.Phase = tsv1alpha1.RemediationCompleted
+	remediation.Message = fmt.Sprintf("expanded data volume %s to %s and restarted %s", pvc.Name, requested.String(), remediation.Pod)
+	remediation.CompletedAt = ptr.To(metav1.Now())
+	if err := r.updateRemediation(ctx, ts, remediation); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "OutOfDiskRemediationCompleted", toTitle(remediation.Message))
+	return nil
+}
+
+// failRemediation records a remediation that cannot be performed, unless the same failure is already recorded last
+func (r *TypesenseClusterReconciler) failRemediation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, remediation tsv1alpha1.RemediationStatus, latest *tsv1alpha1.RemediationStatus, message string) error {
+	if latest != nil && latest.Phase == tsv1alpha1.RemediationFailed && latest.Message == message {
+		return nil
+	}
+
+	r.logger.Info("remediation failed", "type", remediation.Type, "pod", remediation.Pod, "reason", message)
+
+	remediation.Phase = tsv1alpha1.RemediationFailed
+	remediation.Message = message
+	remediation.CompletedAt = ptr.To(metav1.Now())
+	if err := r.updateRemediation(ctx, ts, remediation); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Warning", fmt.Sprintf("%sRemediationFailed", remediation.Type), "Unable to remediate %s: %s", remediation.Pod, message)
+	return nil
+}
+
+// updateRemediation records the remediation first, replacing the one in progress for the same node, and trims the
+// history of the finished ones
+func (r *TypesenseClusterReconciler) updateRemediation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, remediation tsv1alpha1.RemediationStatus) error {
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		remediations := []tsv1alpha1.RemediationStatus{remediation}
+		for _, previous := range status.Remediations {
+			if previous.Type == remediation.Type && previous.Pod == remediation.Pod && previous.Phase == tsv1alpha1.RemediationInProgress {
+				continue
+			}
+
+			remediations = append(remediations, previous)
+		}
+
+		status.Remediations = trimRemediations(remediations)
+	})
+}
+
+// trimRemediations keeps the latest finished remediations up to maxRemediationsHistory, next to every remediation
+// still in progress, as those are what a quorum recovery or a node replacement resumes from after a restart
+func trimRemediations(remediations []tsv1alpha1.RemediationStatus) []tsv1alpha1.RemediationStatus {
+	inProgress := 0
+	for _, remediation := range remediations {
+		if remediation.Phase == tsv1alpha1.RemediationInProgress {
+			inProgress++
+		}
+	}
+
+	finished := max(maxRemediationsHistory-inProgress, 0)
+	trimmed := make([]tsv1alpha1.RemediationStatus, 0, min(len(remediations), inProgress+finished))
+	for _, remediation := range remediations {
+		if remediation.Phase != tsv1alpha1.RemediationInProgress {
+			if finished == 0 {
+				continue
+			}
+			finished--
+		}
+
+		trimmed = append(trimmed, remediation)
+	}
+
+	return trimmed
+}
+
+func getLatestRemediation(ts *tsv1alpha1.TypesenseCluster, remediationType tsv1alpha1.RemediationType, pod string) *tsv1alpha1.RemediationStatus {
+	for i, remediation := range ts.Status.Remediations {
+		if remediation.Type == remediationType && remediation.Pod == pod {
+			return &ts.Status.Remediations[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Remediation", func() {
+	remediations := func(phase tsv1alpha1.RemediationPhase, count int) []tsv1alpha1.RemediationStatus {
+		var remediations []tsv1alpha1.RemediationStatus
+		for i := range count {
+			remediations = append(remediations, tsv1alpha1.RemediationStatus{
+				Type:  tsv1alpha1.RemediationOutOfDisk,
+				Pod:   fmt.Sprintf("test-sts-%d", i),
+				Phase: phase,
+			})
+		}
+
+		return remediations
+	}
+
+	It("should trim the history to the latest finished remediations", func() {
+		history := remediations(tsv1alpha1.RemediationCompleted, maxRemediationsHistory+2)
+
+		trimmed := trimRemediations(history)
+		Expect(trimmed).To(Equal(history[:maxRemediationsHistory]))
+	})
+
+	It("should never trim the remediations in progress", func() {
+		inProgress := []tsv1alpha1.RemediationStatus{
+			{Type: tsv1alpha1.RemediationQuorumRecovery, Pod: "test-sts-0", Phase: tsv1alpha1.RemediationInProgress},
+			{Type: tsv1alpha1.RemediationNodeReplacement, Pod: "test-sts-1", Phase: tsv1alpha1.RemediationInProgress},
+		}
+		history := append(remediations(tsv1alpha1.RemediationFailed, maxRemediationsHistory), inProgress...)
+
+		trimmed := trimRemediations(history)
+		Expect(trimmed).To(HaveLen(maxRemediationsHistory))
+		Expect(trimmed).To(ContainElements(inProgress))
+		Expect(trimmed[:maxRemediationsHistory-2]).To(Equal(history[:maxRemediationsHistory-2]))
+	})
+
+	It("should keep every remediation in progress past the size of the history", func() {
+		history := append(remediations(tsv1alpha1.RemediationInProgress, maxRemediationsHistory+1),
+			remediations(tsv1alpha1.RemediationCompleted, 1)...)
+
+		trimmed := trimRemediations(history)
+		Expect(trimmed).To(Equal(history[:maxRemediationsHistory+1]))
+	})
+
+	Context("When the data volume of an out of disk node cannot grow", func() {
+		const resourceName = "test-remediation-increment"
+
+		ctx := context.Background()
+		pod := fmt.Sprintf("%s-0", fmt.Sprintf(ClusterStatefulSet, resourceName))
+		pvcName := fmt.Sprintf(ClusterDataVolumeClaim, pod)
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var controllerReconciler *TypesenseClusterReconciler
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			// a zero increment is rejected by the api server, unless the crd predates the validation
+			ts.Spec.Remediation = &tsv1alpha1.RemediationSpec{
+				OutOfDisk: &tsv1alpha1.OutOfDiskRemediationSpec{MaxSize: resource.MustParse("10Gi")},
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should fail the remediation instead of restarting the node", func() {
+			Expect(controllerReconciler.startOutOfDiskRemediation(ctx, ts, pod, nil)).To(Succeed())
+
+			Expect(ts.Status.Remediations).To(HaveLen(1))
+			Expect(ts.Status.Remediations[0].Phase).To(Equal(tsv1alpha1.RemediationFailed))
+			Expect(ts.Status.Remediations[0].Message).To(Equal(fmt.Sprintf("increment 0 does not expand data volume %s", pvcName)))
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: pvcName, Namespace: "default"}, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("1Gi")))
+		})
+	})
+})
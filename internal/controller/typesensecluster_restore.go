@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	restoreMountPath = "/etc/typesense-restore"
+	restorePhaseKey  = "phase"
+)
+
+// restoreScript downloads the snapshot into the data directory, unless the restore has been completed in the meantime
+// (e.g. a replica added later on) or the volume already holds raft state.
+const restoreScript = `set -e
+if [ "$(cat /etc/typesense-restore/phase 2>/dev/null)" != "Restoring" ]; then
+  echo "restore is not in progress, skipping"
+  exit 0
+fi
+if [ -d "$DATA_DIR/state" ]; then
+  echo "data directory is already initialized, skipping"
+  exit 0
+fi
+if [ -n "$S3_ENDPOINT" ]; then
+  aws configure set default.s3.addressing_style path
+  set -- --endpoint-url "$S3_ENDPOINT"
+fi
+aws "$@" s3 cp --recursive --only-show-errors "$SOURCE" "$DATA_DIR"
+`
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensebackups,verbs=get;list;watch
+
+// ReconcileRestore resolves the snapshot to restore from when the cluster is bootstrapped, and keeps the ConfigMap
+// that tells the init containers whether the restore is still in progress in sync with the status.
+func (r *TypesenseClusterReconciler) ReconcileRestore(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	if ts.Spec.Restore == nil {
+		return nil
+	}
+
+	r.logger.V(debugLevel).Info("reconciling restore")
+
+	if ts.Status.Restore == nil {
+		stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterStatefulSet, ts.Name)}
+		err := r.Get(ctx, stsObjectKey, &appsv1.StatefulSet{})
+		if err == nil {
+			r.logger.Info("skipping restore, cluster has already been bootstrapped")
+			r.Recorder.Eventf(ts, "Warning", "RestoreSkipped", toTitle("restore is applied only when a cluster is bootstrapped"))
+
+			return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+				status.Restore = &tsv1alpha1.RestoreStatus{Phase: tsv1alpha1.RestoreSkipped}
+			})
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		restore, err := r.resolveRestoreSource(ctx, ts)
+		if err != nil {
+			return err
+		}
+
+		err = r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.Restore = restore
+		})
+		if err != nil {
+			return err
+		}
+
+		r.logger.Info("restoring cluster from snapshot", "location", restore.Source.Location)
+		r.Recorder.Eventf(ts, "Normal", "RestoreStarted", "Restoring data volumes from %s", restore.Source.Location)
+	}
+
+	if ts.Status.Restore.Phase == tsv1alpha1.RestoreSkipped {
+		return nil
+	}
+
+	return r.reconcileRestoreConfigMap(ctx, ts)
+}
+
+// completeRestore stops the init containers from seeding any further volumes, once the restored quorum is ready
+func (r *TypesenseClusterReconciler) completeRestore(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	now := metav1.Now()
+	err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Restore.Phase = tsv1alpha1.RestoreCompleted
+		status.Restore.CompletedAt = &now
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.reconcileRestoreConfigMap(ctx, ts)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("restoring cluster from snapshot completed", "location", ts.Status.Restore.Source.Location)
+	r.Recorder.Eventf(ts, "Normal", "RestoreCompleted", "Restored data volumes from %s", ts.Status.Restore.Source.Location)
+
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) resolveRestoreSource(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*tsv1alpha1.RestoreStatus, error) {
+	now := metav1.Now()
+	restore := &tsv1alpha1.RestoreStatus{
+		Phase:     tsv1alpha1.RestoreRestoring,
+		StartedAt: &now,
+	}
+
+	if ts.Spec.Restore.Source != nil {
+		restore.Source = ts.Spec.Restore.Source.DeepCopy()
+		return restore, nil
+	}
+
+	if ts.Spec.Restore.Backup == nil {
+		return nil, fmt.Errorf("restore requires either a backup or a source")
+	}
+
+	var tb tsv1alpha1.TypesenseBackup
+	backupObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: ts.Spec.Restore.Backup.Name}
+	if err := r.Get(ctx, backupObjectKey, &tb); err != nil {
+		return nil, err
+	}
+
+	if tb.Status.Phase != tsv1alpha1.BackupCompleted {
+		return nil, fmt.Errorf("backup %s is not completed: %s", tb.Name, tb.Status.Phase)
+	}
+
+	restore.Backup = tb.Name
+	restore.CommittedIndex = tb.Status.CommittedIndex
+	restore.Source = &tsv1alpha1.RestoreSourceSpec{
+		Location:          tb.Status.Location,
+		Endpoint:          tb.Spec.Destination.S3.Endpoint,
+		Region:            tb.GetRegion(),
+		CredentialsSecret: tb.Spec.Destination.S3.CredentialsSecret,
+	}
+
+	return restore, nil
+}
+
+func (r *TypesenseClusterReconciler) reconcileRestoreConfigMap(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	configMapName := fmt.Sprintf(ClusterRestoreConfigMap, ts.Name)
+	configMapObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: configMapName}
+	phase := string(ts.Status.Restore.Phase)
+
+	var cm = &corev1.ConfigMap{}
+	if err := r.Get(ctx, configMapObjectKey, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch config map: %s", configMapName))
+			return err
+		}
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: getObjectMeta(ts, &configMapName, nil),
+			Data: map[string]string{
+				restorePhaseKey: phase,
+			},
+		}
+
+		err = ctrl.SetControllerReference(ts, cm, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		return r.Create(ctx, cm)
+	}
+
+	if cm.Data[restorePhaseKey] == phase {
+		return nil
+	}
+
+	patch := client.MergeFrom(cm.DeepCopy())
+	cm.Data = map[string]string{
+		restorePhaseKey: phase,
+	}
+
+	return r.Patch(ctx, cm, patch)
+}
+
+// buildRestoreInitContainers returns the init container seeding the data volume from the snapshot, and the volume
+// of the ConfigMap gating it. The pod template keeps them after the restore completes, so no rollout is triggered.
+func (r *TypesenseClusterReconciler) buildRestoreInitContainers(ts *tsv1alpha1.TypesenseCluster) ([]corev1.Container, []corev1.Volume) {
+	if ts.Spec.Restore == nil || ts.Status.Restore == nil || ts.Status.Restore.Source == nil {
+		return nil, nil
+	}
+
+	source := ts.Status.Restore.Source
+	env := []corev1.EnvVar{
+		{
+			Name:  "HOME",
+			Value: "/tmp",
+		},
+		{
+			Name:  "AWS_DEFAULT_REGION",
+			Value: source.GetRegion(),
+		},
+		{
+			Name:  "SOURCE",
+			Value: source.Location,
+		},
+		{
+			Name:  "DATA_DIR",
+			Value: typesenseDataDir,
+		},
+	}
+
+	if source.Endpoint != nil {
+		env = append(env, corev1.EnvVar{Name: "S3_ENDPOINT", Value: *source.Endpoint})
+	}
+
+	var resources corev1.ResourceRequirements
+	if ts.Spec.Restore.Resources != nil {
+		resources = *ts.Spec.Restore.Resources
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            "restore",
+			Image:           ts.Spec.Restore.GetImage(),
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			SecurityContext: ts.Spec.GetTypesenseSecurityContext(),
+			Command:         []string{"/bin/sh", "-c", restoreScript},
+			Env:             env,
+			EnvFrom: []corev1.EnvFromSource{
+				{
+					SecretRef: &corev1.SecretEnvSource{
+						LocalObjectReference: source.CredentialsSecret,
+					},
+				},
+			},
+			Resources: resources,
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					MountPath: typesenseDataDir,
+					Name:      "data",
+				},
+				{
+					MountPath: restoreMountPath,
+					Name:      "restore",
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: "restore",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: fmt.Sprintf(ClusterRestoreConfigMap, ts.Name),
+					},
+					Optional: ptr.To(true),
+				},
+			},
+		},
+	}
+
+	return containers, volumes
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Restore", func() {
+	ctx := context.Background()
+
+	Context("When a new cluster is restored from a backup", func() {
+		const resourceName = "test-restore"
+		const backupName = "test-restore-backup"
+
+		configMapName := fmt.Sprintf(ClusterRestoreConfigMap, resourceName)
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var tb *tsv1alpha1.TypesenseBackup
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseClusterReconciler
+
+		getRestorePhase := func() string {
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName}, cm)).To(Succeed())
+			return cm.Data[restorePhaseKey]
+		}
+
+		completeBackup := func() {
+			tb.Status = tsv1alpha1.TypesenseBackupStatus{
+				Phase:          tsv1alpha1.BackupCompleted,
+				CommittedIndex: 42,
+				Location:       tb.GetLocation(),
+			}
+			Expect(k8sClient.Status().Update(ctx, tb)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			tb = &tsv1alpha1.TypesenseBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: backupName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseBackupSpec{
+					Cluster: corev1.LocalObjectReference{Name: "test-restore-origin"},
+					Destination: tsv1alpha1.BackupDestinationSpec{
+						S3: tsv1alpha1.S3DestinationSpec{
+							Bucket:            "typesense-backups",
+							Endpoint:          ptr.To("http://minio.minio.svc.cluster.local:9000"),
+							CredentialsSecret: corev1.LocalObjectReference{Name: "credentials"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tb)).To(Succeed())
+
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Replicas: 3,
+					Restore: &tsv1alpha1.RestoreSpec{
+						Backup: &corev1.LocalObjectReference{Name: backupName},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: "default"}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, cm))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tb)).To(Succeed())
+		})
+
+		It("should resolve the snapshot of the backup and open the restore config map", func() {
+			completeBackup()
+
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+
+			Expect(ts.Status.Restore).NotTo(BeNil())
+			Expect(ts.Status.Restore.Phase).To(Equal(tsv1alpha1.RestoreRestoring))
+			Expect(ts.Status.Restore.Backup).To(Equal(backupName))
+			Expect(ts.Status.Restore.CommittedIndex).To(BeEquivalentTo(42))
+			Expect(ts.Status.Restore.Source.Location).To(Equal(tb.GetLocation()))
+			Expect(ts.Status.Restore.Source.CredentialsSecret.Name).To(Equal("credentials"))
+			Expect(ts.Status.IsRestoring()).To(BeTrue())
+
+			Expect(getRestorePhase()).To(Equal(string(tsv1alpha1.RestoreRestoring)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("RestoreStarted")))
+		})
+
+		It("should fail the restore until the backup is completed", func() {
+			err := controllerReconciler.ReconcileRestore(ctx, ts)
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("backup %s is not completed", backupName))))
+			Expect(ts.Status.Restore).To(BeNil())
+
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			By("resolving the snapshot once the backup is completed")
+			completeBackup()
+
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+			Expect(ts.Status.IsRestoring()).To(BeTrue())
+		})
+
+		It("should skip the restore of a cluster that has already been bootstrapped", func() {
+			completeBackup()
+
+			sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, sts)).To(Succeed())
+			}()
+
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+			Expect(ts.Status.Restore.Phase).To(Equal(tsv1alpha1.RestoreSkipped))
+
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			containers, volumes := controllerReconciler.buildRestoreInitContainers(ts)
+			Expect(containers).To(BeEmpty())
+			Expect(volumes).To(BeEmpty())
+		})
+
+		It("should seed the data volume from an init container gated by the restore config map", func() {
+			completeBackup()
+
+			containers, volumes := controllerReconciler.buildRestoreInitContainers(ts)
+			Expect(containers).To(BeEmpty())
+			Expect(volumes).To(BeEmpty())
+
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+
+			containers, volumes = controllerReconciler.buildRestoreInitContainers(ts)
+			Expect(containers).To(HaveLen(1))
+			container := containers[0]
+			Expect(container.Name).To(Equal("restore"))
+			Expect(container.Image).To(Equal(ts.Spec.Restore.GetImage()))
+			Expect(container.Command).To(Equal([]string{"/bin/sh", "-c", restoreScript}))
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "SOURCE", Value: tb.GetLocation()},
+				corev1.EnvVar{Name: "DATA_DIR", Value: typesenseDataDir},
+				corev1.EnvVar{Name: "S3_ENDPOINT", Value: "http://minio.minio.svc.cluster.local:9000"},
+			))
+			Expect(container.EnvFrom).To(ConsistOf(corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}},
+			}))
+			Expect(container.VolumeMounts).To(ConsistOf(
+				corev1.VolumeMount{Name: "data", MountPath: typesenseDataDir},
+				corev1.VolumeMount{Name: "restore", MountPath: restoreMountPath, ReadOnly: true},
+			))
+
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Name).To(Equal("restore"))
+			Expect(volumes[0].ConfigMap.Name).To(Equal(configMapName))
+			Expect(*volumes[0].ConfigMap.Optional).To(BeTrue())
+		})
+
+		It("should close the restore config map once the restore is completed", func() {
+			completeBackup()
+
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+			Expect(getRestorePhase()).To(Equal(string(tsv1alpha1.RestoreRestoring)))
+
+			Expect(controllerReconciler.completeRestore(ctx, ts)).To(Succeed())
+			Expect(ts.Status.Restore.Phase).To(Equal(tsv1alpha1.RestoreCompleted))
+			Expect(ts.Status.Restore.CompletedAt).NotTo(BeNil())
+			Expect(ts.Status.IsRestoring()).To(BeFalse())
+			Expect(getRestorePhase()).To(Equal(string(tsv1alpha1.RestoreCompleted)))
+
+			By("keeping the init container in the pod template, so no rollout is triggered")
+			containers, _ := controllerReconciler.buildRestoreInitContainers(ts)
+			Expect(containers).To(HaveLen(1))
+
+			By("leaving the completed restore untouched afterward")
+			Expect(controllerReconciler.ReconcileRestore(ctx, ts)).To(Succeed())
+			Expect(getRestorePhase()).To(Equal(string(tsv1alpha1.RestoreCompleted)))
+		})
+	})
+
+	Context("When the quorum of a restoring cluster is not ready", func() {
+		const resourceName = "test-restore-quorum"
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var sts *appsv1.StatefulSet
+		var cm *corev1.ConfigMap
+		var pods []corev1.Pod
+		var server *httptest.Server
+		var controllerReconciler *TypesenseClusterReconciler
+
+		BeforeEach(func() {
+			// every node listens on its own loopback address and is still seeding its data volume
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJson(w, http.StatusOK, map[string]any{"state": NotReadyState, "committed_index": 0})
+			}))
+			listener, err := net.Listen("tcp", "0.0.0.0:0")
+			Expect(err).NotTo(HaveOccurred())
+			server.Listener = listener
+			server.Start()
+
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port, err := strconv.Atoi(u.Port())
+			Expect(err).NotTo(HaveOccurred())
+
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Replicas:    3,
+					ApiPort:     port,
+					PeeringPort: 8107,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			sts = newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			status := sts.Status
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			sts.Status = status
+			sts.Status.ObservedGeneration = sts.Generation
+			Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+			pods = nil
+			nodes := make([]string, 0, 3)
+			for i := range 3 {
+				ip := fmt.Sprintf("127.0.0.%d", i+1)
+
+				pod := newStatefulSetPod(sts, i, sts.Status.CurrentRevision, true)
+				podStatus := pod.Status
+				podStatus.PodIP = ip
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+				pod.Status = podStatus
+				Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+				pods = append(pods, pod)
+
+				nodes = append(nodes, fmt.Sprintf("%s:%d:%d", ip, ts.Spec.PeeringPort, ts.Spec.ApiPort))
+			}
+
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(ClusterNodesConfigMap, resourceName), Namespace: "default"},
+				Data:       map[string]string{"nodes": strings.Join(nodes, ",")},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+			// the pods are never scheduled, so fetching their logs fails and the nodes are only probed
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				Recorder:  record.NewFakeRecorder(10),
+				ClientSet: kubernetes.NewForConfigOrDie(cfg),
+				InCluster: true,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			for _, pod := range pods {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &pod))).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, cm)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(sts.Name, 3, "1"))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should wait for the volumes to be seeded instead of downgrading the quorum", func() {
+			ts.Status.Restore = &tsv1alpha1.RestoreStatus{Phase: tsv1alpha1.RestoreRestoring}
+
+			condition, _, err := controllerReconciler.ReconcileQuorum(ctx, ts, &corev1.Secret{}, client.ObjectKeyFromObject(sts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeEquivalentTo(ConditionReasonQuorumRestoring))
+
+			current := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			Expect(current.Data["nodes"]).To(Equal(cm.Data["nodes"]))
+
+			currentSts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(sts), currentSts)).To(Succeed())
+			Expect(*currentSts.Spec.Replicas).To(BeEquivalentTo(3))
+			for _, pod := range pods {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &corev1.Pod{})).To(Succeed())
+			}
+		})
+	})
+})
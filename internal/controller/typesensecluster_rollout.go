@@ -0,0 +1,323 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rollingUpdateRequeuePeriod = 15 * time.Second
+)
+
+// ReconcileRollingUpdate restarts the pods that are not on the update revision of the StatefulSet one at a time,
+// followers first and the leader last. Every restarted node has to rejoin and catch up with the committed index the
+// leader had when it went down, before the next one is restarted. The rollout pauses as long as the quorum is lost,
+// or a node cannot be trusted. It returns true while the rollout is in progress.
+func (r *TypesenseClusterReconciler) ReconcileRollingUpdate(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, secret *corev1.Secret, condition ConditionQuorum) (bool, error) {
+	r.logger.V(debugLevel).Info("reconciling rolling update")
+
+	stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterStatefulSet, ts.Name)}
+	sts, err := r.GetFreshStatefulSet(ctx, stsObjectKey)
+	if err != nil {
+		return false, err
+	}
+
+	if sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		// statefulsets created by earlier versions are switched over in place, without restarting any pod
+		r.logger.Info("switching statefulset update strategy", "sts", sts.Name, "strategy", appsv1.OnDeleteStatefulSetStrategyType)
+
+		patch := client.MergeFrom(sts.DeepCopy())
+		sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+		return false, r.Patch(ctx, sts, patch)
+	}
+
+	revision := sts.Status.UpdateRevision
+	if revision == "" {
+		return false, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     sts.Namespace,
+		LabelSelector: labels.SelectorFromSet(sts.Spec.Selector.MatchLabels),
+	}); err != nil {
+		r.logger.Error(err, "failed to list pods", "statefulset", sts.Name)
+		return false, err
+	}
+
+	// highest ordinal first, as the statefulset controller does
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name > pods.Items[j].Name
+	})
+
+	nodes := make([]tsv1alpha1.RollingUpdateNodeStatus, 0, len(pods.Items))
+	outdated := make([]corev1.Pod, 0, len(pods.Items))
+	updatedReplicas := int32(0)
+	for _, pod := range pods.Items {
+		podRevision := pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		updated := podRevision == revision && pod.DeletionTimestamp == nil
+		nodes = append(nodes, tsv1alpha1.RollingUpdateNodeStatus{
+			Pod:      pod.Name,
+			Image:    getTypesenseContainerImage(&pod),
+			Revision: podRevision,
+			Updated:  updated,
+		})
+
+		if updated {
+			updatedReplicas++
+		} else if podRevision != revision && pod.DeletionTimestamp == nil {
+			outdated = append(outdated, pod)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Pod < nodes[j].Pod
+	})
+
+	image := getTypesenseContainerImage(&corev1.Pod{Spec: sts.Spec.Template.Spec})
+
+	if len(outdated) == 0 {
+		if !ts.Status.IsRollingUpdate() || updatedReplicas != *sts.Spec.Replicas {
+			return ts.Status.IsRollingUpdate(), nil
+		}
+
+		now := metav1.Now()
+		err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.RollingUpdate.Phase = tsv1alpha1.RollingUpdateCompleted
+			status.RollingUpdate.Pod = ""
+			status.RollingUpdate.UpdatedReplicas = updatedReplicas
+			status.RollingUpdate.Nodes = nodes
+			status.RollingUpdate.Message = ""
+			status.RollingUpdate.CompletedAt = &now
+		})
+		if err != nil {
+			return false, err
+		}
+
+		r.logger.Info("rolling update completed", "revision", revision, "image", image)
+		r.Recorder.Eventf(ts, "Normal", "RollingUpdateCompleted", "Rolled all pods to %s", getImageTag(image))
+
+		return false, nil
+	}
+
+	if ts.Status.RollingUpdate == nil || ts.Status.RollingUpdate.Revision != revision {
+		now := metav1.Now()
+		err := r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.RollingUpdate = &tsv1alpha1.RollingUpdateStatus{
+				Phase:           tsv1alpha1.RollingUpdateInProgress,
+				Revision:        revision,
+				Image:           image,
+				UpdatedReplicas: updatedReplicas,
+				Nodes:           nodes,
+				StartedAt:       &now,
+			}
+		})
+		if err != nil {
+			return true, err
+		}
+
+		r.logger.Info("rolling update started", "revision", revision, "image", image, "outdated", len(outdated))
+		r.Recorder.Eventf(ts, "Normal", "RollingUpdateStarted", "Rolling %d pods to %s, followers first", len(outdated), getImageTag(image))
+	}
+
+	httpClient, err := r.getHttpClient(ctx, ts)
+	if err != nil {
+		return true, err
+	}
+
+	nodesStatus := make(map[string]NodeStatus, len(pods.Items))
+	leader := ""
+	healthyNodes := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			nodesStatus[pod.Name] = NodeStatus{State: UnreachableState}
+			continue
+		}
+
+		ne := NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(pod.Status.PodIP)}
+		status, err := r.getNodeStatus(ctx, httpClient, ne, ts, secret, "")
+		if err != nil {
+			r.logger.Error(err, "fetching node status failed", "node", getShortName(ne.PodName), "ip", ne.IP)
+		}
+
+		if status.State == LeaderState {
+			leader = pod.Name
+		}
+
+		if status.State == LeaderState || status.State == FollowerState {
+			healthyNodes++
+		}
+
+		nodesStatus[pod.Name] = status
+	}
+
+	message := ""
+	minRequiredNodes := getMinimumRequiredNodes(int(*sts.Spec.Replicas))
+	if untrusted := getUntrustedNodes(nodesStatus); len(untrusted) > 0 {
+		// the state of the nodes is unknown, restarting any of them could take down a healthy quorum
+		message = fmt.Sprintf("certificate of %s could not be verified", strings.Join(untrusted, ", "))
+	} else if healthyNodes < minRequiredNodes || leader == "" {
+		// the cluster is not serving anyway, so recycling the outdated nodes that are down cannot make it worse,
+		// while it lets a template that fixes them roll out
+		for _, pod := range outdated {
+			state := nodesStatus[pod.Name].State
+			if state == LeaderState || state == FollowerState {
+				continue
+			}
+
+			r.logger.Info("restarting outdated node while quorum is lost", "pod", pod.Name, "state", state)
+			if err := r.Delete(ctx, &pod); err != nil {
+				r.logger.Error(err, "failed to delete pod", "pod", pod.Name)
+			}
+		}
+
+		message = fmt.Sprintf("quorum is lost: %d healthy nodes, %d required", healthyNodes, minRequiredNodes)
+	}
+
+	if message != "" {
+		paused := ts.Status.RollingUpdate.Phase != tsv1alpha1.RollingUpdatePaused
+
+		err := r.updateRollingUpdateStatus(ctx, ts, tsv1alpha1.RollingUpdatePaused, updatedReplicas, nodes, message)
+		if err != nil {
+			return true, err
+		}
+
+		if paused {
+			r.logger.Info("rolling update paused", "reason", message)
+			r.Recorder.Eventf(ts, "Warning", "RollingUpdatePaused", toTitle(message))
+		}
+
+		return true, nil
+	}
+
+	if ts.Status.RollingUpdate.Phase == tsv1alpha1.RollingUpdatePaused {
+		r.logger.Info("rolling update resumed")
+		r.Recorder.Eventf(ts, "Normal", "RollingUpdateResumed", toTitle("quorum is recovered"))
+	}
+
+	// nodes out of memory or disk still serve the raft, and the new template may be what fixes them
+	if condition != ConditionReasonQuorumReady && condition != ConditionReasonQuorumNeedsAttentionMemoryOrDiskIssue {
+		message := fmt.Sprintf("waiting for quorum to be ready: %s", condition)
+		return true, r.updateRollingUpdateStatus(ctx, ts, tsv1alpha1.RollingUpdateInProgress, updatedReplicas, nodes, message)
+	}
+
+	if previous := ts.Status.RollingUpdate.Pod; previous != "" {
+		status, ok := nodesStatus[previous]
+		if !ok || (status.State != LeaderState && status.State != FollowerState) || int64(status.CommittedIndex) < ts.Status.RollingUpdate.CommittedIndex {
+			message := fmt.Sprintf("waiting for %s to catch up with committed index %d", previous, ts.Status.RollingUpdate.CommittedIndex)
+			return true, r.updateRollingUpdateStatus(ctx, ts, tsv1alpha1.RollingUpdateInProgress, updatedReplicas, nodes, message)
+		}
+	}
+
+	if healthyNodes != len(pods.Items) || int32(len(pods.Items)) != *sts.Spec.Replicas {
+		message := fmt.Sprintf("waiting for all nodes to be healthy: %d of %d", healthyNodes, *sts.Spec.Replicas)
+		return true, r.updateRollingUpdateStatus(ctx, ts, tsv1alpha1.RollingUpdateInProgress, updatedReplicas, nodes, message)
+	}
+
+	next := outdated[0]
+	for _, pod := range outdated {
+		if pod.Name != leader {
+			next = pod
+			break
+		}
+	}
+
+	role := nodesStatus[next.Name].State
+	committedIndex := int64(nodesStatus[leader].CommittedIndex)
+
+	// the leader hands over to a follower first, the old leader is restarted as a follower once the election is won
+	if next.Name == leader {
+		stepDownAt := ts.Status.RollingUpdate.LeaderStepDownAt
+		if stepDownAt == nil {
+			follower, err := r.stepDownLeader(ctx, httpClient, ts, secret, pods.Items, nodesStatus)
+			if err != nil {
+				r.logger.Error(err, "stepping down leader failed", "pod", leader)
+			}
+
+			r.logger.Info("stepping down leader", "pod", leader, "follower", follower)
+			err = r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+				status.RollingUpdate.Phase = tsv1alpha1.RollingUpdateInProgress
+				status.RollingUpdate.LeaderStepDownAt = ptr.To(metav1.Now())
+				status.RollingUpdate.UpdatedReplicas = updatedReplicas
+				status.RollingUpdate.Nodes = nodes
+				status.RollingUpdate.Message = fmt.Sprintf("waiting for leader %s to step down", leader)
+			})
+			if err != nil {
+				return true, err
+			}
+
+			r.Recorder.Eventf(ts, "Normal", "RollingUpdateLeaderStepDown", "Stepping down leader %s before restarting it", leader)
+			return true, nil
+		}
+
+		if time.Since(stepDownAt.Time) < leaderStepDownTimeout {
+			r.logger.V(debugLevel).Info("waiting for leader to step down", "pod", leader)
+			return true, nil
+		}
+
+		r.logger.Info("leader did not step down, restarting it anyway", "pod", leader, "timeout", leaderStepDownTimeout)
+		r.Recorder.Eventf(ts, "Warning", "RollingUpdateLeaderStepDownFailed", "Leader %s did not step down within %s, restarting it anyway", leader, leaderStepDownTimeout)
+	}
+
+	r.logger.Info("restarting outdated node", "pod", next.Name, "role", role, "committedIndex", committedIndex)
+	if err := r.Delete(ctx, &next); err != nil {
+		r.logger.Error(err, "failed to delete pod", "pod", next.Name)
+		return true, err
+	}
+
+	err = r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.RollingUpdate.Phase = tsv1alpha1.RollingUpdateInProgress
+		status.RollingUpdate.Pod = next.Name
+		status.RollingUpdate.CommittedIndex = committedIndex
+		status.RollingUpdate.LeaderStepDownAt = nil
+		status.RollingUpdate.UpdatedReplicas = updatedReplicas
+		status.RollingUpdate.Nodes = nodes
+		status.RollingUpdate.Message = fmt.Sprintf("restarting %s", next.Name)
+	})
+	if err != nil {
+		return true, err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "RollingUpdatePodRestarted", "Restarting %s (%s) to %s", next.Name, role, getImageTag(image))
+
+	return true, nil
+}
+
+func (r *TypesenseClusterReconciler) updateRollingUpdateStatus(
+	ctx context.Context,
+	ts *tsv1alpha1.TypesenseCluster,
+	phase tsv1alpha1.RollingUpdatePhase,
+	updatedReplicas int32,
+	nodes []tsv1alpha1.RollingUpdateNodeStatus,
+	message string,
+) error {
+	r.logger.V(debugLevel).Info("reporting rolling update", "phase", phase, "updatedReplicas", updatedReplicas, "message", message)
+
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.RollingUpdate.Phase = phase
+		status.RollingUpdate.UpdatedReplicas = updatedReplicas
+		status.RollingUpdate.Nodes = nodes
+		status.RollingUpdate.Message = message
+	})
+}
+
+func getTypesenseContainerImage(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "typesense" {
+			return container.Image
+		}
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Rolling Update", func() {
+	const resourceName = "test-rollout"
+
+	ctx := context.Background()
+
+	var ts *tsv1alpha1.TypesenseCluster
+	var sts *appsv1.StatefulSet
+	var pods []corev1.Pod
+	var server *httptest.Server
+	var states map[string]NodeStatus
+	var votes []string
+	var mu sync.Mutex
+	var controllerReconciler *TypesenseClusterReconciler
+
+	// setStates reports the state of the nodes by their ordinal
+	setStates := func(nodesStatus ...NodeStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		states = map[string]NodeStatus{}
+		for i, status := range nodesStatus {
+			states[fmt.Sprintf("127.0.0.%d", i+1)] = status
+		}
+	}
+
+	getVotes := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, votes...)
+	}
+
+	// createPod creates the node of the ordinal on the given revision, listening on its own loopback address
+	createPod := func(ordinal int, revision string) {
+		pod := newStatefulSetPod(sts, ordinal, revision, true)
+		podStatus := pod.Status
+		podStatus.PodIP = fmt.Sprintf("127.0.0.%d", ordinal+1)
+		Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+		pod.Status = podStatus
+		Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+		pods[ordinal] = pod
+	}
+
+	// getRestarted returns the ordinals of the nodes that were deleted to be restarted
+	getRestarted := func() []int {
+		restarted := make([]int, 0)
+		for i, pod := range pods {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &corev1.Pod{})
+			if apierrors.IsNotFound(err) {
+				restarted = append(restarted, i)
+				continue
+			}
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		return restarted
+	}
+
+	reconcileRollingUpdate := func() {
+		rolling, err := controllerReconciler.ReconcileRollingUpdate(ctx, ts, &corev1.Secret{}, ConditionReasonQuorumReady)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolling).To(BeTrue())
+	}
+
+	BeforeEach(func() {
+		votes = nil
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.Host)
+			Expect(err).NotTo(HaveOccurred())
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if r.Method == http.MethodPost && r.URL.Path == "/operations/vote" {
+				votes = append(votes, host)
+				writeJson(w, http.StatusOK, map[string]bool{"success": true})
+				return
+			}
+
+			status, ok := states[host]
+			if !ok {
+				writeJson(w, http.StatusInternalServerError, map[string]string{"message": "not ready"})
+				return
+			}
+
+			writeJson(w, http.StatusOK, status)
+		}))
+		listener, err := net.Listen("tcp", "0.0.0.0:0")
+		Expect(err).NotTo(HaveOccurred())
+		server.Listener = listener
+		server.Start()
+
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(u.Port())
+		Expect(err).NotTo(HaveOccurred())
+
+		ts = &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec: tsv1alpha1.TypesenseClusterSpec{
+				Replicas:                         3,
+				ApiPort:                          port,
+				PeeringPort:                      8107,
+				HealthProbeTimeoutInMilliseconds: 1000,
+			},
+		}
+		Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+		sts = newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+		status := sts.Status
+		Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+		sts.Status = status
+		sts.Status.ObservedGeneration = sts.Generation
+		Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+		pods = make([]corev1.Pod, 3)
+		for i := range 3 {
+			createPod(i, sts.Status.CurrentRevision)
+		}
+
+		controllerReconciler = &TypesenseClusterReconciler{
+			Client:    k8sClient,
+			Scheme:    k8sClient.Scheme(),
+			Recorder:  record.NewFakeRecorder(10),
+			InCluster: true,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		for _, pod := range pods {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &pod))).To(Succeed())
+		}
+		Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(sts.Name, 3, "1"))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+	})
+
+	It("should restart the followers first", func() {
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: LeaderState, CommittedIndex: 42},
+		)
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(Equal([]int{1}))
+
+		rollingUpdate := ts.Status.RollingUpdate
+		Expect(rollingUpdate.Phase).To(Equal(tsv1alpha1.RollingUpdateInProgress))
+		Expect(rollingUpdate.Revision).To(Equal(sts.Status.UpdateRevision))
+		Expect(rollingUpdate.Pod).To(Equal(pods[1].Name))
+		Expect(rollingUpdate.CommittedIndex).To(BeEquivalentTo(42))
+	})
+
+	It("should wait for the restarted node to catch up with the committed index of the leader", func() {
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: LeaderState, CommittedIndex: 42},
+		)
+		reconcileRollingUpdate()
+
+		createPod(1, sts.Status.UpdateRevision)
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 44},
+			NodeStatus{State: FollowerState, CommittedIndex: 40},
+			NodeStatus{State: LeaderState, CommittedIndex: 44},
+		)
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(BeEmpty())
+		Expect(ts.Status.RollingUpdate.Message).To(Equal(fmt.Sprintf("waiting for %s to catch up with committed index 42", pods[1].Name)))
+
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 44},
+			NodeStatus{State: FollowerState, CommittedIndex: 44},
+			NodeStatus{State: LeaderState, CommittedIndex: 44},
+		)
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(Equal([]int{0}))
+		Expect(ts.Status.RollingUpdate.Pod).To(Equal(pods[0].Name))
+		Expect(ts.Status.RollingUpdate.CommittedIndex).To(BeEquivalentTo(44))
+		Expect(ts.Status.RollingUpdate.UpdatedReplicas).To(BeEquivalentTo(1))
+	})
+
+	It("should step the leader down before restarting it last", func() {
+		for i := range 2 {
+			Expect(k8sClient.Delete(ctx, &pods[i])).To(Succeed())
+			createPod(i, sts.Status.UpdateRevision)
+		}
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 41},
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: LeaderState, CommittedIndex: 42},
+		)
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(BeEmpty())
+		Expect(getVotes()).To(Equal([]string{"127.0.0.2"}))
+		Expect(ts.Status.RollingUpdate.LeaderStepDownAt).NotTo(BeNil())
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(BeEmpty())
+
+		// the leader did not step down in time
+		Expect(controllerReconciler.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.RollingUpdate.LeaderStepDownAt = ptr.To(metav1.NewTime(time.Now().Add(-leaderStepDownTimeout - time.Second)))
+		})).To(Succeed())
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(Equal([]int{2}))
+		Expect(ts.Status.RollingUpdate.Pod).To(Equal(pods[2].Name))
+		Expect(ts.Status.RollingUpdate.LeaderStepDownAt).To(BeNil())
+		Expect(getVotes()).To(HaveLen(1))
+	})
+
+	It("should pause while the quorum is lost, restarting only the nodes that are down", func() {
+		setStates(
+			NodeStatus{State: FollowerState, CommittedIndex: 42},
+			NodeStatus{State: CandidateState, CommittedIndex: 42},
+		)
+
+		reconcileRollingUpdate()
+		Expect(getRestarted()).To(Equal([]int{1, 2}))
+
+		rollingUpdate := ts.Status.RollingUpdate
+		Expect(rollingUpdate.Phase).To(Equal(tsv1alpha1.RollingUpdatePaused))
+		Expect(rollingUpdate.Message).To(Equal("quorum is lost: 1 healthy nodes, 2 required"))
+		Expect(rollingUpdate.Pod).To(BeEmpty())
+	})
+})
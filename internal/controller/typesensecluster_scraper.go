@@ -88,7 +88,11 @@ func (r *TypesenseClusterReconciler) ReconcileScraper(ctx context.Context, ts ts
 				}
 			}
 
-			if scraperCronJob.Spec.Schedule != scraper.Schedule || container.Image != scraper.Image || hasChangedConfig {
+			podAnnotations := scraperCronJob.Spec.JobTemplate.Spec.Template.Annotations
+			hasChangedAdminApiKey := podAnnotations[adminApiKeyRevisionAnnotationKey] != getAdminApiKeyRevision(&ts)
+			hasChangedTLS := podAnnotations[tlsChecksumAnnotationKey] != getTLSChecksum(&ts)
+
+			if scraperCronJob.Spec.Schedule != scraper.Schedule || container.Image != scraper.Image || hasChangedConfig || hasChangedAdminApiKey || hasChangedTLS {
 				hasChanged = true
 			}
 
@@ -129,6 +133,9 @@ func (r *TypesenseClusterReconciler) createScraper(ctx context.Context, key clie
 				Spec: batchv1.JobSpec{
 					BackoffLimit: ptr.To[int32](0),
 					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: getScraperPodAnnotations(ts),
+						},
 						Spec: corev1.PodSpec{
 							ImagePullSecrets: ts.Spec.ImagePullSecrets,
 							RestartPolicy:    corev1.RestartPolicyNever,
@@ -147,7 +154,7 @@ func (r *TypesenseClusterReconciler) createScraper(ctx context.Context, key clie
 												SecretKeyRef: &corev1.SecretKeySelector{
 													Key: ClusterAdminApiKeySecretKeyName,
 													LocalObjectReference: corev1.LocalObjectReference{
-														Name: r.getAdminApiKeyObjectKey(ts).Name,
+														Name: getAdminApiKeyObjectKey(ts).Name,
 													},
 												},
 											},
@@ -162,7 +169,7 @@ func (r *TypesenseClusterReconciler) createScraper(ctx context.Context, key clie
 										},
 										{
 											Name:  "TYPESENSE_PROTOCOL",
-											Value: "http",
+											Value: ts.Spec.GetApiScheme(),
 										},
 									},
 									EnvFrom: scraperSpec.GetScraperAuthConfiguration(),
@@ -185,6 +192,8 @@ func (r *TypesenseClusterReconciler) createScraper(ctx context.Context, key clie
 		},
 	}
 
+	configureScraperTLS(&scraper.Spec.JobTemplate.Spec.Template.Spec, ts)
+
 	err := ctrl.SetControllerReference(ts, scraper, r.Scheme)
 	if err != nil {
 		return err
@@ -206,3 +215,20 @@ func (r *TypesenseClusterReconciler) deleteScraper(ctx context.Context, scraper
 
 	return nil
 }
+
+func getScraperPodAnnotations(ts *tsv1alpha1.TypesenseCluster) map[string]string {
+	annotations := map[string]string{}
+	if revision := getAdminApiKeyRevision(ts); revision != "" {
+		annotations[adminApiKeyRevisionAnnotationKey] = revision
+	}
+
+	if checksum := getTLSChecksum(ts); checksum != "" {
+		annotations[tlsChecksumAnnotationKey] = checksum
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	return annotations
+}
@@ -15,7 +15,7 @@ func (r *TypesenseClusterReconciler) ReconcileSecret(ctx context.Context, ts tsv
 	r.logger.V(debugLevel).Info("reconciling secret")
 
 	secretExists := true
-	secretObjectKey := r.getAdminApiKeyObjectKey(&ts)
+	secretObjectKey := getAdminApiKeyObjectKey(&ts)
 
 	var secret = &v1.Secret{}
 	if err := r.Get(ctx, secretObjectKey, secret); err != nil {
@@ -45,11 +45,40 @@ func (r *TypesenseClusterReconciler) createAdminApiKey(
 	secretObjectKey client.ObjectKey,
 	ts *tsv1alpha1.TypesenseCluster,
 ) (*v1.Secret, error) {
-	token, err := generateToken()
+	// a rotation interrupted after the previous Secret was deleted left the new token in the pending Secret
+	pending, err := r.getPendingAdminApiKeySecret(ctx, ts)
 	if err != nil {
 		return nil, err
 	}
 
+	var token string
+	if pending != nil {
+		token = string(pending.Data[ClusterAdminApiKeySecretKeyName])
+	} else {
+		token, err = generateToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := r.buildAdminApiKeySecret(secretObjectKey, ts, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Create(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func (r *TypesenseClusterReconciler) buildAdminApiKeySecret(
+	secretObjectKey client.ObjectKey,
+	ts *tsv1alpha1.TypesenseCluster,
+	token string,
+) (*v1.Secret, error) {
 	secret := &v1.Secret{
 		ObjectMeta: getObjectMeta(ts, &secretObjectKey.Name, nil),
 		Type:       v1.SecretTypeOpaque,
@@ -59,12 +88,7 @@ func (r *TypesenseClusterReconciler) createAdminApiKey(
 		},
 	}
 
-	err = ctrl.SetControllerReference(ts, secret, r.Scheme)
-	if err != nil {
-		return nil, err
-	}
-
-	err = r.Create(ctx, secret)
+	err := ctrl.SetControllerReference(ts, secret, r.Scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +96,7 @@ func (r *TypesenseClusterReconciler) createAdminApiKey(
 	return secret, nil
 }
 
-func (r *TypesenseClusterReconciler) getAdminApiKeyObjectKey(ts *tsv1alpha1.TypesenseCluster) client.ObjectKey {
+func getAdminApiKeyObjectKey(ts *tsv1alpha1.TypesenseCluster) client.ObjectKey {
 	if ts.Spec.AdminApiKey != nil {
 		return client.ObjectKey{
 			Namespace: ts.Namespace,
@@ -0,0 +1,382 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rotateAdminApiKeyAnnotationKey   = "ts.opentelekomcloud.com/rotate-admin-api-key"
+	adminApiKeyRevisionAnnotationKey = "ts.opentelekomcloud.com/admin-api-key-revision"
+)
+
+// ReconcileAdminApiKeyRotation drives the rotation of the operator managed admin api key:
+//
+//  1. the new token and a copy of the previous one, expiring after the grace period, are registered as keys in the
+//     cluster, so that both are accepted by every node regardless of the bootstrap key it was started with;
+//  2. the admin Secret is replaced and the revision stamped on the pod template rolls the StatefulSet and the scrapers;
+//  3. once all pods are rolled and the grace period elapsed, the temporary keys are revoked.
+//
+// It returns the admin Secret that is in effect after this step.
+func (r *TypesenseClusterReconciler) ReconcileAdminApiKeyRotation(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, secret *v1.Secret) (*v1.Secret, error) {
+	r.logger.V(debugLevel).Info("reconciling admin api key rotation")
+
+	pending, err := r.getPendingAdminApiKeySecret(ctx, ts)
+	if err != nil {
+		return secret, err
+	}
+
+	if pending != nil {
+		// a previous rotation was interrupted while the admin Secret was being replaced
+		return r.replaceAdminApiKeySecret(ctx, ts, secret, pending)
+	}
+
+	trigger := getAdminApiKeyRotationTrigger(ts)
+	status := ts.Status.AdminApiKeyRotation
+
+	if status == nil {
+		// a brand-new cluster starts with a fresh key; only later changes of the trigger are rotations
+		if ts.Status.Phase == InitReconciliationPhase || trigger == "" {
+			return secret, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+				status.AdminApiKeyRotation = &tsv1alpha1.AdminApiKeyRotationStatus{
+					Phase:       tsv1alpha1.AdminApiKeyRotationCompleted,
+					LastTrigger: trigger,
+				}
+			})
+		}
+		status = &tsv1alpha1.AdminApiKeyRotationStatus{Phase: tsv1alpha1.AdminApiKeyRotationCompleted}
+	}
+
+	switch status.Phase {
+	case tsv1alpha1.AdminApiKeyRotationRolling:
+		return secret, r.completeAdminApiKeyRollout(ctx, ts)
+	case tsv1alpha1.AdminApiKeyRotationGracePeriod:
+		return secret, r.expireAdminApiKeyGracePeriod(ctx, ts)
+	}
+
+	if trigger == status.LastTrigger {
+		return secret, nil
+	}
+
+	if ts.Spec.AdminApiKey != nil {
+		err := fmt.Errorf("admin api key is provided by secret %s and cannot be rotated by the operator", ts.Spec.AdminApiKey.Name)
+		r.Recorder.Eventf(ts, "Warning", "AdminApiKeyRotationRejected", toTitle(err.Error()))
+		return secret, r.patchStatus(ctx, ts, func(s *tsv1alpha1.TypesenseClusterStatus) {
+			s.AdminApiKeyRotation = status.DeepCopy()
+			s.AdminApiKeyRotation.LastTrigger = trigger
+		})
+	}
+
+	if !meta.IsStatusConditionTrue(ts.Status.Conditions, ConditionTypeReady) {
+		r.logger.Info("postponing admin api key rotation until the cluster is ready")
+		return secret, nil
+	}
+
+	return r.startAdminApiKeyRotation(ctx, ts, secret, status, trigger)
+}
+
+func (r *TypesenseClusterReconciler) startAdminApiKeyRotation(
+	ctx context.Context,
+	ts *tsv1alpha1.TypesenseCluster,
+	secret *v1.Secret,
+	status *tsv1alpha1.AdminApiKeyRotationStatus,
+	trigger string,
+) (*v1.Secret, error) {
+	r.logger.Info("rotating admin api key", "revision", status.Revision+1)
+
+	api, err := newTypesenseApiClient(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, ts)
+	if err != nil {
+		return secret, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return secret, err
+	}
+
+	var current = &apiKey{}
+	err = api.do(ctx, http.MethodPost, "/keys", nil, apiKey{
+		Value:       token,
+		Description: fmt.Sprintf("admin api key revision %d", status.Revision+1),
+		Actions:     []string{"*"},
+		Collections: []string{"*"},
+	}, current)
+	if err != nil {
+		return secret, err
+	}
+
+	var previousKeyId *int64
+	gracePeriod := ts.Spec.GetAdminApiKeyRotationGracePeriod()
+	if gracePeriod > 0 {
+		var previous = &apiKey{}
+		err = api.do(ctx, http.MethodPost, "/keys", nil, apiKey{
+			Value:       string(secret.Data[ClusterAdminApiKeySecretKeyName]),
+			Description: fmt.Sprintf("admin api key revision %d", status.Revision),
+			Actions:     []string{"*"},
+			Collections: []string{"*"},
+			// the grace period only starts once the pods are rolled, leave headroom for the rollout itself;
+			// the key is revoked explicitly as soon as the grace period ends
+			ExpiresAt: time.Now().Add(2 * gracePeriod).Add(time.Hour).Unix(),
+		}, previous)
+		if err != nil {
+			r.revokeAdminApiKeys(ctx, api, current.Id)
+			return secret, err
+		}
+		previousKeyId = previous.Id
+	}
+
+	// the admin Secret is immutable, so it is replaced as a whole; the new token is persisted in a pending Secret first,
+	// so that it is never lost if the replacement fails half-way
+	pending, err := r.buildPendingAdminApiKeySecret(ts, token)
+	if err != nil {
+		r.revokeAdminApiKeys(ctx, api, current.Id, previousKeyId)
+		return secret, err
+	}
+
+	if err := r.Create(ctx, pending); err != nil {
+		r.revokeAdminApiKeys(ctx, api, current.Id, previousKeyId)
+		return secret, err
+	}
+
+	now := metav1.Now()
+	err = r.patchStatus(ctx, ts, func(s *tsv1alpha1.TypesenseClusterStatus) {
+		s.AdminApiKeyRotation = &tsv1alpha1.AdminApiKeyRotationStatus{
+			Phase:            tsv1alpha1.AdminApiKeyRotationRolling,
+			Revision:         status.Revision + 1,
+			LastTrigger:      trigger,
+			StartedAt:        &now,
+			LastRotationTime: status.LastRotationTime,
+			CurrentKeyId:     current.Id,
+			PreviousKeyId:    previousKeyId,
+		}
+	})
+	if err != nil {
+		if err := r.Delete(ctx, pending); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "deleting pending admin api key secret failed", "secret", pending.Name)
+			return secret, err
+		}
+		r.revokeAdminApiKeys(ctx, api, current.Id, previousKeyId)
+		return secret, err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "AdminApiKeyRotationStarted", "Rolling out admin api key revision %d", status.Revision+1)
+	return r.replaceAdminApiKeySecret(ctx, ts, secret, pending)
+}
+
+// replaceAdminApiKeySecret replaces the admin Secret with one holding the token of the pending Secret, and deletes
+// the pending Secret only once the replacement exists
+func (r *TypesenseClusterReconciler) replaceAdminApiKeySecret(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, secret *v1.Secret, pending *v1.Secret) (*v1.Secret, error) {
+	token := pending.Data[ClusterAdminApiKeySecretKeyName]
+	secretObjectKey := getAdminApiKeyObjectKey(ts)
+
+	if secret != nil && !bytes.Equal(secret.Data[ClusterAdminApiKeySecretKeyName], token) {
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return secret, err
+		}
+		secret = nil
+	}
+
+	if secret == nil {
+		desired, err := r.buildAdminApiKeySecret(secretObjectKey, ts, string(token))
+		if err != nil {
+			return nil, err
+		}
+
+		err = retry.OnError(retry.DefaultBackoff, func(err error) bool { return !apierrors.IsAlreadyExists(err) }, func() error {
+			return r.Create(ctx, desired)
+		})
+		if err != nil {
+			r.logger.Error(err, "recreating admin api key secret failed, keeping pending secret", "secret", secretObjectKey.Name, "pending", pending.Name)
+			return nil, err
+		}
+		secret = desired
+	}
+
+	if err := r.Delete(ctx, pending); err != nil && !apierrors.IsNotFound(err) {
+		r.logger.Error(err, "deleting pending admin api key secret failed", "secret", pending.Name)
+		return secret, err
+	}
+
+	return secret, nil
+}
+
+func (r *TypesenseClusterReconciler) getPendingAdminApiKeySecret(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*v1.Secret, error) {
+	if ts.Spec.AdminApiKey != nil {
+		return nil, nil
+	}
+
+	pendingObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterAdminApiKeyPendingSecret, ts.Name)}
+
+	var pending = &v1.Secret{}
+	if err := r.Get(ctx, pendingObjectKey, pending); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		r.logger.Error(err, fmt.Sprintf("unable to fetch secret: %s", pendingObjectKey))
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func (r *TypesenseClusterReconciler) buildPendingAdminApiKeySecret(ts *tsv1alpha1.TypesenseCluster, token string) (*v1.Secret, error) {
+	secretObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterAdminApiKeyPendingSecret, ts.Name)}
+
+	secret, err := r.buildAdminApiKeySecret(secretObjectKey, ts, token)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Immutable = nil
+	return secret, nil
+}
+
+func (r *TypesenseClusterReconciler) completeAdminApiKeyRollout(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	status := ts.Status.AdminApiKeyRotation
+
+	stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: fmt.Sprintf(ClusterStatefulSet, ts.Name)}
+	var sts = &appsv1.StatefulSet{}
+	if err := r.Get(ctx, stsObjectKey, sts); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var pods v1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     sts.Namespace,
+		LabelSelector: labels.SelectorFromSet(sts.Spec.Selector.MatchLabels),
+	}); err != nil {
+		r.logger.Error(err, "failed to list pods", "statefulset", sts.Name)
+		return err
+	}
+
+	if !isAdminApiKeyRevisionRolledOut(sts, pods.Items, status.Revision) {
+		r.logger.V(debugLevel).Info("waiting for admin api key rollout", "revision", status.Revision, "updated", sts.Status.UpdatedReplicas, "ready", sts.Status.ReadyReplicas)
+		return nil
+	}
+
+	now := metav1.Now()
+	expiresAt := metav1.NewTime(now.Add(ts.Spec.GetAdminApiKeyRotationGracePeriod()))
+
+	err := r.patchStatus(ctx, ts, func(s *tsv1alpha1.TypesenseClusterStatus) {
+		s.AdminApiKeyRotation.Phase = tsv1alpha1.AdminApiKeyRotationGracePeriod
+		s.AdminApiKeyRotation.LastRotationTime = &now
+		s.AdminApiKeyRotation.GracePeriodExpiresAt = &expiresAt
+	})
+	if err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "AdminApiKeyRotated", "Admin api key revision %d rolled out, previous key valid until %s", status.Revision, expiresAt.Format(time.RFC3339))
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) expireAdminApiKeyGracePeriod(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) error {
+	status := ts.Status.AdminApiKeyRotation
+	if status.GracePeriodExpiresAt != nil && time.Now().Before(status.GracePeriodExpiresAt.Time) {
+		return nil
+	}
+
+	api, err := newTypesenseApiClient(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, ts)
+	if err != nil {
+		return err
+	}
+
+	if !r.revokeAdminApiKeys(ctx, api, status.PreviousKeyId, status.CurrentKeyId) {
+		return fmt.Errorf("revoking temporary admin api keys failed")
+	}
+
+	err = r.patchStatus(ctx, ts, func(s *tsv1alpha1.TypesenseClusterStatus) {
+		s.AdminApiKeyRotation.Phase = tsv1alpha1.AdminApiKeyRotationCompleted
+		s.AdminApiKeyRotation.CurrentKeyId = nil
+		s.AdminApiKeyRotation.PreviousKeyId = nil
+		s.AdminApiKeyRotation.GracePeriodExpiresAt = nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "AdminApiKeyRotationCompleted", "Previous admin api key revoked")
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) revokeAdminApiKeys(ctx context.Context, api *typesenseApiClient, ids ...*int64) bool {
+	revoked := true
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+
+		err := api.do(ctx, http.MethodDelete, fmt.Sprintf("/keys/%d", *id), nil, nil, nil)
+		if err != nil && !isTypesenseApiNotFound(err) {
+			r.logger.Error(err, "revoking admin api key failed", "id", *id)
+			revoked = false
+		}
+	}
+
+	return revoked
+}
+
+// isAdminApiKeyRevisionRolledOut checks every pod against the update revision of the StatefulSet, as with the OnDelete
+// update strategy the current revision of its status is never advanced
+func isAdminApiKeyRevisionRolledOut(sts *appsv1.StatefulSet, pods []v1.Pod, revision int64) bool {
+	if sts.Spec.Template.Annotations[adminApiKeyRevisionAnnotationKey] != strconv.FormatInt(revision, 10) {
+		return false
+	}
+
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdateRevision == "" {
+		return false
+	}
+
+	replicas := ptr.Deref(sts.Spec.Replicas, 1)
+	if int32(len(pods)) != replicas {
+		return false
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil ||
+			pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision ||
+			!isPodConditionTrue(&pod, v1.PodReady) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getAdminApiKeyRotationTrigger combines the spec trigger and the annotation, so a change of either starts a rotation
+func getAdminApiKeyRotationTrigger(ts *tsv1alpha1.TypesenseCluster) string {
+	var trigger string
+	if ts.Spec.AdminApiKeyRotation != nil {
+		trigger = ts.Spec.AdminApiKeyRotation.Trigger
+	}
+
+	if annotation := ts.Annotations[rotateAdminApiKeyAnnotationKey]; annotation != "" {
+		return fmt.Sprintf("%s/%s", trigger, annotation)
+	}
+
+	return trigger
+}
+
+// getAdminApiKeyRevision returns the revision to stamp on pod templates, empty if the key was never rotated
+func getAdminApiKeyRevision(ts *tsv1alpha1.TypesenseCluster) string {
+	if ts.Status.AdminApiKeyRotation == nil || ts.Status.AdminApiKeyRotation.Revision == 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(ts.Status.AdminApiKeyRotation.Revision, 10)
+}
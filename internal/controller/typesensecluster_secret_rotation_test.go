@@ -0,0 +1,249 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+func newRolledOutStatefulSet(name string, replicas int32, revision string) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 2},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"app": name},
+					Annotations: map[string]string{adminApiKeyRevisionAnnotationKey: revision},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}}},
+			},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 2,
+			Replicas:           replicas,
+			ReadyReplicas:      replicas,
+			CurrentRevision:    fmt.Sprintf("%s-old", name),
+			UpdateRevision:     fmt.Sprintf("%s-new", name),
+		},
+	}
+}
+
+func newStatefulSetPod(sts *appsv1.StatefulSet, ordinal int, revision string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", sts.Name, ordinal),
+			Namespace: sts.Namespace,
+			Labels: map[string]string{
+				"app":                                 sts.Name,
+				appsv1.ControllerRevisionHashLabelKey: revision,
+			},
+		},
+		Spec: sts.Spec.Template.Spec,
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+// failingCreateClient fails the creation of the object with the given name
+type failingCreateClient struct {
+	client.Client
+	name string
+}
+
+func (c *failingCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetName() == c.name {
+		return errors.New("injected failure")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("Admin api key rotation", func() {
+	sts := newRolledOutStatefulSet("rotation-sts", 3, "2")
+	updated := sts.Status.UpdateRevision
+	current := sts.Status.CurrentRevision
+
+	DescribeTable("isAdminApiKeyRevisionRolledOut",
+		func(mutate func(*appsv1.StatefulSet, []corev1.Pod) []corev1.Pod, revision int64, expected bool) {
+			sts := sts.DeepCopy()
+			pods := []corev1.Pod{
+				newStatefulSetPod(sts, 0, updated, true),
+				newStatefulSetPod(sts, 1, updated, true),
+				newStatefulSetPod(sts, 2, updated, true),
+			}
+			if mutate != nil {
+				pods = mutate(sts, pods)
+			}
+
+			Expect(isAdminApiKeyRevisionRolledOut(sts, pods, revision)).To(Equal(expected))
+		},
+		Entry("all pods on the update revision while the current revision lags behind", nil, int64(2), true),
+		Entry("the template carries another revision", nil, int64(3), false),
+		Entry("a pod is still on the current revision", func(_ *appsv1.StatefulSet, pods []corev1.Pod) []corev1.Pod {
+			pods[1].Labels[appsv1.ControllerRevisionHashLabelKey] = current
+			return pods
+		}, int64(2), false),
+		Entry("a pod is not ready", func(_ *appsv1.StatefulSet, pods []corev1.Pod) []corev1.Pod {
+			pods[2].Status.Conditions[0].Status = corev1.ConditionFalse
+			return pods
+		}, int64(2), false),
+		Entry("a pod is terminating", func(_ *appsv1.StatefulSet, pods []corev1.Pod) []corev1.Pod {
+			pods[0].DeletionTimestamp = ptr.To(metav1.Now())
+			return pods
+		}, int64(2), false),
+		Entry("a pod is missing", func(_ *appsv1.StatefulSet, pods []corev1.Pod) []corev1.Pod {
+			return pods[:2]
+		}, int64(2), false),
+		Entry("the status is not observed yet", func(sts *appsv1.StatefulSet, pods []corev1.Pod) []corev1.Pod {
+			sts.Generation = 3
+			return pods
+		}, int64(2), false),
+	)
+
+	Context("When the rollout of a new revision is finished", func() {
+		const resourceName = "test-rotation"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+
+		BeforeEach(func() {
+			By("creating the cluster in the middle of a rotation")
+			ts := &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+			ts.Status.AdminApiKeyRotation = &tsv1alpha1.AdminApiKeyRotationStatus{
+				Phase:        tsv1alpha1.AdminApiKeyRotationRolling,
+				Revision:     1,
+				CurrentKeyId: ptr.To(int64(1)),
+			}
+			Expect(k8sClient.Status().Update(ctx, ts)).To(Succeed())
+
+			By("creating the statefulset rolled out with the on delete strategy")
+			desired := sts.DeepCopy()
+			Expect(k8sClient.Create(ctx, desired)).To(Succeed())
+			desired.Status = sts.Status
+			desired.Status.ObservedGeneration = desired.Generation
+			Expect(k8sClient.Status().Update(ctx, desired)).To(Succeed())
+
+			for i := range 3 {
+				pod := newStatefulSetPod(sts, i, sts.Status.UpdateRevision, true)
+				status := pod.Status
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+				pod.Status = status
+				Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("default"), client.MatchingLabels{"app": sts.Name})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: sts.Name, Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}})).To(Succeed())
+		})
+
+		It("should start the grace period of the previous key", func() {
+			controllerReconciler := &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			ts := &tsv1alpha1.TypesenseCluster{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ts)).To(Succeed())
+			Expect(controllerReconciler.completeAdminApiKeyRollout(ctx, ts)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ts)).To(Succeed())
+			Expect(ts.Status.AdminApiKeyRotation.Phase).To(Equal(tsv1alpha1.AdminApiKeyRotationGracePeriod))
+			Expect(ts.Status.AdminApiKeyRotation.GracePeriodExpiresAt).NotTo(BeNil())
+		})
+	})
+
+	Context("When replacing the admin secret fails", func() {
+		const resourceName = "test-rotation-pending"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		secretName := fmt.Sprintf(ClusterAdminApiKeySecret, resourceName)
+
+		AfterEach(func() {
+			for _, name := range []string{secretName, fmt.Sprintf(ClusterAdminApiKeyPendingSecret, resourceName)} {
+				secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}})).To(Succeed())
+		})
+
+		It("should keep the new token and recreate the admin secret from it", func() {
+			ts := &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			controllerReconciler := &TypesenseClusterReconciler{
+				Client:   &failingCreateClient{Client: k8sClient, name: secretName},
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			By("creating the previous admin secret and the pending one")
+			previous, err := controllerReconciler.buildAdminApiKeySecret(getAdminApiKeyObjectKey(ts), ts, "previous")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, previous)).To(Succeed())
+
+			pending, err := controllerReconciler.buildPendingAdminApiKeySecret(ts, "rotated")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, pending)).To(Succeed())
+
+			By("failing to recreate the admin secret")
+			secret, err := controllerReconciler.replaceAdminApiKeySecret(ctx, ts, previous, pending)
+			Expect(err).To(HaveOccurred())
+			Expect(secret).To(BeNil())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pending), &corev1.Secret{})).To(Succeed())
+
+			By("recreating the admin secret from the pending token")
+			controllerReconciler.Client = k8sClient
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ts)).To(Succeed())
+			secret, err = controllerReconciler.ReconcileSecret(ctx, *ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(secret.Data[ClusterAdminApiKeySecretKeyName])).To(Equal("rotated"))
+
+			_, err = controllerReconciler.ReconcileAdminApiKeyRotation(ctx, ts, secret)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pending), &corev1.Secret{})).NotTo(Succeed())
+		})
+	})
+})
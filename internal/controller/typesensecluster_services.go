@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
@@ -98,9 +99,84 @@ func (r *TypesenseClusterReconciler) ReconcileServices(ctx context.Context, ts t
 		}
 	}
 
+	roleServices := map[string]NodeState{
+		fmt.Sprintf(ClusterLeaderService, ts.Name):    LeaderState,
+		fmt.Sprintf(ClusterFollowersService, ts.Name): FollowerState,
+	}
+
+	for name, state := range roleServices {
+		if err := r.reconcileRoleService(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: name}, &ts, state); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// reconcileRoleService maintains a service selecting the ready nodes of a raft role by the label ReconcileQuorum puts
+// on the pods, so writes can be sent to the leader directly and searches kept on the followers
+func (r *TypesenseClusterReconciler) reconcileRoleService(ctx context.Context, key client.ObjectKey, ts *tsv1alpha1.TypesenseCluster, state NodeState) error {
+	desired := r.buildRoleService(key, ts, state)
+
+	var svc = &v1.Service{}
+	if err := r.Get(ctx, key, svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch service: %s", key.Name))
+			return err
+		}
+
+		r.logger.V(debugLevel).Info("creating role service", "service", key.Name)
+
+		err := ctrl.SetControllerReference(ts, desired, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		if err := r.Create(ctx, desired); err != nil {
+			r.logger.Error(err, "creating role service failed", "service", key.Name)
+			return err
+		}
+
+		return nil
+	}
+
+	if !apiequality.Semantic.DeepEqual(svc.Spec.Selector, desired.Spec.Selector) || !apiequality.Semantic.DeepEqual(svc.Spec.Ports, desired.Spec.Ports) {
+		r.logger.V(debugLevel).Info("updating role service", "service", key.Name)
+
+		patch := client.MergeFrom(svc.DeepCopy())
+		svc.Spec.Selector = desired.Spec.Selector
+		svc.Spec.Ports = desired.Spec.Ports
+
+		if err := r.Patch(ctx, svc, patch); err != nil {
+			r.logger.Error(err, "updating role service failed", "service", key.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) buildRoleService(key client.ObjectKey, ts *tsv1alpha1.TypesenseCluster, state NodeState) *v1.Service {
+	selector := getLabels(ts)
+	selector[RaftRoleLabelKey] = strings.ToLower(string(state))
+
+	return &v1.Service{
+		ObjectMeta: getObjectMeta(ts, &key.Name, nil),
+		Spec: v1.ServiceSpec{
+			Type:     v1.ServiceTypeClusterIP,
+			Selector: selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   v1.ProtocolTCP,
+					Port:       int32(ts.Spec.ApiPort),
+					TargetPort: intstr.IntOrString{IntVal: 8108},
+				},
+			},
+		},
+	}
+}
+
 func (r *TypesenseClusterReconciler) createHeadlessService(ctx context.Context, key client.ObjectKey, ts *tsv1alpha1.TypesenseCluster) (*v1.Service, error) {
 	svc := &v1.Service{
 		ObjectMeta: getObjectMeta(ts, &key.Name, nil),
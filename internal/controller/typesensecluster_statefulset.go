@@ -73,6 +73,7 @@ func (r *TypesenseClusterReconciler) ReconcileStatefulSet(ctx context.Context, t
 			ConditionReasonStatefulSetNotReady,
 			ConditionReasonReconciliationInProgress,
 			string(ConditionReasonQuorumNotReadyWaitATerm),
+			string(ConditionReasonQuorumRestoring),
 		}
 
 		condition := r.getConditionReady(ts)
@@ -193,7 +194,10 @@ func (r *TypesenseClusterReconciler) createStatefulSet(ctx context.Context, key
 
 func (r *TypesenseClusterReconciler) updateStatefulSet(ctx context.Context, sts *appsv1.StatefulSet, desired *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
 	patch := client.MergeFrom(sts.DeepCopy())
+	// volume claim templates are immutable, they are changed only by recreating the statefulset in ReconcileStorage
+	volumeClaimTemplates := sts.Spec.VolumeClaimTemplates
 	sts.Spec = desired.Spec
+	sts.Spec.VolumeClaimTemplates = volumeClaimTemplates
 
 	sts.ObjectMeta.Annotations = desired.ObjectMeta.Annotations
 
@@ -216,6 +220,9 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 	podAnnotations := make(map[string]string)
 	podAnnotations[readLagAnnotationKey] = strconv.Itoa(readLagThreshold)
 	podAnnotations[writeLagAnnotationKey] = strconv.Itoa(writeLagThreshold)
+	if revision := getAdminApiKeyRevision(ts); revision != "" {
+		podAnnotations[adminApiKeyRevisionAnnotationKey] = revision
+	}
 	if ts.Spec.PodAnnotations != nil {
 		for k, v := range ts.Spec.PodAnnotations {
 			podAnnotations[k] = v
@@ -232,6 +239,8 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 		}
 	}
 
+	restoreContainers, restoreVolumes := r.buildRestoreInitContainers(ts)
+
 	clusterName := ts.Name
 	podManagementPolicy := appsv1.ParallelPodManagement
 	if ts.Spec.GetPodManagementPolicy() == "OrderedReady" {
@@ -244,7 +253,12 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 		Spec: appsv1.StatefulSetSpec{
 			ServiceName:         fmt.Sprintf(ClusterHeadlessService, clusterName),
 			PodManagementPolicy: podManagementPolicy,
-			Replicas:            ptr.To[int32](ts.Spec.Replicas),
+			// pods are restarted by ReconcileRollingUpdate, followers first and the leader last
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
+			},
+			PersistentVolumeClaimRetentionPolicy: getVolumeClaimRetentionPolicy(ts),
+			Replicas:                             ptr.To[int32](ts.Spec.Replicas),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: getLabels(ts),
 			},
@@ -260,6 +274,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 					},
 					PriorityClassName: ptr.Deref[string](ts.Spec.PriorityClassName, ""),
 					ImagePullSecrets:  ts.Spec.ImagePullSecrets,
+					InitContainers:    restoreContainers,
 					Containers: []corev1.Container{
 						{
 							Name:            "typesense",
@@ -279,7 +294,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 										SecretKeyRef: &corev1.SecretKeySelector{
 											Key: ClusterAdminApiKeySecretKeyName,
 											LocalObjectReference: corev1.LocalObjectReference{
-												Name: r.getAdminApiKeyObjectKey(ts).Name,
+												Name: getAdminApiKeyObjectKey(ts).Name,
 											},
 										},
 									},
@@ -322,6 +337,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 							},
 							EnvFrom:   ts.Spec.GetAdditionalServerConfiguration(),
 							Resources: ts.Spec.GetResources(),
+							Lifecycle: getLeaderStepDownLifecycle(ts),
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									MountPath: "/usr/share/typesense",
@@ -351,7 +367,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 										SecretKeyRef: &corev1.SecretKeySelector{
 											Key: ClusterAdminApiKeySecretKeyName,
 											LocalObjectReference: corev1.LocalObjectReference{
-												Name: r.getAdminApiKeyObjectKey(ts).Name,
+												Name: getAdminApiKeyObjectKey(ts).Name,
 											},
 										},
 									},
@@ -362,7 +378,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 								},
 								{
 									Name:  "TYPESENSE_PROTOCOL",
-									Value: "http",
+									Value: ts.Spec.GetApiScheme(),
 								},
 								{
 									Name:  "TYPESENSE_HOST",
@@ -401,7 +417,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 										SecretKeyRef: &corev1.SecretKeySelector{
 											Key: ClusterAdminApiKeySecretKeyName,
 											LocalObjectReference: corev1.LocalObjectReference{
-												Name: r.getAdminApiKeyObjectKey(ts).Name,
+												Name: getAdminApiKeyObjectKey(ts).Name,
 											},
 										},
 									},
@@ -412,7 +428,7 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 								},
 								{
 									Name:  "TYPESENSE_PROTOCOL",
-									Value: "http",
+									Value: ts.Spec.GetApiScheme(),
 								},
 								{
 									Name:  "TYPESENSE_API_PORT",
@@ -487,13 +503,17 @@ func (r *TypesenseClusterReconciler) buildStatefulSet(ctx context.Context, key c
 								corev1.ResourceStorage: ts.Spec.GetStorage().Size,
 							},
 						},
-						StorageClassName: &ts.Spec.Storage.StorageClassName,
+						StorageClassName: getStorageClassName(ts),
 					},
 				},
 			},
 		},
 	}
 
+	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, restoreVolumes...)
+	configureStatefulSetTLS(sts, ts)
+	configureStatefulSetSnapshots(sts, ts)
+
 	base16Hash, err := r.buildStatefulSetHash(ctx, sts, ts)
 	if err != nil {
 		return nil, err
@@ -531,6 +551,8 @@ func (r *TypesenseClusterReconciler) ScaleStatefulSet(ctx context.Context, stsOb
 }
 
 func (r *TypesenseClusterReconciler) PurgeStatefulSetPods(ctx context.Context, sts *appsv1.StatefulSet, ts *tsv1alpha1.TypesenseCluster) error {
+	quorumPurgesTotal.WithLabelValues(ts.Namespace, ts.Name).Inc()
+
 	labelSelector := labels.SelectorFromSet(sts.Spec.Selector.MatchLabels)
 
 	var pods corev1.PodList
@@ -0,0 +1,393 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	storageExpansionRequeuePeriod = 15 * time.Second
+
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+// ReconcileStorage expands the data volumes when the size of the storage grows. The existing claims are patched in
+// place, as long as their StorageClass allows it, and the StatefulSet is deleted orphaning its pods, so it gets
+// recreated with the new volume claim template. It returns true while the StatefulSet is being recreated.
+func (r *TypesenseClusterReconciler) ReconcileStorage(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (bool, error) {
+	r.logger.V(debugLevel).Info("reconciling storage")
+
+	stsName := fmt.Sprintf(ClusterStatefulSet, ts.Name)
+	stsObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: stsName}
+
+	var sts = &appsv1.StatefulSet{}
+	if err := r.Get(ctx, stsObjectKey, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch statefulset: %s", stsName))
+		return false, err
+	}
+
+	if sts.DeletionTimestamp != nil {
+		r.logger.V(debugLevel).Info("waiting for statefulset to be deleted", "sts", stsName)
+		return true, nil
+	}
+
+	err := r.reconcileVolumeClaimRetentionPolicy(ctx, ts, sts)
+	if err != nil {
+		return false, err
+	}
+
+	current, ok := getVolumeClaimTemplateSize(sts)
+	if !ok {
+		return false, nil
+	}
+
+	storage := ts.Spec.GetStorage()
+	desired := storage.Size
+
+	switch desired.Cmp(current) {
+	case -1:
+		message := fmt.Sprintf("storage cannot shrink from %s to %s", current.String(), desired.String())
+		return false, r.setConditionStorage(ctx, ts, metav1.ConditionFalse, ConditionReasonStorageShrinkNotSupported, message)
+	case 0:
+		return false, r.reconcileVolumeClaimsStatus(ctx, ts, sts, desired)
+	}
+
+	pvcs, err := r.getDataVolumeClaims(ctx, ts, sts)
+	if err != nil {
+		return false, err
+	}
+
+	// the claims are bound to the class they were created with, the default one if none was set back then
+	for _, name := range getVolumeClaimsStorageClassNames(pvcs, storage.StorageClassName) {
+		storageClassName, err := r.resolveStorageClassName(ctx, name)
+		if err != nil {
+			return false, err
+		}
+
+		expandable, err := r.isVolumeExpansionAllowed(ctx, storageClassName)
+		if err != nil {
+			return false, err
+		}
+
+		if !expandable {
+			message := getVolumeExpansionNotAllowedMessage(storageClassName)
+			return false, r.setConditionStorage(ctx, ts, metav1.ConditionFalse, ConditionReasonStorageExpansionNotSupported, message)
+		}
+	}
+
+	r.logger.Info("expanding storage", "from", current.String(), "to", desired.String())
+
+	for _, pvc := range pvcs {
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if requested.Cmp(desired) >= 0 {
+			continue
+		}
+
+		r.logger.V(debugLevel).Info("expanding persistent volume claim", "pvc", pvc.Name, "from", requested.String(), "to", desired.String())
+
+		patch := client.MergeFrom(pvc.DeepCopy())
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+		if err := r.Patch(ctx, &pvc, patch); err != nil {
+			r.logger.Error(err, "expanding persistent volume claim failed", "pvc", pvc.Name)
+			return false, err
+		}
+	}
+
+	err = r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Storage = &tsv1alpha1.StorageStatus{
+			Size:         desired,
+			VolumeClaims: getVolumeClaimsStatus(pvcs, desired),
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	message := fmt.Sprintf("expanding data volumes from %s to %s", current.String(), desired.String())
+	err = r.setConditionStorage(ctx, ts, metav1.ConditionFalse, ConditionReasonStorageExpanding, message)
+	if err != nil {
+		return false, err
+	}
+
+	// the volume claim templates are immutable, so the statefulset is recreated while its pods keep running
+	r.logger.Info("deleting statefulset orphaning its pods", "sts", stsName)
+	err = r.Delete(ctx, sts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil && !apierrors.IsNotFound(err) {
+		r.logger.Error(err, "deleting statefulset failed", "sts", stsName)
+		return false, err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", ConditionReasonStorageExpanding, toTitle(message))
+
+	return true, nil
+}
+
+// reconcileVolumeClaimRetentionPolicy patches the retention policy of the data volumes in place, it is mutable and
+// does not require the pods to be restarted
+func (r *TypesenseClusterReconciler) reconcileVolumeClaimRetentionPolicy(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, sts *appsv1.StatefulSet) error {
+	desired := getVolumeClaimRetentionPolicy(ts)
+	if sts.Spec.PersistentVolumeClaimRetentionPolicy != nil && *sts.Spec.PersistentVolumeClaimRetentionPolicy == *desired {
+		return nil
+	}
+
+	r.logger.V(debugLevel).Info("updating persistent volume claim retention policy", "sts", sts.Name, "whenDeleted", desired.WhenDeleted)
+
+	patch := client.MergeFrom(sts.DeepCopy())
+	sts.Spec.PersistentVolumeClaimRetentionPolicy = desired
+	if err := r.Patch(ctx, sts, patch); err != nil {
+		r.logger.Error(err, "updating persistent volume claim retention policy failed", "sts", sts.Name)
+		return err
+	}
+
+	return nil
+}
+
+// reconcileVolumeClaimsStatus reports the progress of an ongoing expansion, until every claim reports the new capacity
+func (r *TypesenseClusterReconciler) reconcileVolumeClaimsStatus(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, sts *appsv1.StatefulSet, size resource.Quantity) error {
+	condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypeStorageExpanded)
+	if condition == nil || condition.Status == metav1.ConditionTrue {
+		return nil
+	}
+
+	if condition.Reason != ConditionReasonStorageExpanding {
+		// the size has been reverted after a rejected change
+		return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			meta.RemoveStatusCondition(&status.Conditions, ConditionTypeStorageExpanded)
+		})
+	}
+
+	pvcs, err := r.getDataVolumeClaims(ctx, ts, sts)
+	if err != nil {
+		return err
+	}
+
+	volumeClaims := getVolumeClaimsStatus(pvcs, size)
+	err = r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.Storage = &tsv1alpha1.StorageStatus{
+			Size:         size,
+			VolumeClaims: volumeClaims,
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, vc := range volumeClaims {
+		if vc.Phase != tsv1alpha1.VolumeClaimResized {
+			r.logger.V(debugLevel).Info("waiting for persistent volume claim to be resized", "pvc", vc.Name, "phase", vc.Phase)
+			return nil
+		}
+	}
+
+	message := fmt.Sprintf("data volumes expanded to %s", size.String())
+	err = r.setConditionStorage(ctx, ts, metav1.ConditionTrue, ConditionReasonStorageExpanded, message)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("expanding storage completed", "size", size.String())
+	r.Recorder.Eventf(ts, "Normal", ConditionReasonStorageExpanded, toTitle(message))
+
+	return nil
+}
+
+func (r *TypesenseClusterReconciler) setConditionStorage(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, conditionStatus metav1.ConditionStatus, reason string, message string) error {
+	condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypeStorageExpanded)
+	if condition != nil && condition.Status == conditionStatus && condition.Reason == reason && condition.Message == message {
+		return nil
+	}
+
+	if conditionStatus == metav1.ConditionFalse && reason != ConditionReasonStorageExpanding {
+		r.logger.Info("rejecting storage change", "reason", message)
+		r.Recorder.Eventf(ts, "Warning", reason, toTitle(message))
+	}
+
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeStorageExpanded, Status: conditionStatus, Reason: reason, Message: message})
+	})
+}
+
+func (r *TypesenseClusterReconciler) isVolumeExpansionAllowed(ctx context.Context, storageClassName string) (bool, error) {
+	if storageClassName == "" {
+		return false, nil
+	}
+
+	var sc = &storagev1.StorageClass{}
+	if err := r.Get(ctx, client.ObjectKey{Name: storageClassName}, sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch storage class: %s", storageClassName))
+		return false, err
+	}
+
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// resolveStorageClassName returns the name of the default storage class of the cluster when the name is empty, the
+// same way the admission controller picks it for claims created without one, or empty if there is no default class
+func (r *TypesenseClusterReconciler) resolveStorageClassName(ctx context.Context, storageClassName string) (string, error) {
+	if storageClassName != "" {
+		return storageClassName, nil
+	}
+
+	var scs storagev1.StorageClassList
+	if err := r.List(ctx, &scs); err != nil {
+		r.logger.Error(err, "unable to list storage classes")
+		return "", err
+	}
+
+	var defaultClass *storagev1.StorageClass
+	for i, sc := range scs.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] != "true" && sc.Annotations[betaDefaultStorageClassAnnotation] != "true" {
+			continue
+		}
+
+		// the most recently created default class wins, like in the admission controller
+		if defaultClass == nil || defaultClass.CreationTimestamp.Before(&sc.CreationTimestamp) {
+			defaultClass = &scs.Items[i]
+		}
+	}
+
+	if defaultClass == nil {
+		return "", nil
+	}
+
+	return defaultClass.Name, nil
+}
+
+func getVolumeExpansionNotAllowedMessage(storageClassName string) string {
+	if storageClassName == "" {
+		return "no storage class is set and there is no default storage class that allows volume expansion"
+	}
+
+	return fmt.Sprintf("storage class %s does not allow volume expansion", storageClassName)
+}
+
+// getVolumeClaimsStorageClassNames returns the distinct storage classes of the claims, or the desired one if there are
+// no claims yet
+func getVolumeClaimsStorageClassNames(pvcs []corev1.PersistentVolumeClaim, desired string) []string {
+	if len(pvcs) == 0 {
+		return []string{desired}
+	}
+
+	var names []string
+	for _, pvc := range pvcs {
+		name := ptr.Deref(pvc.Spec.StorageClassName, desired)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// getDataVolumeClaims returns the data-<sts>-N claims of the statefulset, including those of scaled down replicas
+func (r *TypesenseClusterReconciler) getDataVolumeClaims(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, sts *appsv1.StatefulSet) ([]corev1.PersistentVolumeClaim, error) {
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &pvcs, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		r.logger.Error(err, "failed to list persistent volume claims", "statefulset", sts.Name)
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf(ClusterDataVolumeClaim, sts.Name) + "-"
+	claims := make([]corev1.PersistentVolumeClaim, 0, len(pvcs.Items))
+	for _, pvc := range pvcs.Items {
+		if strings.HasPrefix(pvc.Name, prefix) && pvc.DeletionTimestamp == nil {
+			claims = append(claims, pvc)
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].Name < claims[j].Name
+	})
+
+	return claims, nil
+}
+
+func getVolumeClaimsStatus(pvcs []corev1.PersistentVolumeClaim, size resource.Quantity) []tsv1alpha1.VolumeClaimStatus {
+	volumeClaims := make([]tsv1alpha1.VolumeClaimStatus, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+
+		phase := tsv1alpha1.VolumeClaimResizing
+		if capacity.Cmp(size) >= 0 {
+			phase = tsv1alpha1.VolumeClaimResized
+		} else {
+			for _, condition := range pvc.Status.Conditions {
+				if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+					phase = tsv1alpha1.VolumeClaimFileSystemResizePending
+				}
+			}
+		}
+
+		volumeClaims = append(volumeClaims, tsv1alpha1.VolumeClaimStatus{
+			Name:      pvc.Name,
+			Requested: requested,
+			Capacity:  capacity,
+			Phase:     phase,
+		})
+	}
+
+	return volumeClaims
+}
+
+// getVolumeClaimRetentionPolicy keeps the data volumes of the nodes removed by a scale down, so they rejoin with their
+// data when the cluster scales up again
+func getVolumeClaimRetentionPolicy(ts *tsv1alpha1.TypesenseCluster) *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	storage := ts.Spec.GetStorage()
+	return &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: storage.GetRetentionPolicy(),
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+}
+
+func getVolumeClaimTemplateSize(sts *appsv1.StatefulSet) (resource.Quantity, bool) {
+	for _, vct := range sts.Spec.VolumeClaimTemplates {
+		if vct.Name == "data" {
+			size, ok := vct.Spec.Resources.Requests[corev1.ResourceStorage]
+			return size, ok
+		}
+	}
+
+	return resource.Quantity{}, false
+}
+
+// getStorageClassName returns the storage class of the data volumes, nil if it is empty so the default class applies
+func getStorageClassName(ts *tsv1alpha1.TypesenseCluster) *string {
+	storageClassName := ts.Spec.GetStorage().StorageClassName
+	if storageClassName == "" {
+		return nil
+	}
+
+	return &storageClassName
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Storage", func() {
+	ctx := context.Background()
+
+	Context("When the storage class of the data volumes is left empty", func() {
+		const resourceName = "test-storage-default-class"
+		const defaultClassName = "default-expandable"
+
+		stsName := fmt.Sprintf(ClusterStatefulSet, resourceName)
+		pvcName := fmt.Sprintf("%s-0", fmt.Sprintf(ClusterDataVolumeClaim, stsName))
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var controllerReconciler *TypesenseClusterReconciler
+
+		BeforeEach(func() {
+			ts = &tsv1alpha1.TypesenseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseClusterSpec{
+					Storage: &tsv1alpha1.StorageSpec{Size: resource.MustParse("2Gi")},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			for _, sc := range []*storagev1.StorageClass{
+				{
+					ObjectMeta:  metav1.ObjectMeta{Name: "not-default"},
+					Provisioner: "kubernetes.io/no-provisioner",
+				},
+				{
+					ObjectMeta:           metav1.ObjectMeta{Name: defaultClassName, Annotations: map[string]string{defaultStorageClassAnnotation: "true"}},
+					Provisioner:          "kubernetes.io/no-provisioner",
+					AllowVolumeExpansion: ptr.To(true),
+				},
+			} {
+				Expect(k8sClient.Create(ctx, sc)).To(Succeed())
+			}
+
+			labels := getLabels(ts)
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: stsName, Namespace: "default"},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: ptr.To(int32(1)),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}}},
+					},
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+						ObjectMeta: metav1.ObjectMeta{Name: "data"},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							Resources: corev1.VolumeResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+							},
+						},
+					}},
+					PersistentVolumeClaimRetentionPolicy: getVolumeClaimRetentionPolicy(ts),
+				},
+			}
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default", Labels: labels},
+				Spec:       sts.Spec.VolumeClaimTemplates[0].Spec,
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+			controllerReconciler = &TypesenseClusterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default"}})).To(Succeed())
+			err := k8sClient.Delete(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: stsName, Namespace: "default"}})
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+			for _, name := range []string{"not-default", defaultClassName} {
+				Expect(k8sClient.Delete(ctx, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}})).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+		})
+
+		It("should resolve the default storage class", func() {
+			Expect(controllerReconciler.resolveStorageClassName(ctx, "")).To(Equal(defaultClassName))
+			Expect(controllerReconciler.resolveStorageClassName(ctx, "not-default")).To(Equal("not-default"))
+		})
+
+		It("should expand the claims with the default storage class", func() {
+			recreating, err := controllerReconciler.ReconcileStorage(ctx, ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recreating).To(BeTrue())
+
+			condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypeStorageExpanded)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(ConditionReasonStorageExpanding))
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: pvcName, Namespace: "default"}, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("2Gi")))
+
+			By("checking that the statefulset is being recreated")
+			sts := &appsv1.StatefulSet{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: stsName, Namespace: "default"}, sts)
+			if !apierrors.IsNotFound(err) {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sts.DeletionTimestamp).NotTo(BeNil())
+			}
+		})
+
+		It("should reject the expansion when there is no default storage class", func() {
+			sc := &storagev1.StorageClass{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: defaultClassName}, sc)).To(Succeed())
+			sc.Annotations = nil
+			Expect(k8sClient.Update(ctx, sc)).To(Succeed())
+
+			recreating, err := controllerReconciler.ReconcileStorage(ctx, ts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recreating).To(BeFalse())
+
+			condition := meta.FindStatusCondition(ts.Status.Conditions, ConditionTypeStorageExpanded)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(ConditionReasonStorageExpansionNotSupported))
+			Expect(condition.Message).To(ContainSubstring("no default storage class"))
+		})
+	})
+})
@@ -0,0 +1,398 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	tlsVolumeName            = "tls"
+	tlsMountPath             = "/etc/typesense/tls"
+	tlsCAKey                 = "ca.crt"
+	tlsChecksumAnnotationKey = "ts.opentelekomcloud.com/tls-certificate-checksum"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// ReconcileTLS requests the certificate from cert-manager, when an issuer is referenced, and records the checksum of
+// the certificate in the status. The checksum is stamped on the pod template and the scrapers, so a renewed
+// certificate rolls the pods. It returns false while the certificate Secret is not available yet.
+func (r *TypesenseClusterReconciler) ReconcileTLS(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (bool, error) {
+	r.logger.V(debugLevel).Info("reconciling tls")
+
+	if !ts.Spec.IsTLSEnabled() {
+		if ts.Status.TLS == nil {
+			return true, nil
+		}
+
+		if err := r.deleteCertificate(ctx, ts, ts.Status.TLS.Certificate); err != nil {
+			return false, err
+		}
+
+		return true, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.TLS = nil
+		})
+	}
+
+	secretName := getTLSSecretName(ts)
+	certificateName := ""
+
+	if ts.Spec.TLS.IssuerRef != nil {
+		certificateName = fmt.Sprintf(ClusterCertificate, ts.Name)
+		if err := r.reconcileCertificate(ctx, ts, certificateName, secretName); err != nil {
+			return false, err
+		}
+	} else if ts.Status.TLS != nil {
+		if err := r.deleteCertificate(ctx, ts, ts.Status.TLS.Certificate); err != nil {
+			return false, err
+		}
+	}
+
+	var secret = &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: secretName}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for certificate secret", "secret", secretName)
+			return false, nil
+		}
+
+		r.logger.Error(err, fmt.Sprintf("unable to fetch secret: %s", secretName))
+		return false, err
+	}
+
+	crt, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(crt) == 0 || len(key) == 0 {
+		r.logger.V(debugLevel).Info("waiting for certificate to be issued", "secret", secretName)
+		return false, nil
+	}
+
+	desired := &tsv1alpha1.TLSStatus{
+		SecretName:  secretName,
+		Certificate: certificateName,
+		Checksum:    fmt.Sprintf("%x", sha256.Sum256(append(append([]byte{}, crt...), key...))),
+		HasCA:       len(secret.Data[tlsCAKey]) > 0,
+		NotAfter:    getCertificateNotAfter(crt),
+	}
+
+	if apiequality.Semantic.DeepEqual(ts.Status.TLS, desired) {
+		return true, nil
+	}
+
+	if ts.Status.TLS != nil && ts.Status.TLS.Checksum != "" && ts.Status.TLS.Checksum != desired.Checksum {
+		r.logger.Info("certificate changed, rolling pods", "secret", secretName)
+		r.Recorder.Eventf(ts, "Normal", "CertificateChanged", "Certificate in secret %s changed, rolling pods", secretName)
+	}
+
+	return true, r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.TLS = desired
+	})
+}
+
+// reconcileCertificate creates or updates the cert-manager Certificate; it is handled as unstructured, so the
+// operator does not depend on cert-manager unless an issuer is referenced
+func (r *TypesenseClusterReconciler) reconcileCertificate(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, certificateName string, secretName string) error {
+	certificateObjectKey := client.ObjectKey{Namespace: ts.Namespace, Name: certificateName}
+	certificateExists := true
+
+	var certificate = &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	if err := r.Get(ctx, certificateObjectKey, certificate); err != nil {
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("cert-manager is not installed, certificates cannot be issued: %w", err)
+		}
+
+		if !apierrors.IsNotFound(err) {
+			r.logger.Error(err, fmt.Sprintf("unable to fetch certificate: %s", certificateName))
+			return err
+		}
+
+		certificateExists = false
+	}
+
+	issuerRef := ts.Spec.TLS.IssuerRef
+	dnsNames := make([]any, 0)
+	for _, dnsName := range getCertificateDNSNames(ts) {
+		dnsNames = append(dnsNames, dnsName)
+	}
+
+	desired := map[string]any{
+		"secretName":  secretName,
+		"dnsNames":    dnsNames,
+		"ipAddresses": []any{"127.0.0.1"},
+		"usages":      []any{"server auth", "digital signature", "key encipherment"},
+		"issuerRef": map[string]any{
+			"name":  issuerRef.Name,
+			"kind":  issuerRef.Kind,
+			"group": issuerRef.Group,
+		},
+	}
+
+	if !certificateExists {
+		r.logger.V(debugLevel).Info("creating certificate", "certificate", certificateName)
+
+		certificate.SetName(certificateName)
+		certificate.SetNamespace(ts.Namespace)
+		certificate.SetLabels(getLabels(ts))
+		certificate.Object["spec"] = desired
+
+		if err := ctrl.SetControllerReference(ts, certificate, r.Scheme); err != nil {
+			return err
+		}
+
+		if err := r.Create(ctx, certificate); err != nil {
+			r.logger.Error(err, "creating certificate failed", "certificate", certificateName)
+			return err
+		}
+
+		return nil
+	}
+
+	spec, _, _ := unstructured.NestedMap(certificate.Object, "spec")
+	if spec == nil {
+		spec = map[string]any{}
+	}
+
+	changed := false
+	for k, v := range desired {
+		if !apiequality.Semantic.DeepEqual(spec[k], v) {
+			spec[k] = v
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	r.logger.V(debugLevel).Info("updating certificate", "certificate", certificateName)
+
+	certificate.Object["spec"] = spec
+	if err := r.Update(ctx, certificate); err != nil {
+		r.logger.Error(err, "updating certificate failed", "certificate", certificateName)
+		return err
+	}
+
+	return nil
+}
+
+// deleteCertificate deletes the Certificate once the issuer is no longer referenced; the Secret cert-manager wrote
+// is left in place
+func (r *TypesenseClusterReconciler) deleteCertificate(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, certificateName string) error {
+	if certificateName == "" {
+		return nil
+	}
+
+	var certificate = &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(certificateName)
+	certificate.SetNamespace(ts.Namespace)
+
+	err := r.Delete(ctx, certificate)
+	if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		r.logger.Error(err, "deleting certificate failed", "certificate", certificate.GetName())
+		return err
+	}
+
+	return nil
+}
+
+// configureStatefulSetTLS mounts the certificate and points Typesense at it. The sidecars trust the CA, when the
+// Secret carries one, and the checksum annotation rolls the pods when the certificate changes.
+func configureStatefulSetTLS(sts *appsv1.StatefulSet, ts *tsv1alpha1.TypesenseCluster) {
+	if !ts.Spec.IsTLSEnabled() {
+		return
+	}
+
+	podSpec := &sts.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, getTLSVolume(ts))
+
+	mount := corev1.VolumeMount{Name: tlsVolumeName, MountPath: tlsMountPath, ReadOnly: true}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+
+		switch container.Name {
+		case "typesense":
+			container.Env = append(container.Env,
+				corev1.EnvVar{Name: "TYPESENSE_SSL_CERTIFICATE", Value: path.Join(tlsMountPath, corev1.TLSCertKey)},
+				corev1.EnvVar{Name: "TYPESENSE_SSL_CERTIFICATE_KEY", Value: path.Join(tlsMountPath, corev1.TLSPrivateKeyKey)},
+			)
+		default:
+			container.Env = append(container.Env, getTLSCAEnv(ts)...)
+		}
+
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+	}
+
+	if ts.Status.TLS != nil {
+		sts.Spec.Template.Annotations[tlsChecksumAnnotationKey] = ts.Status.TLS.Checksum
+	}
+}
+
+// configureScraperTLS mounts only the CA of the certificate in the scrapers, the private key stays with the pods
+func configureScraperTLS(podSpec *corev1.PodSpec, ts *tsv1alpha1.TypesenseCluster) {
+	env := getTLSCAEnv(ts)
+	if env == nil {
+		return
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: tlsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: getTLSSecretName(ts),
+				Items:      []corev1.KeyToPath{{Key: tlsCAKey, Path: tlsCAKey}},
+			},
+		},
+	})
+
+	mount := corev1.VolumeMount{Name: tlsVolumeName, MountPath: tlsMountPath, ReadOnly: true}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, env...)
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, mount)
+	}
+}
+
+func getTLSVolume(ts *tsv1alpha1.TypesenseCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: tlsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: getTLSSecretName(ts),
+			},
+		},
+	}
+}
+
+// getTLSCAEnv points the Go and Python clients of the sidecars and the scrapers at the CA of the certificate
+func getTLSCAEnv(ts *tsv1alpha1.TypesenseCluster) []corev1.EnvVar {
+	if ts.Status.TLS == nil || !ts.Status.TLS.HasCA {
+		return nil
+	}
+
+	caFile := path.Join(tlsMountPath, tlsCAKey)
+	return []corev1.EnvVar{
+		{Name: "SSL_CERT_FILE", Value: caFile},
+		{Name: "REQUESTS_CA_BUNDLE", Value: caFile},
+	}
+}
+
+func getTLSChecksum(ts *tsv1alpha1.TypesenseCluster) string {
+	if !ts.Spec.IsTLSEnabled() || ts.Status.TLS == nil {
+		return ""
+	}
+
+	return ts.Status.TLS.Checksum
+}
+
+func getTLSSecretName(ts *tsv1alpha1.TypesenseCluster) string {
+	if ts.Spec.TLS != nil && ts.Spec.TLS.SecretName != nil {
+		return *ts.Spec.TLS.SecretName
+	}
+
+	return fmt.Sprintf(ClusterTLSSecret, ts.Name)
+}
+
+// getCertificateDNSNames returns the names the api is reached with: the services, the pods via the headless service
+// and localhost for the sidecars
+func getCertificateDNSNames(ts *tsv1alpha1.TypesenseCluster) []string {
+	dnsNames := make([]string, 0)
+	for _, svc := range []string{fmt.Sprintf(ClusterRestService, ts.Name), "*." + fmt.Sprintf(ClusterHeadlessService, ts.Name)} {
+		dnsNames = append(dnsNames,
+			svc,
+			fmt.Sprintf("%s.%s", svc, ts.Namespace),
+			fmt.Sprintf("%s.%s.svc", svc, ts.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", svc, ts.Namespace),
+		)
+	}
+
+	dnsNames = append(dnsNames, "localhost")
+	return append(dnsNames, ts.Spec.TLS.DNSNames...)
+}
+
+func getCertificateNotAfter(crt []byte) *metav1.Time {
+	block, _ := pem.Decode(crt)
+	if block == nil {
+		return nil
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+
+	notAfter := metav1.NewTime(certificate.NotAfter)
+	return &notAfter
+}
+
+// newTLSClientConfig returns the configuration the operator probes the nodes with, trusting the CA of the certificate
+// or, without one, the system roots. The nodes are addressed by their pod IP, which the certificate does not carry, so
+// the chain of the certificate is verified but its host names are not.
+func newTLSClientConfig(ctx context.Context, c client.Client, ts *tsv1alpha1.TypesenseCluster) (*tls.Config, error) {
+	if !ts.Spec.IsTLSEnabled() {
+		return nil, nil
+	}
+
+	var secret = &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ts.Namespace, Name: getTLSSecretName(ts)}, secret); err != nil {
+		return nil, err
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if ca := secret.Data[tlsCAKey]; len(ca) > 0 {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("secret %s has an invalid '%s' key", secret.Name, tlsCAKey)
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return &tls.CertificateVerificationError{Err: fmt.Errorf("no certificate presented")}
+			}
+
+			opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+			for _, intermediate := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(intermediate)
+			}
+
+			if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+				return &tls.CertificateVerificationError{UnverifiedCertificates: cs.PeerCertificates, Err: err}
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// isCertificateVerificationError reports whether the node was reached but its certificate could not be verified,
+// e.g. because the CA is wrong or missing, which says nothing about the health of the node
+func isCertificateVerificationError(err error) bool {
+	var verificationError *tls.CertificateVerificationError
+	return errors.As(err, &verificationError)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster TLS", func() {
+	ctx := context.Background()
+
+	var server *httptest.Server
+	var port int
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJson(w, http.StatusOK, NodeStatus{State: LeaderState, CommittedIndex: 5})
+		}))
+
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err = strconv.Atoi(u.Port())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newCluster := func(name string) *tsv1alpha1.TypesenseCluster {
+		return &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: tsv1alpha1.TypesenseClusterSpec{
+				ApiPort: port,
+				TLS:     &tsv1alpha1.TLSSpec{SecretName: ptr.To(fmt.Sprintf(ClusterTLSSecret, name))},
+			},
+		}
+	}
+
+	newSecret := func(ts *tsv1alpha1.TypesenseCluster, trusted bool) *corev1.Secret {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: getTLSSecretName(ts), Namespace: ts.Namespace},
+			Data:       map[string][]byte{},
+		}
+		if trusted {
+			secret.Data[tlsCAKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		}
+
+		return secret
+	}
+
+	DescribeTable("probing a node",
+		func(trusted bool, expected NodeState) {
+			ts := newCluster("test-tls-probe")
+			secret := newSecret(ts, trusted)
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}()
+
+			tlsConfig, err := newTLSClientConfig(ctx, k8sClient, ts)
+			Expect(err).NotTo(HaveOccurred())
+			httpClient, err := newHttpClient(nil, true, time.Second, tlsConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			controllerReconciler := &TypesenseClusterReconciler{InCluster: true}
+			node := NodeEndpoint{PodName: "test-tls-probe-sts-0", IP: net.ParseIP("127.0.0.1")}
+			status, err := controllerReconciler.getNodeStatus(ctx, httpClient, node, ts, &corev1.Secret{}, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.State).To(Equal(expected))
+		},
+		Entry("the certificate is signed by the CA of the secret", true, LeaderState),
+		Entry("the certificate cannot be verified", false, UntrustedState),
+	)
+
+	Context("When the certificate of the nodes cannot be verified during a rollout", func() {
+		const resourceName = "test-tls-rollout"
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var secret *corev1.Secret
+		var pods []corev1.Pod
+
+		BeforeEach(func() {
+			ts = newCluster(resourceName)
+			Expect(k8sClient.Create(ctx, ts)).To(Succeed())
+
+			secret = newSecret(ts, false)
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			sts := newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1")
+			status := sts.Status
+			Expect(k8sClient.Create(ctx, sts)).To(Succeed())
+			sts.Status = status
+			sts.Status.ObservedGeneration = sts.Generation
+			Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+			pods = nil
+			for i := range 3 {
+				pod := newStatefulSetPod(sts, i, sts.Status.CurrentRevision, true)
+				podStatus := pod.Status
+				podStatus.PodIP = "127.0.0.1"
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+				pod.Status = podStatus
+				Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+				pods = append(pods, pod)
+			}
+		})
+
+		AfterEach(func() {
+			for _, pod := range pods {
+				Expect(k8sClient.Delete(ctx, &pod)).To(Succeed())
+			}
+			Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secret.Name, Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ts)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, newRolledOutStatefulSet(fmt.Sprintf(ClusterStatefulSet, resourceName), 3, "1"))).To(Succeed())
+		})
+
+		It("should pause the rollout without restarting any node", func() {
+			controllerReconciler := &TypesenseClusterReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				Recorder:  record.NewFakeRecorder(10),
+				InCluster: true,
+			}
+
+			rolling, err := controllerReconciler.ReconcileRollingUpdate(ctx, ts, &corev1.Secret{}, ConditionQuorum(ConditionReasonCertificateNotReady))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rolling).To(BeTrue())
+			Expect(ts.Status.RollingUpdate.Phase).To(Equal(tsv1alpha1.RollingUpdatePaused))
+			Expect(ts.Status.RollingUpdate.Message).To(ContainSubstring("could not be verified"))
+
+			for _, pod := range pods {
+				current := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), current)).To(Succeed())
+				Expect(current.DeletionTimestamp).To(BeNil())
+			}
+		})
+	})
+
+	It("should index the secret the certificate is read from", func() {
+		ts := &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: "test-tls-index"}}
+		Expect(indexTLSSecret(ts)).To(BeEmpty())
+
+		ts.Spec.TLS = &tsv1alpha1.TLSSpec{}
+		Expect(indexTLSSecret(ts)).To(ConsistOf("test-tls-index-tls"))
+
+		ts.Spec.TLS.SecretName = ptr.To("user-supplied")
+		Expect(indexTLSSecret(ts)).To(ConsistOf("user-supplied"))
+	})
+})
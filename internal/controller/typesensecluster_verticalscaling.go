@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// memoryRecommendationGranularity the recommended memory is rounded up to
+	memoryRecommendationGranularity = 64 * 1024 * 1024
+)
+
+// ReconcileVerticalScaling records a memory recommendation for the typesense container after the resident memory of
+// the busiest node and the nodes reporting OUT_OF_MEMORY. In Auto mode it patches the memory limit in spec.resources
+// once the cooldown elapsed, so the new template goes through ReconcileStatefulSet and ReconcileRollingUpdate, which
+// restarts the followers first and the leader last.
+func (r *TypesenseClusterReconciler) ReconcileVerticalScaling(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, condition ConditionQuorum) error {
+	if !ts.Spec.IsVerticalScalingEnabled() {
+		if ts.Status.VerticalScaling == nil {
+			return nil
+		}
+
+		return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+			status.VerticalScaling = nil
+		})
+	}
+
+	r.logger.V(debugLevel).Info("reconciling vertical scaling")
+
+	spec := ts.Spec.VerticalScaling
+	resources := ts.Spec.GetResources()
+
+	limit := resources.Limits.Memory()
+	if limit.IsZero() {
+		limit = resources.Requests.Memory()
+	}
+
+	verticalScaling := &tsv1alpha1.VerticalScalingStatus{MemoryLimit: ptr.To(limit.DeepCopy())}
+	if ts.Status.VerticalScaling != nil {
+		verticalScaling.LastResizeTime = ts.Status.VerticalScaling.LastResizeTime
+	}
+
+	for _, node := range ts.Status.Nodes {
+		if node.ResourceError == string(OutOfMemory) {
+			verticalScaling.OutOfMemory = append(verticalScaling.OutOfMemory, node.Pod)
+		}
+	}
+
+	usage, err := r.getMemoryUsage(ctx, ts)
+	if err != nil {
+		r.logger.V(debugLevel).Info("unable to fetch memory usage", "reason", err.Error())
+	}
+	if usage != nil {
+		verticalScaling.MemoryUsage = resource.NewQuantity(*usage, resource.BinarySI)
+	}
+
+	if usage == nil && len(verticalScaling.OutOfMemory) == 0 {
+		verticalScaling.Message = "waiting for the nodes to report their memory usage"
+		return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+	}
+
+	recommended, reason := getMemoryRecommendation(spec, limit.Value(), usage, verticalScaling.OutOfMemory)
+	verticalScaling.RecommendedMemory = resource.NewQuantity(recommended, resource.BinarySI)
+	verticalScaling.Message = reason
+
+	if !ts.Spec.IsVerticalScalingAuto() || recommended <= limit.Value() {
+		return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+	}
+
+	if condition != ConditionReasonQuorumReady && condition != ConditionReasonQuorumNeedsAttentionMemoryOrDiskIssue {
+		verticalScaling.Message = fmt.Sprintf("resizing is suspended while the quorum is %s: %s", condition, reason)
+		return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+	}
+
+	if ts.Status.IsRollingUpdate() {
+		verticalScaling.Message = fmt.Sprintf("resizing is suspended while a rolling update is in progress: %s", reason)
+		return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+	}
+
+	if verticalScaling.LastResizeTime != nil {
+		cooldown := time.Duration(spec.CooldownSeconds) * time.Second
+		if until := verticalScaling.LastResizeTime.Add(cooldown); time.Now().Before(until) {
+			verticalScaling.Message = fmt.Sprintf("resizing is held off by the cooldown until %s: %s", until.Format(time.RFC3339), reason)
+			return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+		}
+	}
+
+	from := limit.String()
+	to := verticalScaling.RecommendedMemory.String()
+	r.logger.Info("resizing typesense memory", "from", from, "to", to, "reason", reason)
+
+	patch := client.MergeFrom(ts.DeepCopy())
+	resources = *resources.DeepCopy()
+	if resources.Limits == nil {
+		resources.Limits = corev1.ResourceList{}
+	}
+	resources.Limits[corev1.ResourceMemory] = verticalScaling.RecommendedMemory.DeepCopy()
+	ts.Spec.Resources = &resources
+	if err := r.Patch(ctx, ts, patch); err != nil {
+		r.logger.Error(err, "patching resources failed", "memory", to)
+		return err
+	}
+
+	r.Recorder.Eventf(ts, "Normal", "MemoryResized", "Raising memory limit from %s to %s, followers first: %s", from, to, reason)
+
+	now := metav1.Now()
+	verticalScaling.LastResizeTime = &now
+	verticalScaling.MemoryLimit = ptr.To(verticalScaling.RecommendedMemory.DeepCopy())
+	return r.updateVerticalScalingStatus(ctx, ts, verticalScaling)
+}
+
+func (r *TypesenseClusterReconciler) updateVerticalScalingStatus(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, verticalScaling *tsv1alpha1.VerticalScalingStatus) error {
+	return r.patchStatus(ctx, ts, func(status *tsv1alpha1.TypesenseClusterStatus) {
+		status.VerticalScaling = verticalScaling
+	})
+}
+
+// getMemoryRecommendation sizes the memory so the busiest node stays at the target utilization, and grows it by at
+// least the out of memory increment while nodes report OUT_OF_MEMORY. The result is rounded up and kept within bounds.
+func getMemoryRecommendation(spec *tsv1alpha1.VerticalScalingSpec, limit int64, usage *int64, outOfMemory []string) (int64, string) {
+	recommended := int64(0)
+	reasons := make([]string, 0, 2)
+
+	if usage != nil {
+		recommended = int64(math.Ceil(float64(*usage) * 100 / float64(spec.TargetMemoryUtilizationPercentage)))
+		reasons = append(reasons, fmt.Sprintf("memory usage %s is %d%% of the limit, target is %d%%",
+			resource.NewQuantity(*usage, resource.BinarySI).String(),
+			int64(math.Round(float64(*usage)*100/float64(max(limit, 1)))),
+			spec.TargetMemoryUtilizationPercentage,
+		))
+	}
+
+	if len(outOfMemory) > 0 {
+		recommended = max(recommended, limit+limit*int64(spec.OutOfMemoryIncrementPercentage)/100)
+		reasons = append(reasons, fmt.Sprintf("out of memory: %s", strings.Join(outOfMemory, ", ")))
+	}
+
+	recommended = (recommended + memoryRecommendationGranularity - 1) / memoryRecommendationGranularity * memoryRecommendationGranularity
+
+	if spec.MinMemory != nil && recommended < spec.MinMemory.Value() {
+		recommended = spec.MinMemory.Value()
+	}
+
+	if maxMemory := spec.MaxMemory.Value(); maxMemory > 0 && recommended > maxMemory {
+		recommended = maxMemory
+		reasons = append(reasons, "maxMemory is reached")
+	}
+
+	return recommended, strings.Join(reasons, ", ")
+}
+
+// getMemoryUsage returns the highest resident memory reported by the /metrics.json endpoint of the running nodes
+func (r *TypesenseClusterReconciler) getMemoryUsage(ctx context.Context, ts *tsv1alpha1.TypesenseCluster) (*int64, error) {
+	api, err := newTypesenseApiClient(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	var usage *int64
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		// the metrics are reported as strings
+		var metrics struct {
+			ResidentBytes string `json:"typesense_memory_resident_bytes"`
+		}
+
+		node := NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(pod.Status.PodIP)}
+		if err := api.withNode(node).do(ctx, http.MethodGet, "/metrics.json", nil, nil, &metrics); err != nil {
+			r.logger.V(debugLevel).Info("unable to fetch metrics", "pod", pod.Name, "reason", err.Error())
+			continue
+		}
+
+		resident, err := strconv.ParseInt(metrics.ResidentBytes, 10, 64)
+		if err != nil {
+			r.logger.V(debugLevel).Info("unable to parse resident memory", "pod", pod.Name, "value", metrics.ResidentBytes)
+			continue
+		}
+
+		if usage == nil || resident > *usage {
+			usage = ptr.To(resident)
+		}
+	}
+
+	if usage == nil {
+		return nil, fmt.Errorf("no memory usage reported by the nodes")
+	}
+
+	return usage, nil
+}
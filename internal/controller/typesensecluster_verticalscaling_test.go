@@ -0,0 +1,71 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Vertical Scaling", func() {
+	const mi = int64(1024 * 1024)
+
+	newSpec := func(minMemory *resource.Quantity) *tsv1alpha1.VerticalScalingSpec {
+		return &tsv1alpha1.VerticalScalingSpec{
+			MinMemory:                         minMemory,
+			MaxMemory:                         resource.MustParse("8Gi"),
+			TargetMemoryUtilizationPercentage: 80,
+			OutOfMemoryIncrementPercentage:    50,
+		}
+	}
+
+	DescribeTable("recommending the memory",
+		func(spec *tsv1alpha1.VerticalScalingSpec, limit int64, usage *int64, outOfMemory []string, expected int64, reason string) {
+			recommended, actual := getMemoryRecommendation(spec, limit, usage, outOfMemory)
+			Expect(recommended).To(Equal(expected))
+			Expect(actual).To(Equal(reason))
+		},
+		Entry("sizes the memory for the target utilization",
+			newSpec(nil), 1024*mi, ptr.To(800*mi), nil,
+			1024*mi, "memory usage 800Mi is 78% of the limit, target is 80%"),
+		Entry("rounds the memory up",
+			newSpec(nil), 1024*mi, ptr.To(100*mi), nil,
+			128*mi, "memory usage 100Mi is 10% of the limit, target is 80%"),
+		Entry("keeps the memory above the minimum",
+			newSpec(ptr.To(resource.MustParse("512Mi"))), 1024*mi, ptr.To(100*mi), nil,
+			512*mi, "memory usage 100Mi is 10% of the limit, target is 80%"),
+		Entry("grows the memory by the increment when nodes are out of memory",
+			newSpec(nil), 1024*mi, nil, []string{"node-0"},
+			1536*mi, "out of memory: node-0"),
+		Entry("grows the memory by at least the increment when nodes are out of memory",
+			newSpec(nil), 1024*mi, ptr.To(1000*mi), []string{"node-0", "node-2"},
+			1536*mi, "memory usage 1000Mi is 98% of the limit, target is 80%, out of memory: node-0, node-2"),
+		Entry("follows the usage when it grows the memory more than the increment",
+			newSpec(nil), 1024*mi, ptr.To(1400*mi), []string{"node-0"},
+			1792*mi, "memory usage 1400Mi is 137% of the limit, target is 80%, out of memory: node-0"),
+		Entry("keeps the memory below the maximum",
+			newSpec(nil), 8192*mi, ptr.To(7680*mi), nil,
+			8192*mi, "memory usage 7680Mi is 94% of the limit, target is 80%, maxMemory is reached"),
+		Entry("recommends nothing without usage or nodes out of memory",
+			newSpec(nil), 1024*mi, nil, nil,
+			int64(0), ""),
+	)
+})
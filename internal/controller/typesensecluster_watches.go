@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	additionalServerConfigurationIndexKey = ".spec.additionalServerConfiguration.name"
+	adminApiKeyIndexKey                   = ".spec.adminApiKey.name"
+	tlsSecretIndexKey                     = ".spec.tls.secretName"
+)
+
+var (
+	// Owned objects are created by the operator itself, only their drift and deletion are of interest
+	ownedObjectsEventFilters = builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return objectChanged(e.ObjectOld, e.ObjectNew)
+		},
+	})
+	// Referenced objects are created by the user, possibly after the cluster that waits for them
+	referencedObjectsEventFilters = builder.WithPredicates(predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return objectChanged(e.ObjectOld, e.ObjectNew)
+		},
+	})
+	// Pods are of interest when they are deleted, so the node is repaired right away, or when a node replacement is
+	// requested by annotation
+	podPredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return annotationChanged(e.ObjectOld, e.ObjectNew, replaceNodeAnnotationKey)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+	podEventFilters = builder.WithPredicates(podPredicate)
+)
+
+// objectChanged compares the data of the objects that have no generation, and the generation of the rest, so status
+// updates are ignored
+func objectChanged(old, new client.Object) bool {
+	switch o := old.(type) {
+	case *corev1.ConfigMap:
+		n := new.(*corev1.ConfigMap)
+		return !apiequality.Semantic.DeepEqual(o.Data, n.Data) || !apiequality.Semantic.DeepEqual(o.BinaryData, n.BinaryData)
+	case *corev1.Secret:
+		n := new.(*corev1.Secret)
+		return !apiequality.Semantic.DeepEqual(o.Data, n.Data)
+	case *corev1.Service:
+		n := new.(*corev1.Service)
+		return !apiequality.Semantic.DeepEqual(o.Spec, n.Spec)
+	}
+
+	return old.GetGeneration() != new.GetGeneration()
+}
+
+func indexAdditionalServerConfiguration(obj client.Object) []string {
+	ts := obj.(*tsv1alpha1.TypesenseCluster)
+	if ts.Spec.AdditionalServerConfiguration == nil || ts.Spec.AdditionalServerConfiguration.Name == "" {
+		return nil
+	}
+
+	return []string{ts.Spec.AdditionalServerConfiguration.Name}
+}
+
+func indexAdminApiKey(obj client.Object) []string {
+	ts := obj.(*tsv1alpha1.TypesenseCluster)
+	if ts.Spec.AdminApiKey == nil || ts.Spec.AdminApiKey.Name == "" {
+		return nil
+	}
+
+	return []string{ts.Spec.AdminApiKey.Name}
+}
+
+// indexTLSSecret indexes the Secret the certificate is read from, whether it is supplied by the user or issued by
+// cert-manager, as neither is owned by the cluster
+func indexTLSSecret(obj client.Object) []string {
+	ts := obj.(*tsv1alpha1.TypesenseCluster)
+	if !ts.Spec.IsTLSEnabled() {
+		return nil
+	}
+
+	return []string{getTLSSecretName(ts)}
+}
+
+// findClustersReferencing returns a map function enqueuing the clusters of the namespace that reference the object
+// by name in the indexed field
+func (r *TypesenseClusterReconciler) findClustersReferencing(indexKey string) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var clusters tsv1alpha1.TypesenseClusterList
+		if err := r.List(ctx, &clusters, client.InNamespace(obj.GetNamespace()), client.MatchingFields{indexKey: obj.GetName()}); err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(clusters.Items))
+		for _, ts := range clusters.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ts)})
+		}
+
+		return requests
+	}
+}
+
+// findClusterByInstanceLabel enqueues the cluster of the objects that are not owned by it directly, e.g. the reverse
+// proxy Deployment that is owned by the Ingress
+func findClusterByInstanceLabel(_ context.Context, obj client.Object) []reconcile.Request {
+	lbls := obj.GetLabels()
+	if lbls["app.kubernetes.io/managed-by"] != "typesense-operator" || lbls["app.kubernetes.io/instance"] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: lbls["app.kubernetes.io/instance"]}},
+	}
+}
+
+// findClusterByStatefulSetPod enqueues the cluster of the pods created by its StatefulSet
+func findClusterByStatefulSetPod(_ context.Context, obj client.Object) []reconcile.Request {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return nil
+	}
+
+	name, ok := strings.CutSuffix(owner.Name, strings.TrimPrefix(ClusterStatefulSet, "%s"))
+	if !ok || obj.GetLabels()["app"] != fmt.Sprintf(ClusterAppLabel, name) {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}},
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Watches", func() {
+	ctx := context.Background()
+
+	DescribeTable("detecting the drift of an object",
+		func(old, new client.Object, expected bool) {
+			Expect(objectChanged(old, new)).To(Equal(expected))
+		},
+		Entry("the data of a configmap changed",
+			&corev1.ConfigMap{Data: map[string]string{"nodes": "10.0.0.1:8107:8108"}},
+			&corev1.ConfigMap{Data: map[string]string{"nodes": "10.0.0.2:8107:8108"}},
+			true),
+		Entry("only the labels of a configmap changed",
+			&corev1.ConfigMap{Data: map[string]string{"nodes": "10.0.0.1:8107:8108"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}}, Data: map[string]string{"nodes": "10.0.0.1:8107:8108"}},
+			false),
+		Entry("the data of a secret changed",
+			&corev1.Secret{Data: map[string][]byte{"typesense-api-key": []byte("old")}},
+			&corev1.Secret{Data: map[string][]byte{"typesense-api-key": []byte("new")}},
+			true),
+		Entry("the spec of a service changed",
+			&corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+			&corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort}},
+			true),
+		Entry("only the status of a service changed",
+			&corev1.Service{},
+			&corev1.Service{Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}}}},
+			false),
+		Entry("the generation of a statefulset changed",
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Generation: 2}},
+			true),
+		Entry("only the status of a statefulset changed",
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Generation: 1}, Status: appsv1.StatefulSetStatus{ReadyReplicas: 3}},
+			false),
+	)
+
+	It("should index the objects referenced by a cluster", func() {
+		ts := &tsv1alpha1.TypesenseCluster{}
+		Expect(indexAdditionalServerConfiguration(ts)).To(BeEmpty())
+		Expect(indexAdminApiKey(ts)).To(BeEmpty())
+
+		ts.Spec.AdditionalServerConfiguration = &corev1.LocalObjectReference{Name: "server-configuration"}
+		ts.Spec.AdminApiKey = &corev1.SecretReference{Name: "admin-api-key"}
+		Expect(indexAdditionalServerConfiguration(ts)).To(ConsistOf("server-configuration"))
+		Expect(indexAdminApiKey(ts)).To(ConsistOf("admin-api-key"))
+	})
+
+	DescribeTable("enqueuing the cluster of an object that is not owned by it",
+		func(labels map[string]string, expected []reconcile.Request) {
+			deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-reverse-proxy", Namespace: "default", Labels: labels}}
+			Expect(findClusterByInstanceLabel(ctx, deployment)).To(Equal(expected))
+		},
+		Entry("managed by the operator",
+			map[string]string{"app.kubernetes.io/managed-by": "typesense-operator", "app.kubernetes.io/instance": "test-watches"},
+			[]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-watches"}}}),
+		Entry("managed by someone else",
+			map[string]string{"app.kubernetes.io/managed-by": "helm", "app.kubernetes.io/instance": "test-watches"},
+			nil),
+		Entry("without an instance",
+			map[string]string{"app.kubernetes.io/managed-by": "typesense-operator"},
+			nil),
+	)
+
+	It("should let pod deletions and node replacement requests through", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-watches-sts-0", Namespace: "default"}}
+		annotated := pod.DeepCopy()
+		annotated.Annotations = map[string]string{replaceNodeAnnotationKey: "true"}
+
+		Expect(podPredicate.Delete(event.DeleteEvent{Object: pod})).To(BeTrue())
+		Expect(podPredicate.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: annotated})).To(BeTrue())
+		Expect(podPredicate.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod.DeepCopy()})).To(BeFalse())
+		Expect(podPredicate.Create(event.CreateEvent{Object: pod})).To(BeFalse())
+	})
+
+	DescribeTable("enqueuing the cluster of a pod",
+		func(ownerKind, ownerName, app string, expected []reconcile.Request) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-watches-sts-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": app},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: ownerKind, Name: ownerName, UID: "uid", Controller: ptr.To(true)},
+				},
+			}}
+			Expect(findClusterByStatefulSetPod(ctx, pod)).To(Equal(expected))
+		},
+		Entry("created by the statefulset of the cluster", "StatefulSet", "test-watches-sts", "test-watches-sts",
+			[]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-watches"}}}),
+		Entry("created by another statefulset", "StatefulSet", "test-watches", "test-watches-sts", nil),
+		Entry("created by a statefulset without the labels of the cluster", "StatefulSet", "test-watches-sts", "other-sts", nil),
+		Entry("created by a job", "Job", "test-watches-sts", "test-watches-sts", nil),
+	)
+})
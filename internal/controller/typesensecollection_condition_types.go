@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonClusterNotReady       = "ClusterNotReady"
+	ConditionReasonCollectionReady       = "CollectionReady"
+	ConditionReasonCollectionNotReady    = "CollectionNotReady"
+	ConditionReasonCollectionSchemaDrift = "CollectionSchemaDrift"
+
+	UpdateCollectionStatusMessageFailed = "failed to update typesense collection status"
+)
+
+func (r *TypesenseCollectionReconciler) initConditions(ctx context.Context, tc *tsv1alpha1.TypesenseCollection) error {
+	if len(tc.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, tc, func(status *tsv1alpha1.TypesenseCollectionStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateCollectionStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseCollectionReconciler) setConditionNotReady(ctx context.Context, tc *tsv1alpha1.TypesenseCollection, reason string, err error) error {
+	if err := r.patchStatus(ctx, tc, func(status *tsv1alpha1.TypesenseCollectionStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseCollectionReconciler) setConditionReady(ctx context.Context, tc *tsv1alpha1.TypesenseCollection, reason string) error {
+	if err := r.patchStatus(ctx, tc, func(status *tsv1alpha1.TypesenseCollectionStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Collection is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseCollectionReconciler) patchStatus(
+	ctx context.Context,
+	tc *tsv1alpha1.TypesenseCollection,
+	patcher func(status *tsv1alpha1.TypesenseCollectionStatus),
+) error {
+	patch := client.MergeFrom(tc.DeepCopy())
+	patcher(&tc.Status)
+
+	err := r.Status().Patch(ctx, tc, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense collection status")
+		return err
+	}
+
+	return nil
+}
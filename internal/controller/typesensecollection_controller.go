@@ -0,0 +1,201 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+// TypesenseCollectionReconciler reconciles a TypesenseCollection object
+type TypesenseCollectionReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensecollections,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensecollections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensecollections/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile creates the collection in the referenced TypesenseCluster if it does not exist, and adds the fields that
+// were declared since; fields are dropped or changed only if AllowFieldDrops is set. Collections are never dropped by
+// the operator; deleting a TypesenseCollection leaves the collection and its documents in place.
+func (r *TypesenseCollectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "collection", req.Name)
+
+	var tc tsv1alpha1.TypesenseCollection
+	if err := r.Get(ctx, req.NamespacedName, &tc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	r.logger.Info("reconciling collection")
+
+	err := r.initConditions(ctx, &tc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: tc.Namespace, Name: tc.Spec.Cluster.Name}
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &tc, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	applied, drift, err := r.ReconcileCollection(ctx, &tc, api)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &tc, ConditionReasonCollectionNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	err = r.patchStatus(ctx, &tc, func(status *tsv1alpha1.TypesenseCollectionStatus) {
+		status.ObservedGeneration = tc.Generation
+		status.CollectionName = tc.GetCollectionName()
+		status.AppliedSchema = getAppliedSchema(applied)
+		status.NumDocuments = applied.NumDocuments
+		status.CreatedAt = getCollectionCreatedAt(applied)
+		status.SchemaDrift = drift
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(drift) > 0 {
+		derr := fmt.Errorf("schema drift detected: %s", strings.Join(drift, "; "))
+		report := tc.Status.Phase != ConditionReasonCollectionSchemaDrift
+
+		cerr := r.setConditionNotReady(ctx, &tc, ConditionReasonCollectionSchemaDrift, derr)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+
+		if report {
+			r.Recorder.Eventf(&tc, "Warning", ConditionReasonCollectionSchemaDrift, toTitle(derr.Error()))
+		}
+	} else {
+		cerr := r.setConditionReady(ctx, &tc, ConditionReasonCollectionReady)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+	}
+
+	r.logger.Info("reconciling collection completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileCollection converges the collection towards the desired schema and returns the applied schema as reported
+// by Typesense afterward, together with the differences that could not be patched.
+func (r *TypesenseCollectionReconciler) ReconcileCollection(ctx context.Context, tc *tsv1alpha1.TypesenseCollection, api *typesenseApiClient) (*collectionSchema, []string, error) {
+	r.logger.V(debugLevel).Info("reconciling collection schema")
+
+	desired := buildCollectionSchema(tc)
+	collectionPath := "/collections/" + url.PathEscape(desired.Name)
+
+	collectionExists := true
+	var applied = &collectionSchema{}
+	if err := api.do(ctx, http.MethodGet, collectionPath, nil, nil, applied); err != nil {
+		if isTypesenseApiNotFound(err) {
+			collectionExists = false
+		} else {
+			r.logger.Error(err, "unable to fetch collection", "collection", desired.Name)
+			return nil, nil, err
+		}
+	}
+
+	if !collectionExists {
+		r.logger.V(debugLevel).Info("creating collection", "collection", desired.Name)
+
+		if err := api.do(ctx, http.MethodPost, "/collections", nil, desired, applied); err != nil {
+			r.logger.Error(err, "creating collection failed", "collection", desired.Name)
+			return nil, nil, err
+		}
+
+		r.Recorder.Eventf(tc, "Normal", "CollectionCreated", "Created collection %s", desired.Name)
+		return applied, nil, nil
+	}
+
+	update, drift := diffCollectionSchema(desired, applied, tc.Spec.AllowFieldDrops)
+	if len(update) == 0 {
+		return applied, drift, nil
+	}
+
+	r.logger.V(debugLevel).Info("patching collection", "collection", desired.Name, "fields", len(update))
+
+	if err := api.do(ctx, http.MethodPatch, collectionPath, nil, collectionSchema{Fields: update}, nil); err != nil {
+		var apiErr *typesenseApiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			// the schema change is rejected by Typesense, e.g. an incompatible type change; retrying won't help
+			r.logger.Error(err, "patching collection rejected", "collection", desired.Name)
+			drift = append(drift, fmt.Sprintf("fields: %s", apiErr.Message))
+			return applied, drift, nil
+		}
+
+		r.logger.Error(err, "patching collection failed", "collection", desired.Name)
+		return nil, nil, err
+	}
+
+	r.Recorder.Eventf(tc, "Normal", "CollectionPatched", "Patched %d field(s) of collection %s", len(update), desired.Name)
+
+	applied = &collectionSchema{}
+	if err := api.do(ctx, http.MethodGet, collectionPath, nil, nil, applied); err != nil {
+		r.logger.Error(err, "unable to fetch collection", "collection", desired.Name)
+		return nil, nil, err
+	}
+
+	return applied, drift, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseCollectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseCollection{}, eventFilters).
+		Named("typesensecollection").
+		Complete(r)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+// newFakeTypesenseApiClient returns an api client that talks to the test server as if it was a node of the cluster
+func newFakeTypesenseApiClient(server *httptest.Server) *typesenseApiClient {
+	u, err := url.Parse(server.URL)
+	Expect(err).NotTo(HaveOccurred())
+	port, err := strconv.Atoi(u.Port())
+	Expect(err).NotTo(HaveOccurred())
+
+	ts := &tsv1alpha1.TypesenseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake", Namespace: "default"},
+		Spec:       tsv1alpha1.TypesenseClusterSpec{ApiPort: port},
+	}
+
+	return &typesenseApiClient{
+		httpClient: server.Client(),
+		inCluster:  true,
+		ts:         ts,
+		node:       NodeEndpoint{PodName: "fake-sts-0", IP: net.ParseIP(u.Hostname())},
+		apiKey:     "fake",
+	}
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	Expect(json.NewEncoder(w).Encode(body)).To(Succeed())
+}
+
+var _ = Describe("TypesenseCollection Controller", func() {
+	desired := collectionSchema{
+		Name: "companies",
+		Fields: []collectionField{
+			{Name: "title", Type: "string"},
+			{Name: "country", Type: "string", Facet: ptr.To(true)},
+			{Name: "attrs", Type: "object"},
+			{Name: "tag_.*", Type: "auto"},
+			{Name: "summary", Type: "auto"},
+			{Name: "labels", Type: "string*"},
+		},
+	}
+
+	applied := func(fields ...collectionField) *collectionSchema {
+		return &collectionSchema{
+			Name: "companies",
+			Fields: append([]collectionField{
+				{Name: "title", Type: "string", Facet: ptr.To(false), Index: ptr.To(true)},
+				{Name: "country", Type: "string", Facet: ptr.To(true)},
+				{Name: "attrs", Type: "object"},
+				{Name: "tag_.*", Type: "auto"},
+				{Name: "summary", Type: "string"},
+				{Name: "labels", Type: "string[]"},
+			}, fields...),
+		}
+	}
+
+	DescribeTable("isTypesenseApiNotFound",
+		func(err error, expected bool) {
+			Expect(isTypesenseApiNotFound(err)).To(Equal(expected))
+		},
+		Entry("a not found error", &typesenseApiError{StatusCode: http.StatusNotFound}, true),
+		Entry("a wrapped not found error", fmt.Errorf("failed to get collection: %w", &typesenseApiError{StatusCode: http.StatusNotFound}), true),
+		Entry("a not found error wrapped with a message", errors.Wrap(&typesenseApiError{StatusCode: http.StatusNotFound}, "failed to get alias"), true),
+		Entry("another status code", &typesenseApiError{StatusCode: http.StatusConflict}, false),
+		Entry("another error", errors.New("connection refused"), false),
+	)
+
+	DescribeTable("diffCollectionSchema",
+		func(desired collectionSchema, applied *collectionSchema, allowDrops bool, update []collectionField, drift []string) {
+			u, d := diffCollectionSchema(desired, applied, allowDrops)
+			Expect(u).To(Equal(update))
+			Expect(d).To(Equal(drift))
+		},
+		Entry("the applied schema is in sync", desired, applied(), false, nil, nil),
+		Entry("fields detected out of declared regex, auto and object fields are kept", desired, applied(
+			collectionField{Name: "tag_color", Type: "string"},
+			collectionField{Name: "attrs.size", Type: "int32"},
+		), false, nil, nil),
+		Entry("a new field is added",
+			collectionSchema{Name: "companies", Fields: append(desired.Fields, collectionField{Name: "year", Type: "int32"})},
+			applied(), false,
+			[]collectionField{{Name: "year", Type: "int32"}}, nil),
+		Entry("a field that is not declared is reported", desired, applied(
+			collectionField{Name: "legacy", Type: "string"},
+		), false, nil, []string{"fields.legacy: applied but not declared"}),
+		Entry("a field that is not declared is dropped when allowed", desired, applied(
+			collectionField{Name: "legacy", Type: "string"},
+		), true, []collectionField{{Name: "legacy", Drop: ptr.To(true)}}, nil),
+		Entry("a changed field is reported",
+			collectionSchema{Name: "companies", Fields: []collectionField{
+				{Name: "title", Type: "string[]", Sort: ptr.To(true)},
+				{Name: "country", Type: "string", Facet: ptr.To(true)},
+				{Name: "attrs", Type: "object"},
+				{Name: "tag_.*", Type: "auto"},
+				{Name: "summary", Type: "auto"},
+				{Name: "labels", Type: "string*"},
+			}},
+			applied(), false, nil, []string{"fields.title: type, sort changed"}),
+		Entry("a changed field is dropped and re-added when allowed",
+			collectionSchema{Name: "companies", Fields: []collectionField{
+				{Name: "title", Type: "string", Facet: ptr.To(true)},
+				{Name: "country", Type: "string", Facet: ptr.To(true)},
+				{Name: "attrs", Type: "object"},
+				{Name: "tag_.*", Type: "auto"},
+				{Name: "summary", Type: "auto"},
+				{Name: "labels", Type: "string*"},
+			}},
+			applied(), true, []collectionField{
+				{Name: "title", Drop: ptr.To(true)},
+				{Name: "title", Type: "string", Facet: ptr.To(true)},
+			}, nil),
+		Entry("collection settings that cannot be patched are reported",
+			collectionSchema{Name: "companies", Fields: desired.Fields, DefaultSortingField: ptr.To("title"), TokenSeparators: []string{"-"}},
+			applied(), false, nil, []string{
+				"defaultSortingField: desired 'title', applied ''",
+				"tokenSeparators: desired [-], applied []",
+			}),
+	)
+
+	Context("When the applied schema diverges", func() {
+		ctx := context.Background()
+
+		var patched []collectionSchema
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			patched = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.URL.Path).To(Equal("/collections/companies"))
+
+				switch r.Method {
+				case http.MethodGet:
+					writeJson(w, http.StatusOK, applied(collectionField{Name: "legacy", Type: "string"}))
+				case http.MethodPatch:
+					var update collectionSchema
+					Expect(json.NewDecoder(r.Body).Decode(&update)).To(Succeed())
+					patched = append(patched, update)
+					writeJson(w, http.StatusOK, update)
+				default:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should only add the new fields and report the rest as drift", func() {
+			tc := &tsv1alpha1.TypesenseCollection{
+				ObjectMeta: metav1.ObjectMeta{Name: "companies", Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseCollectionSpec{
+					Cluster: corev1.LocalObjectReference{Name: "fake"},
+					Fields: []tsv1alpha1.CollectionFieldSpec{
+						{Name: "title", Type: "string"},
+						{Name: "country", Type: "string", Facet: ptr.To(false)},
+						{Name: "attrs", Type: "object"},
+						{Name: "tag_.*", Type: "auto"},
+						{Name: "summary", Type: "auto"},
+						{Name: "labels", Type: "string*"},
+						{Name: "year", Type: "int32"},
+					},
+				},
+			}
+
+			controllerReconciler := &TypesenseCollectionReconciler{Recorder: record.NewFakeRecorder(10)}
+			_, drift, err := controllerReconciler.ReconcileCollection(ctx, tc, newFakeTypesenseApiClient(server))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(patched).To(HaveLen(1))
+			Expect(patched[0].Fields).To(Equal([]collectionField{{Name: "year", Type: "int32"}}))
+			Expect(drift).To(ConsistOf(
+				"fields.legacy: applied but not declared",
+				"fields.country: facet changed",
+			))
+		})
+	})
+})
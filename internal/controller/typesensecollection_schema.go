@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// collectionField is the wire representation of a collection field in the Typesense API
+type collectionField struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type,omitempty"`
+	Facet     *bool   `json:"facet,omitempty"`
+	Optional  *bool   `json:"optional,omitempty"`
+	Index     *bool   `json:"index,omitempty"`
+	Sort      *bool   `json:"sort,omitempty"`
+	Infix     *bool   `json:"infix,omitempty"`
+	Store     *bool   `json:"store,omitempty"`
+	Locale    *string `json:"locale,omitempty"`
+	NumDim    *int    `json:"num_dim,omitempty"`
+	Reference *string `json:"reference,omitempty"`
+	Drop      *bool   `json:"drop,omitempty"`
+}
+
+// collectionSchema is the wire representation of a collection in the Typesense API
+type collectionSchema struct {
+	Name                string            `json:"name,omitempty"`
+	Fields              []collectionField `json:"fields"`
+	DefaultSortingField *string           `json:"default_sorting_field,omitempty"`
+	TokenSeparators     []string          `json:"token_separators,omitempty"`
+	SymbolsToIndex      []string          `json:"symbols_to_index,omitempty"`
+	EnableNestedFields  *bool             `json:"enable_nested_fields,omitempty"`
+	NumDocuments        int64             `json:"num_documents,omitempty"`
+	CreatedAt           int64             `json:"created_at,omitempty"`
+}
+
+func buildCollectionSchema(tc *tsv1alpha1.TypesenseCollection) collectionSchema {
+	fields := make([]collectionField, 0, len(tc.Spec.Fields))
+	for _, f := range tc.Spec.Fields {
+		fields = append(fields, toCollectionField(f))
+	}
+
+	return collectionSchema{
+		Name:                tc.GetCollectionName(),
+		Fields:              fields,
+		DefaultSortingField: tc.Spec.DefaultSortingField,
+		TokenSeparators:     tc.Spec.TokenSeparators,
+		SymbolsToIndex:      tc.Spec.SymbolsToIndex,
+		EnableNestedFields:  tc.Spec.EnableNestedFields,
+	}
+}
+
+func toCollectionField(f tsv1alpha1.CollectionFieldSpec) collectionField {
+	return collectionField{
+		Name:      f.Name,
+		Type:      f.Type,
+		Facet:     f.Facet,
+		Optional:  f.Optional,
+		Index:     f.Index,
+		Sort:      f.Sort,
+		Infix:     f.Infix,
+		Store:     f.Store,
+		Locale:    f.Locale,
+		NumDim:    f.NumDim,
+		Reference: f.Reference,
+	}
+}
+
+func toCollectionFieldSpec(f collectionField) tsv1alpha1.CollectionFieldSpec {
+	spec := tsv1alpha1.CollectionFieldSpec{
+		Name:      f.Name,
+		Type:      f.Type,
+		Facet:     f.Facet,
+		Optional:  f.Optional,
+		Index:     f.Index,
+		Sort:      f.Sort,
+		Infix:     f.Infix,
+		Store:     f.Store,
+		NumDim:    f.NumDim,
+		Reference: f.Reference,
+	}
+
+	if f.Locale != nil && *f.Locale != "" {
+		spec.Locale = f.Locale
+	}
+
+	if f.Reference != nil && *f.Reference == "" {
+		spec.Reference = nil
+	}
+
+	return spec
+}
+
+func getAppliedSchema(applied *collectionSchema) []tsv1alpha1.CollectionFieldSpec {
+	fields := make([]tsv1alpha1.CollectionFieldSpec, 0, len(applied.Fields))
+	for _, f := range applied.Fields {
+		fields = append(fields, toCollectionFieldSpec(f))
+	}
+
+	return fields
+}
+
+func getCollectionCreatedAt(applied *collectionSchema) *metav1.Time {
+	if applied.CreatedAt == 0 {
+		return nil
+	}
+
+	createdAt := metav1.NewTime(time.Unix(applied.CreatedAt, 0))
+	return &createdAt
+}
+
+// diffCollectionSchema compares the desired with the applied schema and returns the fields update that needs to be
+// sent with a PATCH request, and the differences that are not applied. New fields are always added; fields that are
+// not declared anymore, or whose definition changed, are dropped (and re-added) only if allowDrops is set, as this
+// loses or reindexes their data. Fields detected by Typesense out of a declared regex, auto or object field are kept.
+func diffCollectionSchema(desired collectionSchema, applied *collectionSchema, allowDrops bool) ([]collectionField, []string) {
+	var update []collectionField
+	var drift []string
+
+	appliedFields := make(map[string]collectionField, len(applied.Fields))
+	for _, f := range applied.Fields {
+		appliedFields[f.Name] = f
+	}
+
+	desiredFields := make(map[string]collectionField, len(desired.Fields))
+	for _, f := range desired.Fields {
+		desiredFields[f.Name] = f
+	}
+
+	for _, f := range applied.Fields {
+		if _, ok := desiredFields[f.Name]; ok || isDetectedCollectionField(f.Name, desiredFields) {
+			continue
+		}
+
+		if !allowDrops {
+			drift = append(drift, fmt.Sprintf("fields.%s: applied but not declared", f.Name))
+			continue
+		}
+		update = append(update, collectionField{Name: f.Name, Drop: ptr.To(true)})
+	}
+
+	for _, f := range desired.Fields {
+		af, ok := appliedFields[f.Name]
+		if !ok {
+			update = append(update, f)
+			continue
+		}
+
+		changes := getCollectionFieldChanges(f, af)
+		if len(changes) == 0 {
+			continue
+		}
+
+		if !allowDrops {
+			drift = append(drift, fmt.Sprintf("fields.%s: %s changed", f.Name, strings.Join(changes, ", ")))
+			continue
+		}
+		update = append(update, collectionField{Name: f.Name, Drop: ptr.To(true)}, f)
+	}
+
+	if ptr.Deref(desired.DefaultSortingField, "") != ptr.Deref(applied.DefaultSortingField, "") {
+		drift = append(drift, fmt.Sprintf("defaultSortingField: desired '%s', applied '%s'",
+			ptr.Deref(desired.DefaultSortingField, ""), ptr.Deref(applied.DefaultSortingField, "")))
+	}
+
+	if !slices.Equal(desired.TokenSeparators, applied.TokenSeparators) && (len(desired.TokenSeparators) != 0 || len(applied.TokenSeparators) != 0) {
+		drift = append(drift, fmt.Sprintf("tokenSeparators: desired [%s], applied [%s]",
+			strings.Join(desired.TokenSeparators, ","), strings.Join(applied.TokenSeparators, ",")))
+	}
+
+	if !slices.Equal(desired.SymbolsToIndex, applied.SymbolsToIndex) && (len(desired.SymbolsToIndex) != 0 || len(applied.SymbolsToIndex) != 0) {
+		drift = append(drift, fmt.Sprintf("symbolsToIndex: desired [%s], applied [%s]",
+			strings.Join(desired.SymbolsToIndex, ","), strings.Join(applied.SymbolsToIndex, ",")))
+	}
+
+	if ptr.Deref(desired.EnableNestedFields, false) != ptr.Deref(applied.EnableNestedFields, false) {
+		drift = append(drift, fmt.Sprintf("enableNestedFields: desired %t, applied %t",
+			ptr.Deref(desired.EnableNestedFields, false), ptr.Deref(applied.EnableNestedFields, false)))
+	}
+
+	return update, drift
+}
+
+// getCollectionFieldChanges returns the attributes set explicitly in the desired field that differ from the applied
+// one. Attributes left empty in the spec are owned by Typesense defaults and are not compared.
+func getCollectionFieldChanges(desired, applied collectionField) []string {
+	var changes []string
+
+	if !isCollectionFieldTypeOf(desired.Type, applied.Type) {
+		changes = append(changes, "type")
+	}
+
+	boolChanged := func(d, a *bool, def bool) bool {
+		return d != nil && *d != ptr.Deref(a, def)
+	}
+
+	for _, attribute := range []struct {
+		name    string
+		changed bool
+	}{
+		{"facet", boolChanged(desired.Facet, applied.Facet, false)},
+		{"optional", boolChanged(desired.Optional, applied.Optional, false)},
+		{"index", boolChanged(desired.Index, applied.Index, true)},
+		{"sort", boolChanged(desired.Sort, applied.Sort, false)},
+		{"infix", boolChanged(desired.Infix, applied.Infix, false)},
+		{"store", boolChanged(desired.Store, applied.Store, true)},
+		{"locale", desired.Locale != nil && *desired.Locale != ptr.Deref(applied.Locale, "")},
+		{"numDim", desired.NumDim != nil && *desired.NumDim != ptr.Deref(applied.NumDim, 0)},
+		{"reference", desired.Reference != nil && *desired.Reference != ptr.Deref(applied.Reference, "")},
+	} {
+		if attribute.changed {
+			changes = append(changes, attribute.name)
+		}
+	}
+
+	return changes
+}
+
+// isCollectionFieldTypeOf reports whether the applied type satisfies the desired one, auto and string* are resolved
+// by Typesense to a concrete type
+func isCollectionFieldTypeOf(desired, applied string) bool {
+	switch desired {
+	case "auto":
+		return true
+	case "string*":
+		return applied == "string*" || applied == "string" || applied == "string[]"
+	}
+
+	return desired == applied
+}
+
+// isDetectedCollectionField reports whether the field was added by Typesense out of a declared field: a field whose
+// name matches a declared regex field, or one flattened out of a declared object or auto field
+func isDetectedCollectionField(name string, desired map[string]collectionField) bool {
+	for parent, f := range desired {
+		if (f.Type == "object" || f.Type == "object[]" || f.Type == "auto") && strings.HasPrefix(name, parent+".") {
+			return true
+		}
+
+		if !strings.Contains(parent, ".*") {
+			continue
+		}
+
+		pattern, err := regexp.Compile("^(?:" + parent + ")$")
+		if err == nil && pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonOperationPending   = "OperationPending"
+	ConditionReasonOperationRunning   = "OperationRunning"
+	ConditionReasonOperationCompleted = "OperationCompleted"
+	ConditionReasonOperationFailed    = "OperationFailed"
+)
+
+func (r *TypesenseOperationReconciler) setOperationPhase(ctx context.Context, to *tsv1alpha1.TypesenseOperation, phase tsv1alpha1.TypesenseOperationPhase, status metav1.ConditionStatus, reason string, message string) error {
+	return r.patchStatus(ctx, to, func(s *tsv1alpha1.TypesenseOperationStatus) {
+		meta.SetStatusCondition(&s.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: status, Reason: reason, Message: message})
+		s.Phase = phase
+	})
+}
+
+func (r *TypesenseOperationReconciler) patchStatus(
+	ctx context.Context,
+	to *tsv1alpha1.TypesenseOperation,
+	patcher func(status *tsv1alpha1.TypesenseOperationStatus),
+) error {
+	patch := client.MergeFrom(to.DeepCopy())
+	patcher(&to.Status)
+
+	err := r.Status().Patch(ctx, to, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense operation status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	operationRequeuePeriod = 15 * time.Second
+)
+
+// operationPaths maps every operation type to the endpoint of the Typesense api running it
+var operationPaths = map[tsv1alpha1.TypesenseOperationType]string{
+	tsv1alpha1.OperationVote:       "/operations/vote",
+	tsv1alpha1.OperationSnapshot:   "/operations/snapshot",
+	tsv1alpha1.OperationCompact:    "/operations/db/compact",
+	tsv1alpha1.OperationClearCache: "/operations/cache/clear",
+	tsv1alpha1.OperationResetPeers: "/operations/reset_peers",
+}
+
+// TypesenseOperationReconciler reconciles a TypesenseOperation object
+type TypesenseOperationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoperations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoperations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoperations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile runs the operation once on the targeted nodes of the referenced TypesenseCluster, and records the outcome
+// on every node in the status. The cluster does not have to be ready, so the operations that help a broken quorum,
+// e.g. resetting the peers, can be run on it. An operation interrupted by a restart of the operator is failed instead
+// of being run again.
+func (r *TypesenseOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "operation", req.Name)
+
+	var to tsv1alpha1.TypesenseOperation
+	if err := r.Get(ctx, req.NamespacedName, &to); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	switch to.Status.Phase {
+	case "", tsv1alpha1.OperationPending:
+		r.logger.Info("reconciling operation", "type", to.Spec.Type, "node", to.GetNode())
+		return r.run(ctx, &to)
+	case tsv1alpha1.OperationRunning:
+		return r.fail(ctx, &to, fmt.Errorf("operation was interrupted, it is not run again"))
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *TypesenseOperationReconciler) run(ctx context.Context, to *tsv1alpha1.TypesenseOperation) (ctrl.Result, error) {
+	clusterObjectKey := client.ObjectKey{Namespace: to.Namespace, Name: to.Spec.Cluster.Name}
+
+	var ts = &tsv1alpha1.TypesenseCluster{}
+	if err := r.Get(ctx, clusterObjectKey, ts); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setOperationPhase(ctx, to, tsv1alpha1.OperationPending, metav1.ConditionFalse, ConditionReasonClusterNotReady, err.Error())
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	api, err := r.newApiClient(ctx, ts, to.GetTimeout())
+	if err != nil {
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", ts.Name, "reason", err.Error())
+		cerr := r.setOperationPhase(ctx, to, tsv1alpha1.OperationPending, metav1.ConditionFalse, ConditionReasonClusterNotReady, err.Error())
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	nodes, roles, err := r.getTargetNodes(ctx, to, ts, api)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.fail(ctx, to, fmt.Errorf("node %s not found in cluster %s", to.GetNode(), ts.Name))
+		}
+
+		r.logger.Info("waiting for target nodes", "node", to.GetNode(), "reason", err.Error())
+		cerr := r.setOperationPhase(ctx, to, tsv1alpha1.OperationPending, metav1.ConditionFalse, ConditionReasonOperationPending, err.Error())
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: operationRequeuePeriod}, nil
+	}
+
+	// the phase is recorded before any request is sent, so the operation is never run twice
+	err = r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOperationStatus) {
+		status.StartedAt = ptr.To(metav1.Now())
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.setOperationPhase(ctx, to, tsv1alpha1.OperationRunning, metav1.ConditionFalse, ConditionReasonOperationRunning, fmt.Sprintf("Running %s on %d nodes", to.Spec.Type, len(nodes)))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var query url.Values
+	if to.Spec.Type == tsv1alpha1.OperationSnapshot {
+		query = url.Values{"snapshot_path": []string{to.Spec.SnapshotPath}}
+	}
+
+	results := make([]tsv1alpha1.OperationNodeResult, 0, len(nodes))
+	failed := 0
+	for _, node := range nodes {
+		result := tsv1alpha1.OperationNodeResult{
+			Pod:       node.PodName,
+			Role:      string(roles[node.PodName]),
+			StartedAt: ptr.To(metav1.Now()),
+		}
+
+		r.logger.V(debugLevel).Info("running operation", "type", to.Spec.Type, "node", node.PodName)
+
+		var response struct {
+			Success bool `json:"success"`
+		}
+		err := api.withNode(node).do(ctx, http.MethodPost, operationPaths[to.Spec.Type], query, nil, &response)
+		if err == nil && !response.Success {
+			err = fmt.Errorf("typesense reported the operation as unsuccessful")
+		}
+
+		result.CompletedAt = ptr.To(metav1.Now())
+		result.Succeeded = err == nil
+		if err != nil {
+			r.logger.Error(err, "running operation failed", "type", to.Spec.Type, "node", node.PodName)
+			result.Error = err.Error()
+			failed++
+		}
+
+		results = append(results, result)
+	}
+
+	err = r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOperationStatus) {
+		status.Nodes = results
+		status.CompletedAt = ptr.To(metav1.Now())
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if failed > 0 {
+		return r.fail(ctx, to, fmt.Errorf("%s failed on %d of %d nodes", to.Spec.Type, failed, len(nodes)))
+	}
+
+	message := fmt.Sprintf("%s completed on %d nodes", to.Spec.Type, len(nodes))
+	err = r.setOperationPhase(ctx, to, tsv1alpha1.OperationCompleted, metav1.ConditionTrue, ConditionReasonOperationCompleted, message)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.Recorder.Eventf(to, "Normal", ConditionReasonOperationCompleted, message)
+	r.logger.Info("reconciling operation completed", "type", to.Spec.Type, "nodes", len(nodes))
+	return ctrl.Result{}, nil
+}
+
+func (r *TypesenseOperationReconciler) fail(ctx context.Context, to *tsv1alpha1.TypesenseOperation, err error) (ctrl.Result, error) {
+	cerr := r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOperationStatus) {
+		if status.CompletedAt == nil {
+			status.CompletedAt = ptr.To(metav1.Now())
+		}
+	})
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	cerr = r.setOperationPhase(ctx, to, tsv1alpha1.OperationFailed, metav1.ConditionFalse, ConditionReasonOperationFailed, err.Error())
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.Recorder.Eventf(to, "Warning", ConditionReasonOperationFailed, toTitle(err.Error()))
+	return ctrl.Result{}, nil
+}
+
+// newApiClient returns an api client for the cluster that does not require any of its nodes to be ready, it reuses
+// the plumbing of the quorum probes
+func (r *TypesenseOperationReconciler) newApiClient(ctx context.Context, ts *tsv1alpha1.TypesenseCluster, timeout time.Duration) (*typesenseApiClient, error) {
+	var secret = &corev1.Secret{}
+	if err := r.Get(ctx, getAdminApiKeyObjectKey(ts), secret); err != nil {
+		return nil, err
+	}
+
+	apiKey, ok := secret.Data[ClusterAdminApiKeySecretKeyName]
+	if !ok || len(apiKey) == 0 {
+		return nil, fmt.Errorf("secret %s is missing '%s' key", secret.Name, ClusterAdminApiKeySecretKeyName)
+	}
+
+	tlsConfig, err := newTLSClientConfig(ctx, r.Client, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHttpClient(r.Configuration, r.InCluster, timeout, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &typesenseApiClient{
+		httpClient: httpClient,
+		clientSet:  r.ClientSet,
+		inCluster:  r.InCluster,
+		ts:         ts,
+		apiKey:     string(apiKey),
+	}, nil
+}
+
+// getTargetNodes resolves the node of the operation to the running pods of the cluster, along with their raft role.
+// It returns a NotFound error if a named pod does not exist.
+func (r *TypesenseOperationReconciler) getTargetNodes(
+	ctx context.Context,
+	to *tsv1alpha1.TypesenseOperation,
+	ts *tsv1alpha1.TypesenseCluster,
+	api *typesenseApiClient,
+) ([]NodeEndpoint, map[string]NodeState, error) {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{
+		Namespace:     ts.Namespace,
+		LabelSelector: labels.SelectorFromSet(getLabels(ts)),
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	target := to.GetNode()
+	found := false
+	nodes := make([]NodeEndpoint, 0)
+	roles := make(map[string]NodeState)
+
+	for _, pod := range pods.Items {
+		if target != tsv1alpha1.OperationNodeLeader && target != tsv1alpha1.OperationNodeAll && target != pod.Name {
+			continue
+		}
+		found = true
+
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		node := NodeEndpoint{PodName: pod.Name, IP: net.ParseIP(pod.Status.PodIP)}
+
+		var nodeStatus NodeStatus
+		if err := api.withNode(node).withTimeout(typesenseApiRequestTimeout).do(ctx, http.MethodGet, "/status", nil, nil, &nodeStatus); err != nil {
+			nodeStatus.State = UnreachableState
+		}
+
+		if target == tsv1alpha1.OperationNodeLeader && nodeStatus.State != LeaderState {
+			continue
+		}
+
+		nodes = append(nodes, node)
+		roles[pod.Name] = nodeStatus.State
+	}
+
+	if !found && target != tsv1alpha1.OperationNodeLeader && target != tsv1alpha1.OperationNodeAll {
+		return nil, nil, apierrors.NewNotFound(corev1.Resource("pods"), target)
+	}
+
+	if len(nodes) == 0 {
+		if target == tsv1alpha1.OperationNodeLeader {
+			return nil, nil, fmt.Errorf("no leader found for cluster %s", ts.Name)
+		}
+		return nil, nil, fmt.Errorf("no running nodes found for %s", target)
+	}
+
+	return nodes, roles, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseOperation{}, eventFilters).
+		Named("typesenseoperation").
+		Complete(r)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseOperation Controller", func() {
+	Context("When resolving the target nodes of a TypesenseOperation", func() {
+		const clusterName = "test-operation"
+
+		ctx := context.Background()
+
+		var ts *tsv1alpha1.TypesenseCluster
+		var pods []corev1.Pod
+		var states map[string]NodeState
+		var server *httptest.Server
+		var controllerReconciler *TypesenseOperationReconciler
+
+		BeforeEach(func() {
+			// every node listens on its own loopback address, so each one reports its own state
+			states = map[string]NodeState{"127.0.0.1": FollowerState, "127.0.0.2": LeaderState}
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				host, _, err := net.SplitHostPort(r.Host)
+				Expect(err).NotTo(HaveOccurred())
+
+				state, ok := states[host]
+				if !ok {
+					writeJson(w, http.StatusInternalServerError, map[string]string{"message": "not ready"})
+					return
+				}
+
+				writeJson(w, http.StatusOK, NodeStatus{State: state})
+			}))
+			listener, err := net.Listen("tcp", "0.0.0.0:0")
+			Expect(err).NotTo(HaveOccurred())
+			server.Listener = listener
+			server.Start()
+
+			ts = &tsv1alpha1.TypesenseCluster{ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: "default"}}
+
+			pods = nil
+			for i, ip := range []string{"127.0.0.1", "127.0.0.2", "127.0.0.3", ""} {
+				pod := corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-sts-%d", clusterName, i), Namespace: "default", Labels: getLabels(ts)},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "typesense", Image: "typesense/typesense:29.0"}}},
+				}
+				Expect(k8sClient.Create(ctx, &pod)).To(Succeed())
+
+				pod.Status = corev1.PodStatus{Phase: corev1.PodRunning, PodIP: ip}
+				if ip == "" {
+					pod.Status.Phase = corev1.PodPending
+				}
+				Expect(k8sClient.Status().Update(ctx, &pod)).To(Succeed())
+				pods = append(pods, pod)
+			}
+
+			controllerReconciler = &TypesenseOperationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			for _, pod := range pods {
+				Expect(k8sClient.Delete(ctx, &pod)).To(Succeed())
+			}
+		})
+
+		getTargetNodes := func(node string) ([]string, map[string]NodeState, error) {
+			to := &tsv1alpha1.TypesenseOperation{Spec: tsv1alpha1.TypesenseOperationSpec{Node: node}}
+
+			nodes, roles, err := controllerReconciler.getTargetNodes(ctx, to, ts, newFakeTypesenseApiClient(server))
+			names := make([]string, 0, len(nodes))
+			for _, node := range nodes {
+				names = append(names, getShortName(node.PodName))
+			}
+
+			return names, roles, err
+		}
+
+		It("should only target the leader", func() {
+			nodes, roles, err := getTargetNodes(tsv1alpha1.OperationNodeLeader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(Equal([]string{"test-operation-sts-1"}))
+			Expect(roles).To(Equal(map[string]NodeState{pods[1].Name: LeaderState}))
+		})
+
+		It("should fail when there is no leader", func() {
+			states["127.0.0.2"] = FollowerState
+
+			_, _, err := getTargetNodes(tsv1alpha1.OperationNodeLeader)
+			Expect(err).To(MatchError(ContainSubstring("no leader found")))
+		})
+
+		It("should target every running node, reachable or not", func() {
+			nodes, roles, err := getTargetNodes(tsv1alpha1.OperationNodeAll)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(Equal([]string{"test-operation-sts-0", "test-operation-sts-1", "test-operation-sts-2"}))
+			Expect(roles).To(Equal(map[string]NodeState{
+				pods[0].Name: FollowerState,
+				pods[1].Name: LeaderState,
+				pods[2].Name: UnreachableState,
+			}))
+		})
+
+		It("should target a node by the name of its pod", func() {
+			nodes, _, err := getTargetNodes(pods[0].Name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(Equal([]string{"test-operation-sts-0"}))
+		})
+
+		It("should fail for a pod that is not running or does not exist", func() {
+			_, _, err := getTargetNodes(pods[3].Name)
+			Expect(err).To(MatchError(ContainSubstring("no running nodes found")))
+
+			_, _, err = getTargetNodes("test-operation-sts-9")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonOverrideReady    = "OverrideReady"
+	ConditionReasonOverrideNotReady = "OverrideNotReady"
+
+	UpdateOverrideStatusMessageFailed = "failed to update typesense override status"
+)
+
+func (r *TypesenseOverrideReconciler) initConditions(ctx context.Context, to *tsv1alpha1.TypesenseOverride) error {
+	if len(to.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOverrideStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateOverrideStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseOverrideReconciler) setConditionNotReady(ctx context.Context, to *tsv1alpha1.TypesenseOverride, reason string, err error) error {
+	if err := r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOverrideStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseOverrideReconciler) setConditionReady(ctx context.Context, to *tsv1alpha1.TypesenseOverride, reason string) error {
+	if err := r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOverrideStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Override is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseOverrideReconciler) patchStatus(
+	ctx context.Context,
+	to *tsv1alpha1.TypesenseOverride,
+	patcher func(status *tsv1alpha1.TypesenseOverrideStatus),
+) error {
+	patch := client.MergeFrom(to.DeepCopy())
+	patcher(&to.Status)
+
+	err := r.Status().Patch(ctx, to, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense override status")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unleto required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either expreto or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	overrideFinalizer = "ts.opentelekomcloud.com/delete-overrides"
+)
+
+// TypesenseOverrideReconciler reconciles a TypesenseOverride object
+type TypesenseOverrideReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// override is the wire representation of a curation override in the Typesense API
+type override struct {
+	Rule                overrideRule      `json:"rule"`
+	Includes            []overrideInclude `json:"includes,omitempty"`
+	Excludes            []overrideExclude `json:"excludes,omitempty"`
+	FilterBy            string            `json:"filter_by,omitempty"`
+	SortBy              string            `json:"sort_by,omitempty"`
+	ReplaceQuery        string            `json:"replace_query,omitempty"`
+	RemoveMatchedTokens *bool             `json:"remove_matched_tokens,omitempty"`
+	FilterCuratedHits   *bool             `json:"filter_curated_hits,omitempty"`
+	StopProcessing      *bool             `json:"stop_processing,omitempty"`
+	EffectiveFromTs     int64             `json:"effective_from_ts,omitempty"`
+	EffectiveToTs       int64             `json:"effective_to_ts,omitempty"`
+}
+
+type overrideRule struct {
+	Query    string   `json:"query,omitempty"`
+	Match    string   `json:"match,omitempty"`
+	FilterBy string   `json:"filter_by,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type overrideInclude struct {
+	Id       string `json:"id"`
+	Position int    `json:"position"`
+}
+
+type overrideExclude struct {
+	Id string `json:"id"`
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoverrides,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoverrides/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseoverrides/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile converges the overrides of the collection in the referenced TypesenseCluster with the spec, restoring the
+// ones modified outside the operator and pruning the ones removed from the spec. Deleting a TypesenseOverride
+// deletes its overrides, overrides created by other means are left in place.
+func (r *TypesenseOverrideReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "override", req.Name)
+
+	var to tsv1alpha1.TypesenseOverride
+	if err := r.Get(ctx, req.NamespacedName, &to); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: to.Namespace, Name: to.Spec.Cluster.Name}
+
+	if !to.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &to, clusterObjectKey)
+	}
+
+	if !controllerutil.ContainsFinalizer(&to, overrideFinalizer) {
+		controllerutil.AddFinalizer(&to, overrideFinalizer)
+		if err := r.Update(ctx, &to); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.logger.Info("reconciling override")
+
+	err := r.initConditions(ctx, &to)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &to, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	err = r.ReconcileOverrides(ctx, &to, api)
+	if err != nil {
+		if errors.Is(err, errCollectionNotFound) {
+			err = fmt.Errorf("collection %s not found", to.Spec.Collection)
+			report := to.Status.Phase != ConditionReasonCollectionNotFound
+
+			cerr := r.setConditionNotReady(ctx, &to, ConditionReasonCollectionNotFound, err)
+			if cerr != nil {
+				return ctrl.Result{}, cerr
+			}
+
+			if report {
+				r.Recorder.Eventf(&to, "Warning", ConditionReasonCollectionNotFound, toTitle(err.Error()))
+			}
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		cerr := r.setConditionNotReady(ctx, &to, ConditionReasonOverrideNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	cerr := r.setConditionReady(ctx, &to, ConditionReasonOverrideReady)
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.logger.Info("reconciling override completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileOverrides upserts the overrides that are missing or drifted from the spec, prunes the ones removed from the
+// spec and reports any drift in the status. When the collection changes, the overrides of the previous one are deleted.
+func (r *TypesenseOverrideReconciler) ReconcileOverrides(ctx context.Context, to *tsv1alpha1.TypesenseOverride, api *typesenseApiClient) error {
+	r.logger.V(debugLevel).Info("reconciling overrides")
+
+	if err := collectionExists(ctx, api, to.Spec.Collection); err != nil {
+		if !errors.Is(err, errCollectionNotFound) {
+			r.logger.Error(err, "unable to fetch collection", "collection", to.Spec.Collection)
+		}
+		return err
+	}
+
+	previous := to.Status.Applied
+	if to.Status.Collection != "" && to.Status.Collection != to.Spec.Collection {
+		if err := r.overrides(api, to.Status.Collection).deleteAll(ctx, previous); err != nil {
+			return err
+		}
+		previous = nil
+	}
+
+	desired := make([]searchTuningEntry, 0, len(to.Spec.Overrides))
+	for _, o := range to.Spec.Overrides {
+		desired = append(desired, searchTuningEntry{Id: o.Id, Body: getOverride(o)})
+	}
+
+	force := to.Status.ObservedGeneration != to.Generation
+	applied, drift, err := r.overrides(api, to.Spec.Collection).reconcile(ctx, desired, previous, force)
+	if err != nil {
+		return err
+	}
+
+	if len(drift) > 0 {
+		r.logger.Info("corrected drifted overrides", "drift", drift)
+		r.Recorder.Eventf(to, "Warning", ConditionReasonDriftCorrected, "Restored overrides modified outside the operator: %s", strings.Join(drift, ", "))
+	}
+
+	return r.patchStatus(ctx, to, func(status *tsv1alpha1.TypesenseOverrideStatus) {
+		status.ObservedGeneration = to.Generation
+		status.Collection = to.Spec.Collection
+		status.Applied = applied
+		if len(drift) > 0 {
+			now := metav1.Now()
+			status.Drift = drift
+			status.DriftDetectedAt = &now
+		}
+	})
+}
+
+func (r *TypesenseOverrideReconciler) overrides(api *typesenseApiClient, collection string) *searchTuningEntries {
+	return &searchTuningEntries{
+		api:    api,
+		logger: r.logger,
+		path:   "/collections/" + url.PathEscape(collection) + "/overrides",
+	}
+}
+
+func (r *TypesenseOverrideReconciler) finalize(ctx context.Context, to *tsv1alpha1.TypesenseOverride, clusterObjectKey client.ObjectKey) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(to, overrideFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("deleting override")
+
+	if len(to.Status.Applied) > 0 {
+		_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for typesense cluster to delete overrides", "cluster", clusterObjectKey.Name, "reason", err.Error())
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		// a cluster that is gone took its collections along, nothing left to delete
+		if api != nil {
+			if err := r.overrides(api, to.Status.Collection).deleteAll(ctx, to.Status.Applied); err != nil {
+				return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(to, overrideFinalizer)
+	if err := r.Update(ctx, to); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func getOverride(o tsv1alpha1.OverrideSpec) override {
+	ov := override{
+		Rule: overrideRule{
+			Query:    ptr.Deref(o.Rule.Query, ""),
+			Match:    ptr.Deref(o.Rule.Match, ""),
+			FilterBy: ptr.Deref(o.Rule.FilterBy, ""),
+			Tags:     o.Rule.Tags,
+		},
+		FilterBy:            ptr.Deref(o.FilterBy, ""),
+		SortBy:              ptr.Deref(o.SortBy, ""),
+		ReplaceQuery:        ptr.Deref(o.ReplaceQuery, ""),
+		RemoveMatchedTokens: o.RemoveMatchedTokens,
+		FilterCuratedHits:   o.FilterCuratedHits,
+		StopProcessing:      o.StopProcessing,
+	}
+
+	for _, include := range o.Includes {
+		ov.Includes = append(ov.Includes, overrideInclude{Id: include.Id, Position: include.Position})
+	}
+
+	for _, exclude := range o.Excludes {
+		ov.Excludes = append(ov.Excludes, overrideExclude{Id: exclude.Id})
+	}
+
+	if o.EffectiveFrom != nil {
+		ov.EffectiveFromTs = o.EffectiveFrom.Unix()
+	}
+
+	if o.EffectiveTo != nil {
+		ov.EffectiveToTs = o.EffectiveTo.Unix()
+	}
+
+	return ov
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseOverrideReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseOverride{}, eventFilters).
+		Named("typesenseoverride").
+		Complete(r)
+}
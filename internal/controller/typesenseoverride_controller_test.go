@@ -0,0 +1,121 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseOverride Controller", func() {
+	Context("When reconciling the overrides of a TypesenseOverride", func() {
+		const resourceName = "test-override"
+		const overridesPath = "/collections/companies/overrides/"
+
+		ctx := context.Background()
+
+		var to *tsv1alpha1.TypesenseOverride
+		var server *fakeSearchTuningServer
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseOverrideReconciler
+
+		BeforeEach(func() {
+			server = newFakeSearchTuningServer("companies")
+
+			to = &tsv1alpha1.TypesenseOverride{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseOverrideSpec{
+					Cluster:    corev1.LocalObjectReference{Name: "fake"},
+					Collection: "companies",
+					Overrides: []tsv1alpha1.OverrideSpec{
+						{
+							Id:       "pin-acme",
+							Rule:     tsv1alpha1.OverrideRuleSpec{Query: ptr.To("acme"), Match: ptr.To("exact")},
+							Includes: []tsv1alpha1.OverrideIncludeSpec{{Id: "1", Position: 1}},
+						},
+						{
+							Id:       "hide-legacy",
+							Rule:     tsv1alpha1.OverrideRuleSpec{Query: ptr.To("legacy"), Match: ptr.To("contains")},
+							Excludes: []tsv1alpha1.OverrideExcludeSpec{{Id: "42"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, to)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseOverrideReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(k8sClient.Delete(ctx, to)).To(Succeed())
+		})
+
+		reconcileOverrides := func() {
+			Expect(controllerReconciler.ReconcileOverrides(ctx, to, newFakeTypesenseApiClient(server.Server))).To(Succeed())
+		}
+
+		It("should prune the overrides removed from the spec", func() {
+			reconcileOverrides()
+			Expect(to.Status.Applied).To(Equal([]string{"pin-acme", "hide-legacy"}))
+
+			// as the api server does on a spec change
+			to.Spec.Overrides = to.Spec.Overrides[:1]
+			to.Generation++
+			reconcileOverrides()
+
+			Expect(server.deleted).To(Equal([]string{overridesPath + "hide-legacy"}))
+			Expect(to.Status.Applied).To(Equal([]string{"pin-acme"}))
+		})
+
+		It("should restore the overrides modified outside the operator and report the drift", func() {
+			reconcileOverrides()
+
+			server.entries[overridesPath+"pin-acme"]["includes"] = []any{map[string]any{"id": "2", "position": 1}}
+			reconcileOverrides()
+
+			Expect(server.entries[overridesPath+"pin-acme"]).To(HaveKeyWithValue("includes", ConsistOf(HaveKeyWithValue("id", "1"))))
+			Expect(to.Status.Drift).To(Equal([]string{"pin-acme was modified"}))
+			Expect(<-recorder.Events).To(ContainSubstring(ConditionReasonDriftCorrected))
+		})
+
+		It("should not report the fields the server fills in as drift", func() {
+			reconcileOverrides()
+
+			server.entries[overridesPath+"pin-acme"]["filter_curated_hits"] = false
+			server.entries[overridesPath+"pin-acme"]["stop_processing"] = true
+			reconcileOverrides()
+
+			Expect(to.Status.Drift).To(BeEmpty())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+})
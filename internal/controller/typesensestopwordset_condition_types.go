@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonStopwordSetReady    = "StopwordSetReady"
+	ConditionReasonStopwordSetNotReady = "StopwordSetNotReady"
+
+	UpdateStopwordSetStatusMessageFailed = "failed to update typesense stopword set status"
+)
+
+func (r *TypesenseStopwordSetReconciler) initConditions(ctx context.Context, sw *tsv1alpha1.TypesenseStopwordSet) error {
+	if len(sw.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, sw, func(status *tsv1alpha1.TypesenseStopwordSetStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateStopwordSetStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseStopwordSetReconciler) setConditionNotReady(ctx context.Context, sw *tsv1alpha1.TypesenseStopwordSet, reason string, err error) error {
+	if err := r.patchStatus(ctx, sw, func(status *tsv1alpha1.TypesenseStopwordSetStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseStopwordSetReconciler) setConditionReady(ctx context.Context, sw *tsv1alpha1.TypesenseStopwordSet, reason string) error {
+	if err := r.patchStatus(ctx, sw, func(status *tsv1alpha1.TypesenseStopwordSetStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Stopword set is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseStopwordSetReconciler) patchStatus(
+	ctx context.Context,
+	sw *tsv1alpha1.TypesenseStopwordSet,
+	patcher func(status *tsv1alpha1.TypesenseStopwordSetStatus),
+) error {
+	patch := client.MergeFrom(sw.DeepCopy())
+	patcher(&sw.Status)
+
+	err := r.Status().Patch(ctx, sw, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense stopword set status")
+		return err
+	}
+
+	return nil
+}
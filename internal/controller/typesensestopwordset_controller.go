@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unlesw required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either expresw or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	stopwordSetFinalizer = "ts.opentelekomcloud.com/delete-stopwords"
+)
+
+// TypesenseStopwordSetReconciler reconciles a TypesenseStopwordSet object
+type TypesenseStopwordSetReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// stopwordSet is the wire representation of a stopword set in the Typesense API
+type stopwordSet struct {
+	Stopwords []string `json:"stopwords"`
+	Locale    string   `json:"locale,omitempty"`
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensestopwordsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensestopwordsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensestopwordsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile converges the stopword set in the referenced TypesenseCluster with the spec, restoring it when it is
+// modified outside the operator. Deleting a TypesenseStopwordSet deletes the stopword set.
+func (r *TypesenseStopwordSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "stopwordset", req.Name)
+
+	var sw tsv1alpha1.TypesenseStopwordSet
+	if err := r.Get(ctx, req.NamespacedName, &sw); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: sw.Namespace, Name: sw.Spec.Cluster.Name}
+
+	if !sw.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &sw, clusterObjectKey)
+	}
+
+	if !controllerutil.ContainsFinalizer(&sw, stopwordSetFinalizer) {
+		controllerutil.AddFinalizer(&sw, stopwordSetFinalizer)
+		if err := r.Update(ctx, &sw); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.logger.Info("reconciling stopword set")
+
+	err := r.initConditions(ctx, &sw)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &sw, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	err = r.ReconcileStopwords(ctx, &sw, api)
+	if err != nil {
+		cerr := r.setConditionNotReady(ctx, &sw, ConditionReasonStopwordSetNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	cerr := r.setConditionReady(ctx, &sw, ConditionReasonStopwordSetReady)
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.logger.Info("reconciling stopword set completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileStopwords upserts the stopword set when it is missing or drifted from the spec and reports any drift in
+// the status. When the name of the set changes, the previous one is deleted.
+func (r *TypesenseStopwordSetReconciler) ReconcileStopwords(ctx context.Context, sw *tsv1alpha1.TypesenseStopwordSet, api *typesenseApiClient) error {
+	r.logger.V(debugLevel).Info("reconciling stopwords")
+
+	var previous []string
+	if sw.Status.StopwordSetName != "" {
+		previous = append(previous, sw.Status.StopwordSetName)
+	}
+
+	desired := []searchTuningEntry{{Id: sw.GetStopwordSetName(), Body: getStopwordSet(sw.Spec)}}
+
+	force := sw.Status.ObservedGeneration != sw.Generation
+	applied, drift, err := r.stopwords(api).reconcile(ctx, desired, previous, force)
+	if err != nil {
+		return err
+	}
+
+	if len(drift) > 0 {
+		r.logger.Info("corrected drifted stopword set", "drift", drift)
+		r.Recorder.Eventf(sw, "Warning", ConditionReasonDriftCorrected, "Restored stopword set modified outside the operator: %s", strings.Join(drift, ", "))
+	}
+
+	return r.patchStatus(ctx, sw, func(status *tsv1alpha1.TypesenseStopwordSetStatus) {
+		status.ObservedGeneration = sw.Generation
+		status.StopwordSetName = applied[0]
+		if len(drift) > 0 {
+			now := metav1.Now()
+			status.Drift = drift
+			status.DriftDetectedAt = &now
+		}
+	})
+}
+
+func (r *TypesenseStopwordSetReconciler) stopwords(api *typesenseApiClient) *searchTuningEntries {
+	return &searchTuningEntries{
+		api:      api,
+		logger:   r.logger,
+		path:     "/stopwords",
+		envelope: "stopwords",
+	}
+}
+
+func (r *TypesenseStopwordSetReconciler) finalize(ctx context.Context, sw *tsv1alpha1.TypesenseStopwordSet, clusterObjectKey client.ObjectKey) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(sw, stopwordSetFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("deleting stopword set")
+
+	if sw.Status.StopwordSetName != "" {
+		_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for typesense cluster to delete stopword set", "cluster", clusterObjectKey.Name, "reason", err.Error())
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		// a cluster that is gone took its stopword sets along, nothing left to delete
+		if api != nil {
+			if err := r.stopwords(api).deleteAll(ctx, []string{sw.Status.StopwordSetName}); err != nil {
+				return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(sw, stopwordSetFinalizer)
+	if err := r.Update(ctx, sw); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getStopwordSet lowercases the stopwords, the way Typesense stores them, so they compare equal with what it serves
+func getStopwordSet(spec tsv1alpha1.TypesenseStopwordSetSpec) stopwordSet {
+	set := stopwordSet{
+		Stopwords: make([]string, 0, len(spec.Stopwords)),
+		Locale:    ptr.Deref(spec.Locale, ""),
+	}
+
+	for _, word := range spec.Stopwords {
+		set.Stopwords = append(set.Stopwords, strings.ToLower(strings.TrimSpace(word)))
+	}
+
+	return set
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseStopwordSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseStopwordSet{}, eventFilters).
+		Named("typesensestopwordset").
+		Complete(r)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseStopwordSet Controller", func() {
+	Context("When reconciling the stopwords of a TypesenseStopwordSet", func() {
+		const resourceName = "test-stopwordset"
+
+		ctx := context.Background()
+
+		var sw *tsv1alpha1.TypesenseStopwordSet
+		var server *fakeSearchTuningServer
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseStopwordSetReconciler
+
+		BeforeEach(func() {
+			server = newFakeSearchTuningServer()
+
+			sw = &tsv1alpha1.TypesenseStopwordSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseStopwordSetSpec{
+					Cluster:   corev1.LocalObjectReference{Name: "fake"},
+					Stopwords: []string{" The", "a", "AN"},
+					Locale:    ptr.To("en"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, sw)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseStopwordSetReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(k8sClient.Delete(ctx, sw)).To(Succeed())
+		})
+
+		reconcileStopwords := func() {
+			Expect(controllerReconciler.ReconcileStopwords(ctx, sw, newFakeTypesenseApiClient(server.Server))).To(Succeed())
+		}
+
+		It("should apply the normalized stopwords", func() {
+			reconcileStopwords()
+
+			Expect(server.entries["/stopwords/test-stopwordset"]).To(HaveKeyWithValue("stopwords", ConsistOf("the", "a", "an")))
+			Expect(sw.Status.StopwordSetName).To(Equal(resourceName))
+		})
+
+		It("should prune the previous set when the name changes", func() {
+			reconcileStopwords()
+
+			// as the api server does on a spec change
+			sw.Spec.Name = ptr.To("english")
+			sw.Generation++
+			reconcileStopwords()
+
+			Expect(server.deleted).To(Equal([]string{"/stopwords/test-stopwordset"}))
+			Expect(server.entries).To(HaveKey("/stopwords/english"))
+			Expect(sw.Status.StopwordSetName).To(Equal("english"))
+		})
+
+		It("should restore the stopwords modified outside the operator and report the drift", func() {
+			reconcileStopwords()
+
+			server.entries["/stopwords/test-stopwordset"]["stopwords"] = []any{"the"}
+			reconcileStopwords()
+
+			Expect(server.entries["/stopwords/test-stopwordset"]).To(HaveKeyWithValue("stopwords", ConsistOf("the", "a", "an")))
+			Expect(sw.Status.Drift).To(Equal([]string{"test-stopwordset was modified"}))
+			Expect(<-recorder.Events).To(ContainSubstring(ConditionReasonDriftCorrected))
+		})
+	})
+})
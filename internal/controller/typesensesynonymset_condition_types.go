@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definitions to manage status conditions
+const (
+	ConditionReasonSynonymSetReady    = "SynonymSetReady"
+	ConditionReasonSynonymSetNotReady = "SynonymSetNotReady"
+
+	UpdateSynonymSetStatusMessageFailed = "failed to update typesense synonym set status"
+)
+
+func (r *TypesenseSynonymSetReconciler) initConditions(ctx context.Context, ss *tsv1alpha1.TypesenseSynonymSet) error {
+	if len(ss.Status.Conditions) == 0 {
+		if err := r.patchStatus(ctx, ss, func(status *tsv1alpha1.TypesenseSynonymSetStatus) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionUnknown, Reason: ConditionReasonReconciliationInProgress, Message: InitReconciliationMessage})
+			status.Phase = "Pending"
+		}); err != nil {
+			r.logger.Error(err, UpdateSynonymSetStatusMessageFailed)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TypesenseSynonymSetReconciler) setConditionNotReady(ctx context.Context, ss *tsv1alpha1.TypesenseSynonymSet, reason string, err error) error {
+	if err := r.patchStatus(ctx, ss, func(status *tsv1alpha1.TypesenseSynonymSetStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseSynonymSetReconciler) setConditionReady(ctx context.Context, ss *tsv1alpha1.TypesenseSynonymSet, reason string) error {
+	if err := r.patchStatus(ctx, ss, func(status *tsv1alpha1.TypesenseSynonymSetStatus) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: reason, Message: "Synonym set is Ready"})
+		status.Phase = reason
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TypesenseSynonymSetReconciler) patchStatus(
+	ctx context.Context,
+	ss *tsv1alpha1.TypesenseSynonymSet,
+	patcher func(status *tsv1alpha1.TypesenseSynonymSetStatus),
+) error {
+	patch := client.MergeFrom(ss.DeepCopy())
+	patcher(&ss.Status)
+
+	err := r.Status().Patch(ctx, ss, patch)
+	if err != nil {
+		r.logger.Error(err, "unable to patch typesense synonym set status")
+		return err
+	}
+
+	return nil
+}
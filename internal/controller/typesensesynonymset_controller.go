@@ -0,0 +1,256 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	synonymSetFinalizer = "ts.opentelekomcloud.com/delete-synonyms"
+)
+
+// TypesenseSynonymSetReconciler reconciles a TypesenseSynonymSet object
+type TypesenseSynonymSetReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	logger        logr.Logger
+	Recorder      record.EventRecorder
+	ClientSet     *kubernetes.Clientset
+	Configuration *rest.Config
+	InCluster     bool
+}
+
+// synonym is the wire representation of a synonym in the Typesense API
+type synonym struct {
+	Root           string   `json:"root,omitempty"`
+	Synonyms       []string `json:"synonyms"`
+	Locale         string   `json:"locale,omitempty"`
+	SymbolsToIndex []string `json:"symbols_to_index,omitempty"`
+}
+
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensesynonymsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensesynonymsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesensesynonymsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=get;list;watch
+
+// Reconcile converges the synonyms of the collection in the referenced TypesenseCluster with the spec, restoring the
+// ones modified outside the operator and pruning the ones removed from the spec. Deleting a TypesenseSynonymSet
+// deletes its synonyms, synonyms created by other means are left in place.
+func (r *TypesenseSynonymSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.logger = log.Log.WithValues("namespace", req.Namespace, "synonymset", req.Name)
+
+	var ss tsv1alpha1.TypesenseSynonymSet
+	if err := r.Get(ctx, req.NamespacedName, &ss); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clusterObjectKey := client.ObjectKey{Namespace: ss.Namespace, Name: ss.Spec.Cluster.Name}
+
+	if !ss.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &ss, clusterObjectKey)
+	}
+
+	if !controllerutil.ContainsFinalizer(&ss, synonymSetFinalizer) {
+		controllerutil.AddFinalizer(&ss, synonymSetFinalizer)
+		if err := r.Update(ctx, &ss); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.logger.Info("reconciling synonym set")
+
+	err := r.initConditions(ctx, &ss)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("typesense cluster %s not found", clusterObjectKey.Name)
+		}
+
+		r.logger.V(debugLevel).Info("waiting for typesense cluster", "cluster", clusterObjectKey.Name, "reason", err.Error())
+		cerr := r.setConditionNotReady(ctx, &ss, ConditionReasonClusterNotReady, err)
+		if cerr != nil {
+			return ctrl.Result{}, cerr
+		}
+		return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+	}
+
+	err = r.ReconcileSynonyms(ctx, &ss, api)
+	if err != nil {
+		if errors.Is(err, errCollectionNotFound) {
+			err = fmt.Errorf("collection %s not found", ss.Spec.Collection)
+			report := ss.Status.Phase != ConditionReasonCollectionNotFound
+
+			cerr := r.setConditionNotReady(ctx, &ss, ConditionReasonCollectionNotFound, err)
+			if cerr != nil {
+				return ctrl.Result{}, cerr
+			}
+
+			if report {
+				r.Recorder.Eventf(&ss, "Warning", ConditionReasonCollectionNotFound, toTitle(err.Error()))
+			}
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		cerr := r.setConditionNotReady(ctx, &ss, ConditionReasonSynonymSetNotReady, err)
+		if cerr != nil {
+			err = errors.Wrap(err, cerr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	cerr := r.setConditionReady(ctx, &ss, ConditionReasonSynonymSetReady)
+	if cerr != nil {
+		return ctrl.Result{}, cerr
+	}
+
+	r.logger.Info("reconciling synonym set completed", "requeueAfter", reconcileRequeuePeriod)
+	return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+}
+
+// ReconcileSynonyms upserts the synonyms that are missing or drifted from the spec, prunes the ones removed from the
+// spec and reports any drift in the status. When the collection changes, the synonyms of the previous one are deleted.
+func (r *TypesenseSynonymSetReconciler) ReconcileSynonyms(ctx context.Context, ss *tsv1alpha1.TypesenseSynonymSet, api *typesenseApiClient) error {
+	r.logger.V(debugLevel).Info("reconciling synonyms")
+
+	if err := collectionExists(ctx, api, ss.Spec.Collection); err != nil {
+		if !errors.Is(err, errCollectionNotFound) {
+			r.logger.Error(err, "unable to fetch collection", "collection", ss.Spec.Collection)
+		}
+		return err
+	}
+
+	previous := ss.Status.Applied
+	if ss.Status.Collection != "" && ss.Status.Collection != ss.Spec.Collection {
+		if err := r.synonyms(api, ss.Status.Collection).deleteAll(ctx, previous); err != nil {
+			return err
+		}
+		previous = nil
+	}
+
+	desired := make([]searchTuningEntry, 0, len(ss.Spec.Synonyms))
+	for _, s := range ss.Spec.Synonyms {
+		desired = append(desired, searchTuningEntry{Id: s.Id, Body: getSynonym(s)})
+	}
+
+	force := ss.Status.ObservedGeneration != ss.Generation
+	applied, drift, err := r.synonyms(api, ss.Spec.Collection).reconcile(ctx, desired, previous, force)
+	if err != nil {
+		return err
+	}
+
+	if len(drift) > 0 {
+		r.logger.Info("corrected drifted synonyms", "drift", drift)
+		r.Recorder.Eventf(ss, "Warning", ConditionReasonDriftCorrected, "Restored synonyms modified outside the operator: %s", strings.Join(drift, ", "))
+	}
+
+	return r.patchStatus(ctx, ss, func(status *tsv1alpha1.TypesenseSynonymSetStatus) {
+		status.ObservedGeneration = ss.Generation
+		status.Collection = ss.Spec.Collection
+		status.Applied = applied
+		if len(drift) > 0 {
+			now := metav1.Now()
+			status.Drift = drift
+			status.DriftDetectedAt = &now
+		}
+	})
+}
+
+func (r *TypesenseSynonymSetReconciler) synonyms(api *typesenseApiClient, collection string) *searchTuningEntries {
+	return &searchTuningEntries{
+		api:    api,
+		logger: r.logger,
+		path:   "/collections/" + url.PathEscape(collection) + "/synonyms",
+	}
+}
+
+func (r *TypesenseSynonymSetReconciler) finalize(ctx context.Context, ss *tsv1alpha1.TypesenseSynonymSet, clusterObjectKey client.ObjectKey) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(ss, synonymSetFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("deleting synonym set")
+
+	if len(ss.Status.Applied) > 0 {
+		_, api, err := newTypesenseApiClientForCluster(ctx, r.Client, r.Configuration, r.ClientSet, r.InCluster, clusterObjectKey)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.logger.V(debugLevel).Info("waiting for typesense cluster to delete synonyms", "cluster", clusterObjectKey.Name, "reason", err.Error())
+			return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+		}
+
+		// a cluster that is gone took its collections along, nothing left to delete
+		if api != nil {
+			if err := r.synonyms(api, ss.Status.Collection).deleteAll(ctx, ss.Status.Applied); err != nil {
+				return ctrl.Result{RequeueAfter: reconcileRequeuePeriod}, nil
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ss, synonymSetFinalizer)
+	if err := r.Update(ctx, ss); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func getSynonym(s tsv1alpha1.SynonymSpec) synonym {
+	syn := synonym{
+		Synonyms:       s.Synonyms,
+		SymbolsToIndex: s.SymbolsToIndex,
+	}
+
+	if s.Root != nil {
+		syn.Root = *s.Root
+	}
+
+	if s.Locale != nil {
+		syn.Locale = *s.Locale
+	}
+
+	return syn
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TypesenseSynonymSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tsv1alpha1.TypesenseSynonymSet{}, eventFilters).
+		Named("typesensesynonymset").
+		Complete(r)
+}
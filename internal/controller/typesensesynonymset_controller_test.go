@@ -0,0 +1,202 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+// fakeSearchTuningServer serves the synonyms, overrides and stopword sets of a cluster from memory
+type fakeSearchTuningServer struct {
+	*httptest.Server
+	collections []string
+	// entries by their path, e.g. /collections/companies/synonyms/coat
+	entries map[string]map[string]any
+	// deleted lists the paths of the deleted entries
+	deleted []string
+}
+
+func newFakeSearchTuningServer(collections ...string) *fakeSearchTuningServer {
+	s := &fakeSearchTuningServer{collections: collections, entries: map[string]map[string]any{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+
+		if collection, ok := strings.CutPrefix(r.URL.Path, "/collections/"); ok && !strings.Contains(collection, "/") {
+			if !slices.Contains(s.collections, collection) {
+				writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+				return
+			}
+
+			writeJson(w, http.StatusOK, collectionSchema{Name: collection})
+			return
+		}
+
+		entry, ok := s.entries[r.URL.Path]
+		switch r.Method {
+		case http.MethodGet:
+			if !ok {
+				writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+				return
+			}
+
+			if strings.HasPrefix(r.URL.Path, "/stopwords/") {
+				writeJson(w, http.StatusOK, map[string]any{"stopwords": entry})
+				return
+			}
+			writeJson(w, http.StatusOK, entry)
+		case http.MethodPut:
+			entry = map[string]any{}
+			Expect(json.NewDecoder(r.Body).Decode(&entry)).To(Succeed())
+			entry["id"] = path.Base(r.URL.Path)
+			s.entries[r.URL.Path] = entry
+
+			writeJson(w, http.StatusOK, entry)
+		case http.MethodDelete:
+			if !ok {
+				writeJson(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+				return
+			}
+
+			delete(s.entries, r.URL.Path)
+			s.deleted = append(s.deleted, r.URL.Path)
+			writeJson(w, http.StatusOK, entry)
+		}
+	}))
+
+	return s
+}
+
+var _ = Describe("TypesenseSynonymSet Controller", func() {
+	Context("When reconciling the synonyms of a TypesenseSynonymSet", func() {
+		const resourceName = "test-synonymset"
+		const synonymsPath = "/collections/companies/synonyms/"
+
+		ctx := context.Background()
+
+		var ss *tsv1alpha1.TypesenseSynonymSet
+		var server *fakeSearchTuningServer
+		var recorder *record.FakeRecorder
+		var controllerReconciler *TypesenseSynonymSetReconciler
+
+		BeforeEach(func() {
+			server = newFakeSearchTuningServer("companies", "companies-v2")
+
+			ss = &tsv1alpha1.TypesenseSynonymSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: tsv1alpha1.TypesenseSynonymSetSpec{
+					Cluster:    corev1.LocalObjectReference{Name: "fake"},
+					Collection: "companies",
+					Synonyms: []tsv1alpha1.SynonymSpec{
+						{Id: "coat", Synonyms: []string{"coat", "jacket"}},
+						{Id: "sneaker", Root: ptr.To("shoe"), Synonyms: []string{"sneaker", "trainer"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ss)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			controllerReconciler = &TypesenseSynonymSetReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(k8sClient.Delete(ctx, ss)).To(Succeed())
+		})
+
+		reconcileSynonyms := func() {
+			Expect(controllerReconciler.ReconcileSynonyms(ctx, ss, newFakeTypesenseApiClient(server.Server))).To(Succeed())
+		}
+
+		It("should apply the synonyms of the spec", func() {
+			reconcileSynonyms()
+
+			Expect(server.entries).To(HaveLen(2))
+			Expect(server.entries[synonymsPath+"sneaker"]).To(HaveKeyWithValue("root", "shoe"))
+			Expect(ss.Status.Applied).To(Equal([]string{"coat", "sneaker"}))
+			Expect(ss.Status.Collection).To(Equal("companies"))
+			Expect(ss.Status.Drift).To(BeEmpty())
+		})
+
+		It("should prune the synonyms removed from the spec and leave the others in place", func() {
+			server.entries[synonymsPath+"manual"] = map[string]any{"id": "manual", "synonyms": []any{"a", "b"}}
+			reconcileSynonyms()
+
+			// as the api server does on a spec change
+			ss.Spec.Synonyms = ss.Spec.Synonyms[:1]
+			ss.Generation++
+			reconcileSynonyms()
+
+			Expect(server.deleted).To(Equal([]string{synonymsPath + "sneaker"}))
+			Expect(server.entries).To(HaveKey(synonymsPath + "manual"))
+			Expect(ss.Status.Applied).To(Equal([]string{"coat"}))
+		})
+
+		It("should restore the synonyms modified outside the operator and report the drift", func() {
+			reconcileSynonyms()
+
+			server.entries[synonymsPath+"coat"]["synonyms"] = []any{"coat"}
+			delete(server.entries, synonymsPath+"sneaker")
+			reconcileSynonyms()
+
+			Expect(server.entries[synonymsPath+"coat"]).To(HaveKeyWithValue("synonyms", ConsistOf("coat", "jacket")))
+			Expect(server.entries).To(HaveKey(synonymsPath + "sneaker"))
+			Expect(ss.Status.Drift).To(ConsistOf("coat was modified", "sneaker was deleted"))
+			Expect(ss.Status.DriftDetectedAt).NotTo(BeNil())
+			Expect(<-recorder.Events).To(ContainSubstring(ConditionReasonDriftCorrected))
+		})
+
+		It("should move the synonyms when the collection changes", func() {
+			reconcileSynonyms()
+
+			ss.Spec.Collection = "companies-v2"
+			ss.Generation++
+			reconcileSynonyms()
+
+			Expect(server.deleted).To(ConsistOf(synonymsPath+"coat", synonymsPath+"sneaker"))
+			Expect(server.entries).To(HaveKey("/collections/companies-v2/synonyms/coat"))
+			Expect(ss.Status.Collection).To(Equal("companies-v2"))
+		})
+
+		It("should wait for the collection to exist", func() {
+			ss.Spec.Collection = "missing"
+
+			err := controllerReconciler.ReconcileSynonyms(ctx, ss, newFakeTypesenseApiClient(server.Server))
+			Expect(err).To(MatchError(errCollectionNotFound))
+			Expect(server.entries).To(BeEmpty())
+		})
+	})
+})
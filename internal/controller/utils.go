@@ -182,6 +182,16 @@ func getDelayPerReplicaFactor(size int) int64 {
 	return minDelayPerReplicaFactor
 }
 
+func annotationChanged(old, new metav1.Object, keys ...string) bool {
+	for _, key := range keys {
+		if old.GetAnnotations()[key] != new.GetAnnotations()[key] {
+			return true
+		}
+	}
+
+	return false
+}
+
 func contains(values []string, value string) (int, bool) {
 	//sort.Strings(values)
 
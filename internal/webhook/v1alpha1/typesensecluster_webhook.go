@@ -0,0 +1,379 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+const (
+	// raftNodeNameLimit mirrors the limit getNodes enforces on the raft node names, e.g. <name>-sts-0.<name>-sts-svc
+	raftNodeNameLimit = 64
+	raftNodeName      = "%s-sts-%d.%s-sts-svc"
+
+	metricsExporterPort = 9100
+	healthcheckPort     = 8808
+
+	maxReplicas        = 7
+	scaleDefaulterPath = "/mutate-ts-opentelekomcloud-com-v1alpha1-typesensecluster-scale"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var typesenseclusterlog = logf.Log.WithName("typesensecluster-resource")
+
+// SetupTypesenseClusterWebhookWithManager registers the webhook for TypesenseCluster in the manager.
+func SetupTypesenseClusterWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scaleDefaulterPath, &webhook.Admission{
+		Handler: &TypesenseClusterScaleDefaulter{decoder: admission.NewDecoder(mgr.GetScheme())},
+	})
+
+	return ctrl.NewWebhookManagedBy(mgr).For(&tsv1alpha1.TypesenseCluster{}).
+		WithValidator(&TypesenseClusterCustomValidator{}).
+		WithDefaulter(&TypesenseClusterCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ts-opentelekomcloud-com-v1alpha1-typesensecluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=create;update,versions=v1alpha1,name=mtypesensecluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TypesenseClusterCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind TypesenseCluster when those are created or updated.
+type TypesenseClusterCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &TypesenseClusterCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind TypesenseCluster. It
+// persists the same defaults the Get* helpers of the spec apply, so what is stored is what gets reconciled.
+// Optional features that are toggled by the presence of their block, e.g. metrics or ingress, are not enabled.
+func (d *TypesenseClusterCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	ts, ok := obj.(*tsv1alpha1.TypesenseCluster)
+	if !ok {
+		return fmt.Errorf("expected a TypesenseCluster object but got %T", obj)
+	}
+	typesenseclusterlog.Info("Defaulting for TypesenseCluster", "name", ts.GetName())
+
+	spec := &ts.Spec
+
+	if spec.Replicas == 0 {
+		spec.Replicas = 3
+	}
+	spec.Replicas = getQuorumReplicas(spec.Replicas)
+	if spec.ApiPort == 0 {
+		spec.ApiPort = 8108
+	}
+	if spec.PeeringPort == 0 {
+		spec.PeeringPort = 8107
+	}
+	if spec.HealthProbeTimeoutInMilliseconds == 0 {
+		spec.HealthProbeTimeoutInMilliseconds = 500
+	}
+	if spec.PodManagementPolicy == nil {
+		spec.PodManagementPolicy = ptr.To(spec.GetPodManagementPolicy())
+	}
+	if spec.TerminationGracePeriodSeconds == nil {
+		spec.TerminationGracePeriodSeconds = ptr.To(spec.GetTerminationGracePeriodSeconds())
+	}
+	if spec.Resources == nil {
+		spec.Resources = ptr.To(spec.GetResources())
+	}
+
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &tsv1alpha1.SecurityContextSpec{}
+	}
+	if spec.SecurityContext.PodSecurityContext == nil {
+		spec.SecurityContext.PodSecurityContext = spec.GetPodSecurityContext()
+	}
+
+	if spec.HealthCheck == nil {
+		spec.HealthCheck = ptr.To(spec.GetHealthCheckSidecarSpecs())
+	}
+	if spec.HealthCheck.Image == "" {
+		spec.HealthCheck.Image = (&tsv1alpha1.TypesenseClusterSpec{}).GetHealthCheckSidecarSpecs().Image
+	}
+
+	if spec.Storage == nil {
+		spec.Storage = ptr.To(spec.GetStorage())
+	}
+	defaults := (&tsv1alpha1.TypesenseClusterSpec{}).GetStorage()
+	if spec.Storage.Size.IsZero() {
+		spec.Storage.Size = defaults.Size
+	}
+	if spec.Storage.AccessMode == "" {
+		spec.Storage.AccessMode = defaults.AccessMode
+	}
+
+	if spec.Metrics != nil {
+		defaults := (&tsv1alpha1.TypesenseClusterSpec{}).GetMetricsExporterSpecs()
+		if spec.Metrics.Image == "" {
+			spec.Metrics.Image = defaults.Image
+		}
+		if spec.Metrics.IntervalInSeconds == 0 {
+			spec.Metrics.IntervalInSeconds = defaults.IntervalInSeconds
+		}
+	}
+
+	if spec.Ingress != nil {
+		if spec.Ingress.Image == "" {
+			spec.Ingress.Image = "nginx:alpine"
+		}
+		if spec.Ingress.Path == "" {
+			spec.Ingress.Path = "/"
+		}
+		if spec.Ingress.PathType == nil {
+			spec.Ingress.PathType = ptr.To(networkingv1.PathTypeImplementationSpecific)
+		}
+	}
+
+	for i := range spec.HttpRoutes {
+		route := &spec.HttpRoutes[i]
+		if route.Path == "" {
+			route.Path = "/"
+		}
+		if route.PathType == nil {
+			route.PathType = ptr.To(gatewayv1.PathMatchPathPrefix)
+		}
+		if route.ReferenceGrant == nil {
+			route.ReferenceGrant = ptr.To(false)
+		}
+	}
+
+	if spec.AdminApiKeyRotation != nil && spec.AdminApiKeyRotation.GracePeriodMinutes == nil {
+		spec.AdminApiKeyRotation.GracePeriodMinutes = ptr.To(int32(spec.GetAdminApiKeyRotationGracePeriod().Minutes()))
+	}
+
+	if spec.Restore != nil && spec.Restore.Image == "" {
+		spec.Restore.Image = spec.Restore.GetImage()
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-ts-opentelekomcloud-com-v1alpha1-typesensecluster-scale,mutating=true,failurePolicy=fail,sideEffects=None,groups=ts.opentelekomcloud.com,resources=typesenseclusters/scale,verbs=update,versions=v1alpha1,name=mtypesenseclusterscale-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TypesenseClusterScaleDefaulter rounds the replicas written through the scale subresource, e.g. by a
+// HorizontalPodAutoscaler, up to the next size a raft quorum can have, as the schema only admits odd sizes.
+type TypesenseClusterScaleDefaulter struct {
+	decoder admission.Decoder
+}
+
+var _ admission.Handler = &TypesenseClusterScaleDefaulter{}
+
+// Handle implements admission.Handler for the scale subresource of the Kind TypesenseCluster.
+func (d *TypesenseClusterScaleDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	scale := &autoscalingv1.Scale{}
+	if err := d.decoder.Decode(req, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	replicas := getQuorumReplicas(scale.Spec.Replicas)
+	if replicas == scale.Spec.Replicas {
+		return admission.Allowed("")
+	}
+	typesenseclusterlog.Info("Rounding scale for TypesenseCluster", "name", req.Name, "requested", scale.Spec.Replicas, "replicas", replicas)
+
+	scale.Spec.Replicas = replicas
+	marshaled, err := json.Marshal(scale)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// getQuorumReplicas rounds replicas up to the next odd size, within the sizes the schema admits
+func getQuorumReplicas(replicas int32) int32 {
+	if replicas < 1 {
+		return 1
+	}
+	if replicas%2 == 0 {
+		replicas++
+	}
+
+	return min(replicas, maxReplicas)
+}
+
+// +kubebuilder:webhook:path=/validate-ts-opentelekomcloud-com-v1alpha1-typesensecluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=ts.opentelekomcloud.com,resources=typesenseclusters,verbs=create;update,versions=v1alpha1,name=vtypesensecluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TypesenseClusterCustomValidator struct is responsible for validating the TypesenseCluster resource
+// when it is created, updated, or deleted.
+type TypesenseClusterCustomValidator struct{}
+
+var _ webhook.CustomValidator = &TypesenseClusterCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type TypesenseCluster.
+func (v *TypesenseClusterCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ts, ok := obj.(*tsv1alpha1.TypesenseCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a TypesenseCluster object but got %T", obj)
+	}
+	typesenseclusterlog.Info("Validation for TypesenseCluster upon creation", "name", ts.GetName())
+
+	return nil, toInvalidError(ts, validateTypesenseCluster(ts))
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type TypesenseCluster.
+func (v *TypesenseClusterCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	ts, ok := newObj.(*tsv1alpha1.TypesenseCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a TypesenseCluster object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*tsv1alpha1.TypesenseCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a TypesenseCluster object for the oldObj but got %T", oldObj)
+	}
+	typesenseclusterlog.Info("Validation for TypesenseCluster upon update", "name", ts.GetName())
+
+	// finalizers, metadata and status are updated while the cluster is deleted or the spec is left untouched, none
+	// of these updates may be blocked by a spec stored before the webhook was deployed
+	if ts.DeletionTimestamp != nil || equality.Semantic.DeepEqual(old.Spec, ts.Spec) {
+		return nil, nil
+	}
+
+	allErrs := getIntroducedErrors(validateTypesenseCluster(old), validateTypesenseCluster(ts))
+	allErrs = append(allErrs, validateTypesenseClusterUpdate(old, ts)...)
+
+	return nil, toInvalidError(ts, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type TypesenseCluster.
+func (v *TypesenseClusterCustomValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateTypesenseCluster(ts *tsv1alpha1.TypesenseCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// the longest raft node name belongs to the highest ordinal
+	nodeName := fmt.Sprintf(raftNodeName, ts.Name, ts.Spec.Replicas-1, ts.Name)
+	if len(nodeName) > raftNodeNameLimit {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("metadata", "name"),
+			ts.Name,
+			fmt.Sprintf("raft node name %s exceeds %d characters, use a shorter name", nodeName, raftNodeNameLimit),
+		))
+	}
+
+	if ts.Spec.Ingress != nil && len(ts.Spec.HttpRoutes) > 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("httpRoutes"), "ingress and httpRoutes are mutually exclusive"))
+	}
+
+	if ts.Spec.ApiPort != 0 && ts.Spec.ApiPort == ts.Spec.PeeringPort {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("peeringPort"), ts.Spec.PeeringPort, "peeringPort must differ from apiPort"))
+	}
+
+	for _, p := range []struct {
+		path *field.Path
+		port int
+	}{
+		{specPath.Child("apiPort"), ts.Spec.ApiPort},
+		{specPath.Child("peeringPort"), ts.Spec.PeeringPort},
+	} {
+		if p.port == metricsExporterPort || p.port == healthcheckPort {
+			allErrs = append(allErrs, field.Invalid(p.path, p.port, "port is reserved for the metrics exporter and healthcheck sidecars"))
+		}
+	}
+
+	routes := make(map[string]bool, len(ts.Spec.HttpRoutes))
+	for i, route := range ts.Spec.HttpRoutes {
+		if routes[route.Name] {
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("httpRoutes").Index(i).Child("name"), route.Name))
+		}
+		routes[route.Name] = true
+	}
+
+	return allErrs
+}
+
+func validateTypesenseClusterUpdate(old, ts *tsv1alpha1.TypesenseCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if old.Spec.PeeringPort != 0 && ts.Spec.PeeringPort != old.Spec.PeeringPort {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("peeringPort"), "peeringPort cannot be changed, raft peers would lose each other"))
+	}
+
+	if old.Spec.Storage != nil && ts.Spec.Storage != nil {
+		storagePath := specPath.Child("storage")
+
+		if ts.Spec.Storage.Size.Cmp(old.Spec.Storage.Size) < 0 {
+			allErrs = append(allErrs, field.Forbidden(
+				storagePath.Child("size"),
+				fmt.Sprintf("storage cannot shrink from %s to %s", old.Spec.Storage.Size.String(), ts.Spec.Storage.Size.String()),
+			))
+		}
+
+		if ts.Spec.Storage.StorageClassName != old.Spec.Storage.StorageClassName {
+			allErrs = append(allErrs, field.Forbidden(storagePath.Child("storageClassName"), "storageClassName cannot be changed"))
+		}
+
+		if old.Spec.Storage.AccessMode != "" && ts.Spec.Storage.AccessMode != old.Spec.Storage.AccessMode {
+			allErrs = append(allErrs, field.Forbidden(storagePath.Child("accessMode"), "accessMode cannot be changed"))
+		}
+	}
+
+	return allErrs
+}
+
+// getIntroducedErrors returns the errors of the updated spec on fields that were valid before the update, so that a
+// cluster stored with an invalid spec can still be updated as long as the update does not make it worse
+func getIntroducedErrors(old, updated field.ErrorList) field.ErrorList {
+	invalid := make(map[string]bool, len(old))
+	for _, err := range old {
+		invalid[err.Field] = true
+	}
+
+	var allErrs field.ErrorList
+	for _, err := range updated {
+		if !invalid[err.Field] {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	return allErrs
+}
+
+func toInvalidError(ts *tsv1alpha1.TypesenseCluster, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: tsv1alpha1.GroupVersion.Group, Kind: "TypesenseCluster"},
+		ts.Name,
+		allErrs,
+	)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	tsv1alpha1 "github.com/akyriako/typesense-operator/api/v1alpha1"
+)
+
+var _ = Describe("TypesenseCluster Webhook", func() {
+	var (
+		obj       *tsv1alpha1.TypesenseCluster
+		oldObj    *tsv1alpha1.TypesenseCluster
+		validator TypesenseClusterCustomValidator
+		defaulter TypesenseClusterCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &tsv1alpha1.TypesenseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+			Spec: tsv1alpha1.TypesenseClusterSpec{
+				Image:       "typesense/typesense:29.0",
+				Replicas:    3,
+				ApiPort:     8108,
+				PeeringPort: 8107,
+				Storage: &tsv1alpha1.StorageSpec{
+					Size:             resource.MustParse("1Gi"),
+					StorageClassName: "standard",
+				},
+			},
+		}
+		oldObj = obj.DeepCopy()
+		validator = TypesenseClusterCustomValidator{}
+		defaulter = TypesenseClusterCustomDefaulter{}
+	})
+
+	Context("When creating TypesenseCluster under Defaulting Webhook", func() {
+		It("Should apply the defaults of the spec helpers", func() {
+			obj.Spec.Replicas = 0
+			obj.Spec.Storage.AccessMode = ""
+
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			By("checking that the default values are set")
+			Expect(obj.Spec.Replicas).To(Equal(int32(3)))
+			Expect(obj.Spec.Storage.AccessMode).To(Equal("ReadWriteOnce"))
+			Expect(*obj.Spec.TerminationGracePeriodSeconds).To(Equal(obj.Spec.GetTerminationGracePeriodSeconds()))
+			Expect(*obj.Spec.Resources).To(Equal(obj.Spec.GetResources()))
+			Expect(obj.Spec.Metrics).To(BeNil())
+		})
+
+		It("Should default a missing storage", func() {
+			obj.Spec.Storage = nil
+
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Storage).NotTo(BeNil())
+			Expect(*obj.Spec.Storage).To(Equal(obj.Spec.GetStorage()))
+		})
+
+		It("Should round even replicas up to an odd size", func() {
+			obj.Spec.Replicas = 4
+
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Replicas).To(Equal(int32(5)))
+		})
+	})
+
+	Context("When scaling TypesenseCluster under Scale Defaulting Webhook", func() {
+		scale := func(replicas int32) admission.Response {
+			raw, err := json.Marshal(&autoscalingv1.Scale{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
+				ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Namespace: obj.Namespace},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			scaleDefaulter := &TypesenseClusterScaleDefaulter{decoder: admission.NewDecoder(scheme.Scheme)}
+			return scaleDefaulter.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Name:        obj.Name,
+				Namespace:   obj.Namespace,
+				Operation:   admissionv1.Update,
+				SubResource: "scale",
+				Object:      runtime.RawExtension{Raw: raw},
+			}})
+		}
+
+		It("Should round an even scale up to the next odd size", func() {
+			response := scale(2)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(HaveLen(1))
+			Expect(response.Patches[0].Path).To(Equal("/spec/replicas"))
+			Expect(response.Patches[0].Value).To(BeEquivalentTo(3))
+		})
+
+		It("Should cap the scale to the largest size of the quorum", func() {
+			response := scale(8)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(HaveLen(1))
+			Expect(response.Patches[0].Value).To(BeEquivalentTo(7))
+		})
+
+		It("Should admit an odd scale untouched", func() {
+			response := scale(5)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+		})
+	})
+
+	Context("When creating or updating TypesenseCluster under Validating Webhook", func() {
+		It("Should deny creation if the raft node names are too long", func() {
+			obj.Name = strings.Repeat("a", 30)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should admit creation without a storage class", func() {
+			obj.Spec.Storage.StorageClassName = ""
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if both ingress and httpRoutes are set", func() {
+			obj.Spec.Ingress = &tsv1alpha1.IngressSpec{Host: "search.example.com"}
+			obj.Spec.HttpRoutes = []tsv1alpha1.HttpRouteSpec{{Name: "public"}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should admit creation if all required fields are present", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny changing the peering port", func() {
+			obj.Spec.PeeringPort = 9107
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should deny shrinking the storage", func() {
+			obj.Spec.Storage.Size = resource.MustParse("512Mi")
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should admit updates that leave the spec untouched", func() {
+			oldObj.Spec.Ingress = &tsv1alpha1.IngressSpec{Host: "search.example.com"}
+			oldObj.Spec.HttpRoutes = []tsv1alpha1.HttpRouteSpec{{Name: "public"}}
+			obj = oldObj.DeepCopy()
+			obj.Finalizers = nil
+			obj.Labels = map[string]string{"team": "search"}
+
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).To(BeNil())
+		})
+
+		It("Should admit updates of a cluster being deleted", func() {
+			obj.DeletionTimestamp = ptr.To(metav1.Now())
+			obj.Spec.PeeringPort = 9107
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).To(BeNil())
+		})
+
+		It("Should admit updates that do not touch an invalid field stored before", func() {
+			oldObj.Spec.Ingress = &tsv1alpha1.IngressSpec{Host: "search.example.com"}
+			oldObj.Spec.HttpRoutes = []tsv1alpha1.HttpRouteSpec{{Name: "public"}}
+			obj = oldObj.DeepCopy()
+			obj.Spec.Replicas = 5
+
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).To(BeNil())
+		})
+
+		It("Should deny updates that make a valid field invalid", func() {
+			obj.Spec.Ingress = &tsv1alpha1.IngressSpec{Host: "search.example.com"}
+			obj.Spec.HttpRoutes = []tsv1alpha1.HttpRouteSpec{{Name: "public"}}
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should admit expanding the storage", func() {
+			obj.Spec.Storage.Size = resource.MustParse("2Gi")
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).To(BeNil())
+		})
+	})
+})